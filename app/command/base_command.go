@@ -0,0 +1,125 @@
+// Package command 服务程序的子命令，所有子命令使用相同的配置文件
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"message/config"
+	"message/database"
+	"message/logs"
+	"os"
+)
+
+// Command 服务程序的一个子命令
+type Command struct {
+	// Name 命令名称
+	Name string
+	// Usage 参数说明
+	Usage string
+	// Summary 命令的简介
+	Summary string
+	// Run 执行命令，args 为命令名称之后的参数
+	Run func(command *Command, args []string) error
+}
+
+// commands 所有的子命令，按照帮助信息中的顺序排列
+var commands = []*Command{
+	{Name: "serve", Usage: "", Summary: "启动 HTTP 和 gRPC 服务", Run: runServe},
+	{Name: "migrate", Usage: "<up|down|status> [flags]", Summary: "迁移数据库", Run: runMigrate},
+	{Name: "token", Usage: "<create|revoke> [token]", Summary: "创建或撤销消息凭证", Run: runToken},
+	{Name: "purge", Usage: "[flags]", Summary: "清理回收站中的消息和过期的幂等键", Run: runPurge},
+	{Name: "config", Usage: "validate", Summary: "检查配置文件", Run: runConfig},
+	{Name: "export", Usage: "[flags]", Summary: "导出消息", Run: runExport},
+}
+
+// errUsage 参数错误，已经打印了帮助信息
+var errUsage = errors.New("usage")
+
+// Run 执行 args 中指定的子命令并返回退出码，没有指定子命令时启动服务
+func Run(args []string) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "-h" || name == "--help" || name == "help" {
+		usage()
+		return 0
+	}
+
+	for _, command := range commands {
+		if command.Name != name {
+			continue
+		}
+		err := command.Run(command, args)
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			return 2
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "未知的命令 %s\n\n", name)
+	usage()
+	return 2
+}
+
+// usage 打印所有子命令的帮助信息
+func usage() {
+	fmt.Fprintf(os.Stderr, "用法: %s <command> [flags]\n\n命令:\n", os.Args[0])
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-32s %s\n", command.Name+" "+command.Usage, command.Summary)
+	}
+	fmt.Fprintf(os.Stderr, "\n没有指定命令时执行 serve，使用 %s <command> -h 查看命令的参数\n", os.Args[0])
+}
+
+// newFlagSet 创建子命令的参数解析器
+func newFlagSet(command *Command) *flag.FlagSet {
+	flags := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: %s %s %s\n\n%s\n", os.Args[0], command.Name, command.Usage, command.Summary)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse 解析参数，参数错误时返回 errUsage
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// subcommand 返回子命令的第一个参数，例如 migrate up 中的 up，没有或者不在 names 中时打印帮助信息
+func subcommand(flags *flag.FlagSet, args []string, names ...string) (string, []string, error) {
+	if len(args) > 0 {
+		for _, name := range names {
+			if args[0] == name {
+				return name, args[1:], nil
+			}
+		}
+	}
+	flags.Usage()
+	return "", nil, errUsage
+}
+
+// initCommand 读取配置、初始化日志并连接数据库，日志输出到 stderr，避免和命令的结果混在一起
+func initCommand() error {
+	config.InitConfig()
+	logs.Console = "stderr"
+	logs.InitLog()
+
+	database.InitMySQL()
+	if !database.Connected() {
+		return errors.New("数据库连接失败")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"message/config"
+)
+
+// runConfig 检查配置文件是否可以读取以及配置的值是否有效
+func runConfig(command *Command, args []string) error {
+	flags := newFlagSet(command)
+	_, args, err := subcommand(flags, args, "validate")
+	if err != nil {
+		return err
+	}
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if err := config.LoadConfig(); err != nil {
+		return err
+	}
+	if err := config.AppConfig.Validate(); err != nil {
+		// 每行输出一个无效的配置项
+		fmt.Println(err)
+		return errors.New("配置无效")
+	}
+	fmt.Println("配置有效")
+	return nil
+}
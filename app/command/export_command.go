@@ -0,0 +1,85 @@
+package command
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/logs"
+	"os"
+)
+
+// runExport 导出消息，和管理接口 /admin/message/export 的格式相同，可以通过导入接口导入到其他环境
+func runExport(command *Command, args []string) error {
+	flags := newFlagSet(command)
+	exportRequest := &request.MessageExportRequest{}
+	flags.StringVar(&exportRequest.Format, "format", request.FormatJSONL, "导出格式（jsonl/csv）")
+	flags.StringVar(&exportRequest.Filter, "filter", "", "过滤语句，语法和接口的 filter 参数相同")
+	token := flags.String("token", "", "只导出该消息凭证发送和收到的消息，为空时导出所有消息")
+	out := flags.String("out", "", "导出的文件，为空时输出到标准输出")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := request.Validate.Struct(exportRequest); err != nil {
+		flags.Usage()
+		return errUsage
+	}
+	var messageFilters []request.MessageFilterRequest
+	if exportRequest.Filter != "" {
+		filters, err := request.ParseMessageFilters(exportRequest.Filter)
+		if err != nil {
+			return err
+		}
+		messageFilters = filters
+	}
+
+	if err := initCommand(); err != nil {
+		return err
+	}
+	defer logs.Sync()
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	buffered := bufio.NewWriter(w)
+
+	// 逐条写入消息
+	var write func(message *response.ExportedMessage) error
+	flush := buffered.Flush
+	if exportRequest.Format == request.FormatCSV {
+		writer := csv.NewWriter(buffered)
+		if err := writer.Write(response.MessageCSVColumns); err != nil {
+			return err
+		}
+		write = func(message *response.ExportedMessage) error {
+			return writer.Write(message.CSVRecord())
+		}
+		flush = func() error {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		}
+	} else {
+		encoder := json.NewEncoder(buffered)
+		write = func(message *response.ExportedMessage) error {
+			return encoder.Encode(message)
+		}
+	}
+
+	logs.LogInfo.Infof("Export %v %s", exportRequest, *token)
+	if err := repository.ExportMessages(*token, messageFilters, write); err != nil {
+		return err
+	}
+	return flush()
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"message/database"
+	"message/logs"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate 迁移数据库，可以在部署时单独执行
+func runMigrate(command *Command, args []string) error {
+	flags := newFlagSet(command)
+	to := flags.Uint("to", 0, "up 时只迁移到该版本，为 0 时迁移到最新版本")
+	steps := flags.Int("steps", 1, "down 时回滚的迁移数量")
+	action, args, err := subcommand(flags, args, "up", "down", "status")
+	if err != nil {
+		return err
+	}
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if action == "down" && *steps <= 0 {
+		flags.Usage()
+		return errUsage
+	}
+	if err := initCommand(); err != nil {
+		return err
+	}
+	defer logs.Sync()
+
+	switch action {
+	case "up":
+		version := database.LatestVersion()
+		if *to > 0 {
+			version = *to
+		}
+		if err := database.MigrateTo(version); err != nil {
+			return err
+		}
+		logs.LogInfo.Infof("Migrate-成功 %d", version)
+	case "down":
+		if err := database.Rollback(*steps); err != nil {
+			return err
+		}
+		logs.LogInfo.Infof("Migrate-回滚成功 %d", *steps)
+	case "status":
+		statuses, err := database.Migrations()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.AppliedAt != nil {
+				state, appliedAt = "applied", status.AppliedAt.Local().Format(time.DateTime)
+			}
+			if status.Unknown {
+				state = "unknown"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return w.Flush()
+	}
+	return nil
+}
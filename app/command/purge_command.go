@@ -0,0 +1,70 @@
+package command
+
+import (
+	"fmt"
+	"message/app/repository"
+	"message/config"
+	"message/logs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runPurge 立即清理回收站中的消息和过期的幂等键，和后台任务执行相同的操作
+func runPurge(command *Command, args []string) error {
+	flags := newFlagSet(command)
+	olderThan := flags.String("older-than", "", "清理软删除超过该时间的消息，例如 30d、72h，默认为 app.trash.purgeDays 天")
+	idempotency := flags.Bool("idempotency", true, "同时清理过期的幂等键")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	var age time.Duration
+	if *olderThan != "" {
+		var err error
+		if age, err = parseAge(*olderThan); err != nil {
+			flags.Usage()
+			return errUsage
+		}
+	}
+
+	if err := initCommand(); err != nil {
+		return err
+	}
+	defer logs.Sync()
+
+	if *olderThan == "" {
+		age = time.Duration(config.AppConfig.App.Trash.PurgeDays) * 24 * time.Hour
+	}
+	if age <= 0 {
+		return fmt.Errorf("没有设置 --older-than，并且 app.trash.purgeDays 为 0")
+	}
+
+	purged, err := repository.PurgeTrashMessages(time.Now().Add(-age))
+	if err != nil {
+		return err
+	}
+	logs.LogInfo.Infof("Purge-清理已删除的消息 %d", purged)
+	fmt.Printf("messages: %d\n", purged)
+
+	if *idempotency {
+		purged, err := repository.PurgeExpiredIdempotencies()
+		if err != nil {
+			return err
+		}
+		logs.LogInfo.Infof("Purge-清理过期的幂等键 %d", purged)
+		fmt.Printf("idempotency keys: %d\n", purged)
+	}
+	return nil
+}
+
+// parseAge 解析时间长度，除了 time.ParseDuration 支持的格式，还支持以 d 结尾的天数
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("无效的时间 %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,201 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"message/app/event"
+	"message/app/middleware"
+	"message/app/repository"
+	"message/app/rpc"
+	"message/app/worker"
+	"message/config"
+	"message/database"
+	"message/logs"
+	"message/router"
+	"message/utils"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runServe 启动 HTTP 和 gRPC 服务
+func runServe(command *Command, args []string) error {
+	flags := newFlagSet(command)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	// 初始化Gin引擎
+	r := gin.Default()
+
+	// 初始化配置
+	config.InitConfig()
+
+	// 初始日志
+	logs.InitLog()
+	defer logs.Sync()
+	r.Use(utils.AccessLogger())
+
+	// 国际化中间件
+	r.Use(middleware.I18nMiddleware())
+
+	// 初始化路由
+	router.InitRouter(r)
+
+	// 将事件发送到发送者注册的 Webhook
+	event.InitWebhook(repository.ResolveWebhook)
+
+	// 连接MySQL数据库
+	database.InitMySQL()
+
+	// 后台任务和后台重新连接数据库的 ctx，在停止服务时取消
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// 连接数据库后初始化数据库迁移并启动后台任务，迁移失败或者数据库的版本比程序新时不能启动
+	onConnect := func() error {
+		if err := database.InitMigration(); err != nil {
+			logs.LogError.Errorf("InitMigration %s", err)
+			return err
+		}
+		worker.InitTrashWorker()
+		worker.InitIdempotencyWorker()
+		worker.Start(workerCtx)
+		return nil
+	}
+
+	// 启动时连接失败时在后台继续重新连接，连接成功前 /readyz 和需要数据库的接口返回 503
+	reconnected := make(chan struct{})
+	if database.Connected() {
+		close(reconnected)
+		if err := onConnect(); err != nil {
+			return err
+		}
+	} else {
+		go func() {
+			defer close(reconnected)
+			database.Reconnect(workerCtx, onConnect)
+		}()
+	}
+	waitWorkers := func() {
+		<-reconnected
+		worker.Wait()
+	}
+
+	// 启动 gRPC 服务
+	grpcServer, err := rpc.Start()
+	if err != nil {
+		logs.LogError.Errorf("RPC-启动失败 %s", err)
+	}
+
+	// 启动 HTTP 服务，收到 SIGTERM/SIGINT 或者服务启动失败时停止
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := newHTTPServer(r)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- listenAndServe(server)
+	}()
+
+	var listenErr error
+	select {
+	case <-ctx.Done():
+		logs.LogInfo.Infof("Serve-收到停止信号，开始停止服务")
+	case listenErr = <-serveErr:
+		logs.LogError.Errorf("Serve-HTTP 服务启动失败 %s", listenErr)
+	}
+	stop()
+
+	return errors.Join(listenErr, shutdown(server, grpcServer, stopWorkers, waitWorkers))
+}
+
+// newHTTPServer 根据配置创建 HTTP 服务
+func newHTTPServer(handler http.Handler) *http.Server {
+	serverConfig := config.AppConfig.Server
+	return &http.Server{
+		Addr:           serverConfig.Address,
+		Handler:        handler,
+		ReadTimeout:    time.Duration(serverConfig.ReadTimeout) * time.Second,
+		WriteTimeout:   time.Duration(serverConfig.WriteTimeout) * time.Second,
+		IdleTimeout:    time.Duration(serverConfig.IdleTimeout) * time.Second,
+		MaxHeaderBytes: serverConfig.MaxHeaderBytes,
+	}
+}
+
+// listenAndServe 启动 HTTP 服务，设置了证书和私钥时使用 HTTPS，正常停止时返回 nil
+func listenAndServe(server *http.Server) error {
+	tls := config.AppConfig.Server.TLS
+	logs.LogInfo.Infof("Serve-启动 %s", server.Addr)
+
+	var err error
+	if tls.Cert != "" && tls.Key != "" {
+		err = server.ListenAndServeTLS(tls.Cert, tls.Key)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// shutdown 在 server.shutdownTimeout 内停止服务
+//
+// 依次停止接收新请求并等待正在处理的 HTTP 和 gRPC 请求、停止后台任务和后台重新连接数据库、等待事件处理结束，最后关闭数据库连接池。
+// 超时后强制关闭剩余的连接，不再等待。
+func shutdown(server *http.Server, grpcServer *grpc.Server, stopWorkers context.CancelFunc, waitWorkers func()) error {
+	timeout := time.Duration(config.AppConfig.Server.ShutdownTimeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	if err := server.Shutdown(ctx); err != nil {
+		logs.LogError.Errorf("Serve-HTTP 服务停止超时 %s", err)
+		errs = append(errs, err, server.Close())
+	}
+
+	if grpcServer != nil {
+		if err := waitContext(ctx, grpcServer.GracefulStop); err != nil {
+			logs.LogError.Errorf("Serve-gRPC 服务停止超时 %s", err)
+			grpcServer.Stop()
+		}
+	}
+
+	stopWorkers()
+	if err := waitContext(ctx, waitWorkers); err != nil {
+		logs.LogError.Errorf("Serve-等待后台任务停止超时 %s", err)
+		errs = append(errs, err)
+	}
+	if err := waitContext(ctx, event.Wait); err != nil {
+		logs.LogError.Errorf("Serve-等待事件处理结束超时 %s", err)
+		errs = append(errs, err)
+	}
+
+	if err := database.Close(); err != nil {
+		logs.LogError.Errorf("Serve-关闭数据库连接失败 %s", err)
+		errs = append(errs, err)
+	}
+
+	logs.LogInfo.Infof("Serve-服务已停止")
+	return errors.Join(errs...)
+}
+
+// waitContext 执行 wait 直到返回，ctx 先结束时不再等待并返回 ctx 的错误
+func waitContext(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
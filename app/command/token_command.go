@@ -0,0 +1,71 @@
+package command
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"message/app/repository"
+	"message/app/request"
+	"message/logs"
+)
+
+// runToken 在验证表中创建或撤销消息凭证
+func runToken(command *Command, args []string) error {
+	flags := newFlagSet(command)
+	action, args, err := subcommand(flags, args, "create", "revoke")
+	if err != nil {
+		return err
+	}
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	token := flags.Arg(0)
+	switch {
+	case action == "create" && token == "":
+		token, err = newMessageToken()
+		if err != nil {
+			return err
+		}
+	case token == "":
+		flags.Usage()
+		return errUsage
+	}
+	// 凭证的格式和 AuthMiddleware 校验的相同
+	if err := request.Validate.Var(token, "required,len=32"); err != nil {
+		return fmt.Errorf("凭证必须为 32 个字符")
+	}
+
+	if err := initCommand(); err != nil {
+		return err
+	}
+	defer logs.Sync()
+
+	if action == "create" {
+		if err := repository.CreateMessageToken(token); err != nil {
+			return err
+		}
+		logs.LogInfo.Infof("Token-创建成功 %s", token)
+		fmt.Println(token)
+		return nil
+	}
+
+	revoked, err := repository.RevokeMessageToken(token)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return fmt.Errorf("凭证 %s 不存在", token)
+	}
+	logs.LogInfo.Infof("Token-撤销成功 %s", token)
+	return nil
+}
+
+// newMessageToken 生成随机的 32 个字符的消息凭证
+func newMessageToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
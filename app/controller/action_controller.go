@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"message/app/event"
+	"message/app/model"
+	"message/app/repository"
+	"message/app/response"
+	"message/logs"
+	"net/http"
+)
+
+// MessageAction 点击消息的操作按钮
+//
+//	@Summary		点击消息的操作按钮
+//	@Description	记录接收者点击的操作按钮，并通过事件和 Webhook 通知发送者。每个接收者只能选择一个操作按钮，重复点击同一个按钮时返回之前的记录
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string							true	"消息id"
+//	@Param			actionId	path		string							true	"操作按钮id"
+//	@Success		200			{object}	response.MessageActionResponse	"点击记录"
+//	@Failure		400			{object}	request.ValidationError			"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError				"凭证错误"
+//	@Failure		404			{object}	response.HTTPError				"找不到数据"
+//	@Failure		409			{object}	response.HTTPError				"已经选择了其他操作按钮"
+//	@Failure		502			{object}	response.HTTPError				"系统异常"
+//	@Router			/message/{id}/actions/{actionId} [post]
+func MessageAction(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+
+	// 检查 token 是否存在
+	if !tokenExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	actionId := ctx.Param("actionId")
+
+	logs.LogInfo.Infof("MessageAction %s %s %s", ctx.Param("id"), actionId, messageToken)
+
+	// 根据id查询接收者可以看到的消息
+	message := repository.QueryVisibleMessageById(messageToken, ctx.Param("id"))
+	if message == nil {
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	}
+
+	// 记录点击的操作按钮
+	chosen, created, err := repository.ChooseMessageAction(messageToken, message, actionId, auditor(ctx))
+	switch {
+	case errors.Is(err, repository.ErrMessageActionNotFound):
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	case errors.Is(err, repository.ErrMessageActionChosen):
+		response.NewError(
+			ctx,
+			http.StatusConflict,
+			response.ErrMessageActionChosen,
+		)
+		return
+	case err != nil:
+		logs.LogError.Errorf("MessageAction %s %s %s", message.MessageId, messageToken, err)
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 第一次点击时通知发送者
+	if created {
+		event.Publish(event.Event{
+			Name:      event.MessageAction,
+			MessageId: message.MessageId,
+			SenderIds: message.SenderIds,
+			Actor:     messageToken,
+			Payload: map[string]interface{}{
+				"action": message.Actions.Find(actionId),
+				"data":   message.Data,
+			},
+			CreatedAt: chosen.ChosenAt,
+			Urgent:    message.Priority == model.PriorityUrgent,
+		})
+	}
+
+	// 返回点击记录
+	response.JSON(ctx, http.StatusOK, chosen)
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/logs"
+	"net/http"
+)
+
+// AdminMessageIndex 管理员查询消息
+//
+//	@Summary		管理员查询消息
+//	@Description	查询所有消息，不限制发送者和接收者
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			filter		query		string					false	"过滤语句（title = 标题,status = 0|1|2,...）"
+//	@Param			sortColumn	query		string					false	"排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status|priority）"
+//	@Param			sortType	query		string					false	"排序类型（asc/desc）"
+//	@Param			page		query		int						false	"查询第几页数据"
+//	@Param			format		query		string					false	"复杂消息的返回格式（html/text），为空时原样返回"
+//	@Param			deleted		query		bool					false	"是否查询已软删除的消息"
+//	@Success		200			{array}		[]response.AdminMessage	"消息信息"
+//	@Failure		400			{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError		"凭证错误"
+//	@Failure		502			{object}	response.HTTPError		"系统异常"
+//	@Router			/admin/message [get]
+func AdminMessageIndex(ctx *gin.Context) {
+	// 从上下文中获取 adminMessage
+	message, messageExists := ctx.Get("adminMessage")
+	// 从上下文中获取 messageFilters
+	messageFilter, messageFilterExists := ctx.Get("messageFilters")
+
+	// 检查 adminMessage 是否存在
+	if !messageExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 adminMessage 转换为 AdminMessageRequest 类型
+	adminMessageRequest := message.(*request.AdminMessageRequest)
+
+	var messageFilters []request.MessageFilterRequest
+	if messageFilterExists {
+		// 将 messageFilter 转换为 []MessageFilterRequest 类型
+		messageFilters = *messageFilter.(*[]request.MessageFilterRequest)
+	}
+
+	logs.LogInfo.Infof("AdminMessageIndex %v", adminMessageRequest)
+
+	// 返回查询结果
+	messages := repository.QueryMessagesByAdminMessageRequest(
+		adminMessageRequest,
+		messageFilters,
+	)
+	response.Page(ctx, adminMessageRequest.Page, messages)
+}
+
+// AdminMessageDelete 管理员撤回消息
+//
+//	@Summary		管理员撤回消息
+//	@Description	根据数组的数据撤回消息，不限制发送者
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			atomic	query		bool								false	"任意一条消息撤回失败则全部回滚"
+//	@Param			_		body		[]request.MessageDeleteRequest		true	"撤回的消息"
+//	@Success		200		{object}	[]response.MessageDeleteResponse	"每条消息的撤回结果"
+//	@Failure		400		{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError					"凭证错误"
+//	@Failure		502		{object}	response.HTTPError					"系统异常"
+//	@Router			/admin/message [delete]
+func AdminMessageDelete(ctx *gin.Context) {
+	// 从上下文中获取 messageDelete
+	messageDelete, messageDeleteExists := ctx.Get("messageDelete")
+	// 从上下文中获取 messageDeleteQuery
+	messageDeleteQuery, messageDeleteQueryExists := ctx.Get("messageDeleteQuery")
+
+	// 检查 messageDelete 和 messageDeleteQuery 是否存在
+	if !messageDeleteExists || !messageDeleteQueryExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 messageDelete 转换为 []MessageDeleteRequest 类型
+	messageDeleteRequests := messageDelete.(*[]request.MessageDeleteRequest)
+	// 将 messageDeleteQuery 转换为 MessageDeleteQueryRequest 类型
+	messageDeleteQueryRequest := messageDeleteQuery.(*request.MessageDeleteQueryRequest)
+
+	logs.LogInfo.Infof("AdminMessageDelete %v %v", messageDeleteRequests, messageDeleteQueryRequest)
+
+	// 撤回消息
+	results := repository.AdminDeleteMessagesById(
+		messageDeleteRequests,
+		messageDeleteQueryRequest.Atomic,
+		auditor(ctx),
+	)
+
+	// 返回撤回结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// AdminMessageRestore 管理员恢复消息
+//
+//	@Summary		管理员恢复消息
+//	@Description	根据数组的数据恢复已软删除的消息，不限制发送者
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageRestoreRequest		true	"恢复的消息"
+//	@Success		200	{object}	[]response.MessageRestoreResponse	"恢复后返回的数据"
+//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError					"凭证错误"
+//	@Failure		502	{object}	response.HTTPError					"系统异常"
+//	@Router			/admin/message/restore [post]
+func AdminMessageRestore(ctx *gin.Context) {
+	// 从上下文中获取 messageRestore
+	messageRestore, messageRestoreExists := ctx.Get("messageRestore")
+
+	// 检查 messageRestore 是否存在
+	if !messageRestoreExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 messageRestore 转换为 []MessageRestoreRequest 类型
+	messageRestoreRequests := messageRestore.(*[]request.MessageRestoreRequest)
+
+	logs.LogInfo.Infof("AdminMessageRestore %v", messageRestoreRequests)
+
+	// 恢复消息
+	results := repository.AdminRestoreMessagesById(messageRestoreRequests, auditor(ctx))
+
+	// 返回恢复结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// AdminMessageRecipientStatus 查询消息每个接收者的状态
+//
+//	@Summary		查询接收者状态
+//	@Description	根据消息id查询消息每个接收者的状态
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string										true	"消息id"
+//	@Success		200	{array}		[]response.MessageRecipientStatusResponse	"接收者状态"
+//	@Failure		400	{object}	request.ValidationError						"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError							"凭证错误"
+//	@Failure		404	{object}	response.HTTPError							"找不到数据"
+//	@Router			/admin/message/{id}/status [get]
+func AdminMessageRecipientStatus(ctx *gin.Context) {
+	// 根据id查询消息每个接收者的状态
+	recipients := repository.QueryMessageRecipientStatus(ctx.Param("id"))
+
+	if recipients == nil {
+		// 如果找不到对应的消息，返回状态码 NotFound
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	}
+
+	logs.LogInfo.Infof("AdminMessageRecipientStatus %s", ctx.Param("id"))
+
+	// 返回每个接收者的状态
+	response.JSON(ctx, http.StatusOK, recipients)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/logs"
+	"net/http"
+)
+
+// AdminAuditIndex 查询审计日志
+//
+//	@Summary		查询审计日志
+//	@Description	根据条件分页查询审计日志
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			actor		query		string					false	"操作者的凭证"
+//	@Param			action		query		string					false	"操作类型（create|update|status|delete|restore|hide|retract|flag|tag|action）"
+//	@Param			messageId	query		string					false	"消息id"
+//	@Param			from		query		string					false	"开始时间（RFC3339）"
+//	@Param			to			query		string					false	"结束时间（RFC3339）"
+//	@Param			page		query		int						false	"查询第几页数据"
+//	@Success		200			{array}		[]response.Audit		"审计日志"
+//	@Failure		400			{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError		"凭证错误"
+//	@Failure		502			{object}	response.HTTPError		"系统异常"
+//	@Router			/admin/audit [get]
+func AdminAuditIndex(ctx *gin.Context) {
+	// 从上下文中获取 audit
+	audit, auditExists := ctx.Get("audit")
+
+	// 检查 audit 是否存在
+	if !auditExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 audit 转换为 AuditRequest 类型
+	auditRequest := audit.(*request.AuditRequest)
+
+	logs.LogInfo.Infof("AdminAuditIndex %v", auditRequest)
+
+	// 返回查询结果
+	audits := repository.QueryAudits(auditRequest)
+	response.Page(ctx, auditRequest.Page, audits)
+}
+
+// AdminAuditExport 导出审计日志
+//
+//	@Summary		导出审计日志
+//	@Description	根据条件导出审计日志，每行一条 JSON 数据
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		application/x-ndjson
+//	@Security		ApiKeyAuth
+//	@Param			actor		query		string					false	"操作者的凭证"
+//	@Param			action		query		string					false	"操作类型（create|update|status|delete|restore|hide|retract|flag|tag|action）"
+//	@Param			messageId	query		string					false	"消息id"
+//	@Param			from		query		string					false	"开始时间（RFC3339）"
+//	@Param			to			query		string					false	"结束时间（RFC3339）"
+//	@Success		200			{object}	response.Audit			"每行一条审计日志"
+//	@Failure		400			{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError		"凭证错误"
+//	@Failure		502			{object}	response.HTTPError		"系统异常"
+//	@Router			/admin/audit/export [get]
+func AdminAuditExport(ctx *gin.Context) {
+	// 从上下文中获取 audit
+	audit, auditExists := ctx.Get("audit")
+
+	// 检查 audit 是否存在
+	if !auditExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 audit 转换为 AuditRequest 类型
+	auditRequest := audit.(*request.AuditRequest)
+
+	logs.LogInfo.Infof("AdminAuditExport %v", auditRequest)
+
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	ctx.Status(http.StatusOK)
+
+	// 逐条写入审计日志，避免一次性读取所有数据
+	encoder := json.NewEncoder(ctx.Writer)
+	err := repository.ExportAudits(auditRequest, func(audit *response.Audit) error {
+		if err := encoder.Encode(audit); err != nil {
+			return err
+		}
+		ctx.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		logs.LogError.Errorf("AdminAuditExport %s", err)
+	}
+}
+
+// auditor 返回当前请求的审计日志操作者
+func auditor(ctx *gin.Context) *repository.Auditor {
+	return &repository.Auditor{
+		Actor: ctx.GetString("token"),
+		Ip:    ctx.ClientIP(),
+	}
+}
@@ -0,0 +1,61 @@
+package controller_test
+
+import (
+	"fmt"
+	"message/app/model"
+	"message/config"
+	"message/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminAuditRecordsOperator(t *testing.T) {
+	db := testutil.Setup(t)
+	config.AppConfig.App.Admin.Token = "root-token"
+	config.AppConfig.App.Admin.Operators = []config.AdminOperator{{Name: "alice", Token: "alice-token"}}
+	r := testutil.NewRouter()
+
+	send := func(method string, path string, token string, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 导入的每条消息在同一个事务中记录审计日志，操作者是管理员的名称
+	messageId := strings.Repeat("a", 32)
+	record := fmt.Sprintf(
+		`{"message_id": %q, "sender_ids": ["sender"], "introducer_ids": ["recipient"], "title": "标题", "content": "内容", "category": "notice", "big_content": "详细内容", "created_at": "2024-02-15T05:49:57Z"}`,
+		messageId,
+	)
+	if code := send(http.MethodPost, "/admin/message/import", "alice-token", record+"\n"); code != http.StatusOK {
+		t.Fatalf("POST /admin/message/import = %d, want 200", code)
+	}
+	var audit model.Audit
+	if err := db.Where("action = ?", model.AuditImport).First(&audit).Error; err != nil {
+		t.Fatalf("import audit: %s", err)
+	}
+	if audit.Actor != "admin:alice" || audit.MessageId != messageId {
+		t.Fatalf("import audit = %s %s, want admin:alice %s", audit.Actor, audit.MessageId, messageId)
+	}
+
+	// 使用 token 时操作者为 admin
+	body := fmt.Sprintf(`[{"messageId": %q}]`, messageId)
+	if code := send(http.MethodDelete, "/admin/message", "root-token", body); code != http.StatusOK {
+		t.Fatalf("DELETE /admin/message = %d, want 200", code)
+	}
+	var deleteAudit model.Audit
+	if err := db.Where("action = ?", model.AuditDelete).First(&deleteAudit).Error; err != nil {
+		t.Fatalf("delete audit: %s", err)
+	}
+	if deleteAudit.Actor != "admin" {
+		t.Fatalf("delete audit actor = %s, want admin", deleteAudit.Actor)
+	}
+
+	if code := send(http.MethodGet, "/admin/audit", "unknown-token", ""); code != http.StatusUnauthorized {
+		t.Fatalf("GET /admin/audit with an unknown token = %d, want 401", code)
+	}
+}
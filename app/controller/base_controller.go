@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/response"
+	"message/config"
+	"net/http"
+)
+
+// writeMessage 设置消息的 ETag 响应头并返回消息
+func writeMessage(ctx *gin.Context, status int, message *response.Message) {
+	ctx.Header("ETag", response.MessageETag(message))
+	response.JSON(ctx, status, message)
+}
+
+// writePreconditionFailed 消息已经被修改时返回 412 和当前的消息
+//
+// 旧接口直接返回当前的消息，/v1 接口在错误的详细信息中返回当前的消息。
+func writePreconditionFailed(ctx *gin.Context, message *response.Message) {
+	ctx.Header("ETag", response.MessageETag(message))
+	if !response.Enveloped(ctx) {
+		ctx.JSON(http.StatusPreconditionFailed, message)
+		return
+	}
+	response.NewErrorDetails(ctx, http.StatusPreconditionFailed, response.ErrPreconditionFailed, message)
+}
+
+// checkIfMatch 检查 If-Match 请求头，条件不满足时返回错误响应和 false
+//
+// 消息已经被修改时返回 412 和当前的消息，配置要求携带 If-Match 但请求没有携带时返回 428。
+// 查询时使用任意格式返回的 ETag 都可以用于更新。
+func checkIfMatch(ctx *gin.Context, message *response.Message) bool {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		if config.AppConfig.API.RequireIfMatch {
+			response.NewError(
+				ctx,
+				http.StatusPreconditionRequired,
+				response.ErrPreconditionRequired,
+			)
+			return false
+		}
+		return true
+	}
+
+	if !response.MatchIfMatch(ifMatch, message) {
+		writePreconditionFailed(ctx, message)
+		return false
+	}
+	return true
+}
+
+// checkIfNoneMatch 检查 If-None-Match 请求头，消息没有修改时返回 304 和 true
+//
+// 需要在渲染为请求的格式之后调用，ETag 包括消息的格式。
+func checkIfNoneMatch(ctx *gin.Context, message *response.Message) bool {
+	ifNoneMatch := ctx.GetHeader("If-None-Match")
+	if ifNoneMatch == "" || !response.MatchIfNoneMatch(ifNoneMatch, message) {
+		return false
+	}
+
+	ctx.Header("ETag", response.MessageETag(message))
+	ctx.Status(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	lang "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/logs"
+	"net/http"
+)
+
+// MessageExport 导出消息
+//
+//	@Summary		导出消息
+//	@Description	导出自己发送的消息和自己作为接收者没有删除的消息，格式为 JSON Lines 或 CSV
+//	@Tags			message
+//	@Accept			json
+//	@Produce		application/x-ndjson,text/csv
+//	@Security		ApiKeyAuth
+//	@Param			format	query		string					false	"导出格式（jsonl/csv），为空时为 jsonl"
+//	@Param			filter	query		string					false	"过滤语句（title = 标题,status = 0|1|2,...）"
+//	@Success		200		{object}	response.ExportedMessage	"每行一条消息"
+//	@Failure		400		{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError		"凭证错误"
+//	@Failure		502		{object}	response.HTTPError		"系统异常"
+//	@Router			/message/export [get]
+func MessageExport(ctx *gin.Context) {
+	exportMessages(ctx, "MessageExport", ctx.GetString("token"))
+}
+
+// AdminMessageExport 导出所有消息
+//
+//	@Summary		导出所有消息
+//	@Description	导出所有没有删除的消息，格式为 JSON Lines 或 CSV，可以用于导入到其他环境
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		application/x-ndjson,text/csv
+//	@Security		ApiKeyAuth
+//	@Param			format	query		string					false	"导出格式（jsonl/csv），为空时为 jsonl"
+//	@Param			filter	query		string					false	"过滤语句（title = 标题,status = 0|1|2,...）"
+//	@Success		200		{object}	response.ExportedMessage	"每行一条消息"
+//	@Failure		400		{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError		"凭证错误"
+//	@Failure		502		{object}	response.HTTPError		"系统异常"
+//	@Router			/admin/message/export [get]
+func AdminMessageExport(ctx *gin.Context) {
+	exportMessages(ctx, "AdminMessageExport", "")
+}
+
+// exportMessages 逐条读取消息并写入响应，避免一次性读取所有数据
+func exportMessages(
+	ctx *gin.Context,
+	// 调用的接口名称，用于记录日志
+	name string,
+	// 消息凭证，为空时导出所有消息
+	token string,
+) {
+	// 从上下文中获取 messageExport
+	messageExport, messageExportExists := ctx.Get("messageExport")
+	// 从上下文中获取 messageFilters
+	messageFilter, messageFilterExists := ctx.Get("messageFilters")
+
+	// 检查 messageExport 是否存在
+	if !messageExportExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 messageExport 转换为 MessageExportRequest 类型
+	messageExportRequest := messageExport.(*request.MessageExportRequest)
+
+	var messageFilters []request.MessageFilterRequest
+	if messageFilterExists {
+		// 将 messageFilter 转换为 []MessageFilterRequest 类型
+		messageFilters = *messageFilter.(*[]request.MessageFilterRequest)
+	}
+
+	logs.LogInfo.Infof("%s %v %s", name, messageExportRequest, token)
+
+	var write func(message *response.ExportedMessage) error
+	var flush func() error
+	// writeError 响应头已经发送后出错时在数据的最后写入错误，没有写入时为 nil
+	var writeError func(message string)
+	// 声明在响应结束后发送的错误，导出中途出错时客户端可以根据它判断数据不完整
+	ctx.Header("Trailer", response.ExportErrorTrailer)
+	if messageExportRequest.Format == request.FormatCSV {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", `attachment; filename="message.csv"`)
+		ctx.Status(http.StatusOK)
+
+		writer := csv.NewWriter(ctx.Writer)
+		_ = writer.Write(response.MessageCSVColumns)
+		write = func(message *response.ExportedMessage) error {
+			if err := writer.Write(message.CSVRecord()); err != nil {
+				return err
+			}
+			writer.Flush()
+			ctx.Writer.Flush()
+			return writer.Error()
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		ctx.Header("Content-Type", "application/x-ndjson")
+		ctx.Header("Content-Disposition", `attachment; filename="message.jsonl"`)
+		ctx.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(ctx.Writer)
+		write = func(message *response.ExportedMessage) error {
+			if err := encoder.Encode(message); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+			return nil
+		}
+		flush = func() error {
+			return nil
+		}
+		writeError = func(message string) {
+			_ = encoder.Encode(gin.H{"error": response.EnvelopeError{
+				Code:    response.ErrInternal.Code,
+				Message: message,
+			}})
+		}
+	}
+
+	// 逐条写入消息
+	err := repository.ExportMessages(token, messageFilters, write)
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// 响应头已经发送，只能通过 trailer 和 JSON Lines 的最后一行告诉客户端导出没有完成
+		logs.LogError.Errorf("%s %s %s", name, err, token)
+		ctx.Writer.Header().Set(response.ExportErrorTrailer, response.ErrInternal.Code)
+		if writeError != nil {
+			writeError(lang.MustGetMessage(ctx, response.ErrInternal.Key))
+		}
+	}
+}
+
+// messageImportBatchSize 导入消息时每次写入数据库的消息数量
+const messageImportBatchSize = 100
+
+// maxMessageImportRecords 一次导入的消息的最大数量，导入前所有消息都读取到内存中
+const maxMessageImportRecords = 100000
+
+// errMessageImportTooLarge 导入的消息超过 maxMessageImportRecords 条
+var errMessageImportTooLarge = errors.New("message import too large")
+
+// AdminMessageImport 导入消息
+//
+//	@Summary		导入消息
+//	@Description	导入 JSON Lines 或 CSV 格式的消息，字段与导出的消息相同，保留消息id、时间、发送者、接收者和状态。消息id已经存在的消息跳过，读取并校验所有数据后在一个事务中导入，失败时不会导入任何消息，一次最多导入 100000 条消息。dryRun 为 true 时只校验数据
+//	@Tags			admin
+//	@Accept			application/x-ndjson,text/csv
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			format	query		string							false	"导入格式（jsonl/csv），为空时为 jsonl"
+//	@Param			dryRun	query		bool							false	"只校验数据，不写入数据库"
+//	@Param			_		body		string							true	"导入的数据"
+//	@Success		200		{object}	response.MessageImportResponse	"导入的结果"
+//	@Failure		400		{object}	request.ValidationError			"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError				"凭证错误"
+//	@Failure		413		{object}	response.HTTPError				"导入的消息过多"
+//	@Failure		502		{object}	response.HTTPError				"系统异常"
+//	@Router			/admin/message/import [post]
+func AdminMessageImport(ctx *gin.Context) {
+	// 从上下文中获取 messageImport
+	messageImport, messageImportExists := ctx.Get("messageImport")
+
+	// 检查 messageImport 是否存在
+	if !messageImportExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 messageImport 转换为 MessageImportRequest 类型
+	messageImportRequest := messageImport.(*request.MessageImportRequest)
+
+	logs.LogInfo.Infof("AdminMessageImport %v", messageImportRequest)
+
+	result := &response.MessageImportResponse{
+		DryRun: messageImportRequest.DryRun,
+		Errors: make([]response.MessageImportError, 0),
+	}
+
+	// 先读取并校验所有数据，校验通过的消息每 messageImportBatchSize 条分为一批，读取期间不占用数据库连接
+	var batches [][]*request.MessageImportRecord
+	count := 0
+	err := request.ReadMessageImport(
+		ctx.Request.Body,
+		messageImportRequest.Format,
+		func(line int, record *request.MessageImportRecord, errors []request.ValidationError) error {
+			result.Total++
+			if len(errors) > 0 {
+				messageId := ""
+				if record != nil {
+					messageId = record.MessageId
+				}
+				result.AddError(line, messageId, errors)
+				return nil
+			}
+
+			count++
+			if count > maxMessageImportRecords {
+				return errMessageImportTooLarge
+			}
+			if count%messageImportBatchSize == 1 {
+				batches = append(batches, make([]*request.MessageImportRecord, 0, messageImportBatchSize))
+			}
+			batches[len(batches)-1] = append(batches[len(batches)-1], record)
+			return nil
+		},
+	)
+	if errors.Is(err, errMessageImportTooLarge) {
+		logs.LogInfo.Infof("AdminMessageImport-失败-数据过多 %d", count)
+		response.NewError(
+			ctx,
+			http.StatusRequestEntityTooLarge,
+			response.ErrImportTooLarge,
+		)
+		return
+	}
+	if err != nil {
+		logs.LogError.Errorf("AdminMessageImport %s", err)
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 所有消息在一个事务中导入
+	result.Imported, result.Skipped, err = repository.ImportMessages(
+		messageImportRequest.DryRun,
+		auditor(ctx),
+		batches,
+	)
+	if err != nil {
+		// 事务已经回滚，没有导入任何消息
+		logs.LogError.Errorf("AdminMessageImport %s", err)
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	logs.LogInfo.Infof(
+		"AdminMessageImport-成功 %d %d %d %d",
+		result.Total,
+		result.Imported,
+		result.Skipped,
+		result.Invalid,
+	)
+
+	// 返回导入的结果
+	response.JSON(ctx, http.StatusOK, result)
+}
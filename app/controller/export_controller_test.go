@@ -0,0 +1,60 @@
+package controller_test
+
+import (
+	"fmt"
+	"message/app/model"
+	"message/app/response"
+	"message/config"
+	"message/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminMessageExportReportsTruncation(t *testing.T) {
+	db := testutil.Setup(t)
+	config.AppConfig.App.Admin.Token = "root-token"
+	r := testutil.NewRouter()
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "root-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	record := fmt.Sprintf(
+		`{"message_id": %q, "sender_ids": ["sender"], "introducer_ids": ["recipient"], "title": "标题", "content": "内容", "category": "notice", "big_content": "详细内容", "created_at": "2024-02-15T05:49:57Z"}`,
+		strings.Repeat("a", 32),
+	)
+	if w := send(http.MethodPost, "/admin/message/import", record+"\n"); w.Code != http.StatusOK {
+		t.Fatalf("POST /admin/message/import = %d, want 200", w.Code)
+	}
+
+	// 导出完整时不发送错误的 trailer
+	w := send(http.MethodGet, "/admin/message/export", "")
+	if w.Code != http.StatusOK || w.Result().Trailer.Get(response.ExportErrorTrailer) != "" {
+		t.Fatalf("GET /admin/message/export = %d %v, want 200 without an error trailer", w.Code, w.Result().Trailer)
+	}
+
+	// 查询接收者状态失败时响应头已经发送，错误在 trailer 和最后一行中返回
+	if err := db.Migrator().DropTable(&model.MessageRecipient{}); err != nil {
+		t.Fatalf("drop message_recipient: %s", err)
+	}
+	w = send(http.MethodGet, "/admin/message/export", "")
+	if code := w.Result().Trailer.Get(response.ExportErrorTrailer); code != response.ErrInternal.Code {
+		t.Fatalf("export error trailer = %q, want %q", code, response.ErrInternal.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if last := lines[len(lines)-1]; !strings.Contains(last, `"code":"internal_error"`) {
+		t.Fatalf("last export line = %s, want the error record", last)
+	}
+
+	// CSV 只能通过 trailer 判断
+	w = send(http.MethodGet, "/admin/message/export?format=csv", "")
+	if code := w.Result().Trailer.Get(response.ExportErrorTrailer); code != response.ErrInternal.Code {
+		t.Fatalf("csv export error trailer = %q, want %q", code, response.ErrInternal.Code)
+	}
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"message/app/event"
+	"message/app/response"
+	"message/app/worker"
+	"message/database"
+	"message/logs"
+	"net/http"
+	"time"
+)
+
+// readyTimeout 就绪检查中访问数据库的超时时间
+const readyTimeout = 2 * time.Second
+
+// 就绪检查不需要认证，只返回固定的错误信息，具体的错误写到日志中
+const (
+	errDatabaseUnavailable = "数据库不可用"
+	errCheckMigrations     = "检查迁移失败"
+	errPendingMigrations   = "有没有执行的迁移，需要执行 migrate up"
+	errWorkerFailed        = "最近一次执行失败"
+)
+
+// Healthz 存活检查
+//
+//	@Summary		存活检查
+//	@Description	进程可以处理请求时返回 200，不检查数据库等依赖
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	response.Health	"服务正常"
+//	@Router			/healthz [get]
+func Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, response.Health{Status: response.HealthOK})
+}
+
+// Readyz 就绪检查
+//
+//	@Summary		就绪检查
+//	@Description	检查数据库连接和迁移的版本，并返回后台任务的状态和丢弃的事件数量
+//	@Description	数据库不可用或者有没有执行的迁移时返回 503，后台任务失败时状态为 degraded，仍然返回 200
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	response.Health	"可以处理请求"
+//	@Failure		503	{object}	response.Health	"不能处理请求"
+//	@Router			/readyz [get]
+func Readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), readyTimeout)
+	defer cancel()
+
+	health := response.Health{
+		Status: response.HealthOK,
+		Checks: map[string]response.HealthCheck{
+			"database":   checkDatabase(checkCtx),
+			"migrations": checkMigrations(),
+			"workers":    checkWorkers(),
+			"events":     checkEvents(),
+		},
+	}
+
+	// 任意一项不可用时服务不可用，只有非必需的检查异常时服务降级
+	for _, check := range health.Checks {
+		switch check.Status {
+		case response.HealthUnavailable:
+			health.Status = response.HealthUnavailable
+		case response.HealthDegraded:
+			if health.Status == response.HealthOK {
+				health.Status = response.HealthDegraded
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if health.Status == response.HealthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, health)
+}
+
+// checkDatabase 检查数据库是否可以访问
+func checkDatabase(ctx context.Context) response.HealthCheck {
+	if err := database.Ping(ctx); err != nil {
+		logs.LogError.Errorf("Readyz-数据库不可用 %s", err)
+		return response.HealthCheck{Status: response.HealthUnavailable, Error: errDatabaseUnavailable}
+	}
+	return response.HealthCheck{Status: response.HealthOK}
+}
+
+// checkMigrations 检查数据库的版本和程序是否相同
+func checkMigrations() response.HealthCheck {
+	if !database.Connected() {
+		return response.HealthCheck{Status: response.HealthUnavailable, Error: errDatabaseUnavailable}
+	}
+
+	pending, err := database.CheckSchema()
+	if err != nil {
+		logs.LogError.Errorf("Readyz-检查迁移失败 %s", err)
+		return response.HealthCheck{Status: response.HealthUnavailable, Error: errCheckMigrations}
+	}
+
+	details := gin.H{"latest": database.LatestVersion(), "pending": pending}
+	if pending > 0 {
+		return response.HealthCheck{
+			Status:  response.HealthUnavailable,
+			Error:   errPendingMigrations,
+			Details: details,
+		}
+	}
+	return response.HealthCheck{Status: response.HealthOK, Details: details}
+}
+
+// checkWorkers 返回后台任务的状态，最近一次执行失败时服务降级，具体的错误在后台任务的日志中
+func checkWorkers() response.HealthCheck {
+	check := response.HealthCheck{Status: response.HealthOK}
+	workers := make([]response.WorkerHealth, 0)
+	for _, status := range worker.Statuses() {
+		workerHealth := response.WorkerHealth{
+			Name:     status.Name,
+			Running:  status.Running,
+			Failures: status.Failures,
+		}
+		if !status.LastRunAt.IsZero() {
+			lastRunAt := status.LastRunAt
+			workerHealth.LastRunAt = &lastRunAt
+		}
+		if status.LastError != nil {
+			workerHealth.LastError = errWorkerFailed
+			check.Status = response.HealthDegraded
+		}
+		workers = append(workers, workerHealth)
+	}
+	check.Details = workers
+	return check
+}
+
+// checkEvents 返回启动后因为订阅的队列已满丢弃的事件数量，丢弃事件不影响处理请求
+func checkEvents() response.HealthCheck {
+	return response.HealthCheck{Status: response.HealthOK, Details: gin.H{"dropped": event.Dropped()}}
+}
@@ -0,0 +1,63 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"message/app/response"
+	"message/database"
+	"message/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadyzHidesErrorDetails(t *testing.T) {
+	db := testutil.Setup(t)
+	r := testutil.NewRouter()
+
+	readyz := func() (int, response.Health) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var health response.Health
+		if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+			t.Fatalf("decode /readyz: %s %s", err, w.Body.String())
+		}
+		return w.Code, health
+	}
+
+	if code, health := readyz(); code != http.StatusOK || health.Status != response.HealthOK {
+		t.Fatalf("GET /readyz = %d %+v, want 200 ok", code, health)
+	}
+
+	// 连接池关闭后 Ping 失败，只返回固定的说明，不返回驱动的错误
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB: %s", err)
+	}
+	if err := sqlDB.Close(); err != nil {
+		t.Fatalf("close database: %s", err)
+	}
+	code, health := readyz()
+	if code != http.StatusServiceUnavailable || health.Status != response.HealthUnavailable {
+		t.Fatalf("GET /readyz with a closed database = %d %+v, want 503 unavailable", code, health)
+	}
+	if got := health.Checks["database"].Error; got != "数据库不可用" {
+		t.Fatalf("database error = %q, want the fixed message", got)
+	}
+	for name, check := range health.Checks {
+		if strings.Contains(check.Error, "sql") {
+			t.Fatalf("%s error = %q, want no driver error", name, check.Error)
+		}
+	}
+
+	// 没有连接数据库时 /readyz 和需要数据库的接口都返回 503
+	database.Use(nil)
+	if code, health := readyz(); code != http.StatusServiceUnavailable || health.Checks["database"].Error != "数据库不可用" {
+		t.Fatalf("GET /readyz without a database = %d %+v, want 503", code, health)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/message", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GET /message without a database = %d, want 503", w.Code)
+	}
+}
@@ -1,8 +1,10 @@
 package controller
 
 import (
-	lang "github.com/gin-contrib/i18n"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"message/app/model"
 	"message/app/repository"
 	"message/app/request"
 	"message/app/response"
@@ -19,10 +21,12 @@ import (
 //	@Produce		json
 //	@Security		ApiKeyAuth
 //	@Param			filter		query		string					false	"过滤语句（title = 标题,status = 0|1|2,...）"
-//	@Param			sortColumn	query		string					false	"排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status）"
+//	@Param			sortColumn	query		string					false	"排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status|priority）"
 //	@Param			sortType	query		string					false	"排序类型（asc/desc）"
 //	@Param			page		query		int						false	"查询第几页数据"
-//	@Success		200			{array}		[]response.Message		"消息信息"
+//	@Param			format		query		string					false	"复杂消息的返回格式（html/text），为空时原样返回"
+//	@Param			pinnedFirst	query		bool					false	"置顶的消息排在最前面"
+//	@Success		200			{array}		[]response.InboxMessage	"消息信息"
 //	@Failure		400			{object}	request.ValidationError	"请求参数错误"
 //	@Failure		401			{object}	response.HTTPError		"凭证错误"
 //	@Failure		502			{object}	response.HTTPError		"系统异常"
@@ -40,7 +44,7 @@ func MessageIndex(ctx *gin.Context) {
 		response.NewError(
 			ctx,
 			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
+			response.ErrInternal,
 		)
 		return
 	}
@@ -65,30 +69,88 @@ func MessageIndex(ctx *gin.Context) {
 	logs.LogInfo.Infof("MessageIndex %v %s", messageRequest, messageToken)
 
 	// 返回查询结果
-	ctx.JSON(
-		http.StatusOK,
-		repository.QueryMessagesByMessageTokenMessageRequest(
-			messageToken,
-			messageRequest,
-			messageFilters,
-		),
+	messages := repository.QueryMessagesByMessageTokenMessageRequest(
+		messageToken,
+		messageRequest,
+		messageFilters,
+	)
+	response.Page(ctx, messageRequest.Page, messages)
+}
+
+// MessageSearch 搜索消息
+//
+//	@Summary		搜索消息
+//	@Description	根据关键字全文搜索标题、内容和详细内容，按照相关度排序并返回高亮的摘要
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			q		query		string							true	"搜索的关键字"
+//	@Param			filter	query		string							false	"过滤语句（title = 标题,status = 0|1|2,...）"
+//	@Param			page	query		int								false	"查询第几页数据"
+//	@Param			format	query		string							false	"复杂消息的返回格式（html/text），为空时原样返回"
+//	@Success		200		{array}		[]response.MessageSearchResult	"搜索到的消息"
+//	@Failure		400		{object}	request.ValidationError			"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError				"凭证错误"
+//	@Failure		502		{object}	response.HTTPError				"系统异常"
+//	@Router			/message/search [get]
+func MessageSearch(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageSearch
+	messageSearch, messageSearchExists := ctx.Get("messageSearch")
+	// 从上下文中获取 messageFilters
+	messageFilter, messageFilterExists := ctx.Get("messageFilters")
+
+	// 检查 token 和 messageSearch 是否存在
+	if !tokenExists || !messageSearchExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageSearch 转换为 MessageSearchRequest 类型
+	messageSearchRequest := messageSearch.(*request.MessageSearchRequest)
+
+	var messageFilters []request.MessageFilterRequest
+	if messageFilterExists {
+		// 将 messageFilter 转换为 []MessageFilterRequest 类型
+		messageFilters = *messageFilter.(*[]request.MessageFilterRequest)
+	}
+
+	logs.LogInfo.Infof("MessageSearch %v %s", messageSearchRequest, messageToken)
+
+	// 返回搜索结果
+	messages := repository.SearchMessages(
+		messageToken,
+		messageSearchRequest,
+		messageFilters,
 	)
+	response.Page(ctx, messageSearchRequest.Page, messages)
 }
 
 // MessageCreate 创建消息
 //
 //	@Summary		创建消息
-//	@Description	创建消息
+//	@Description	创建消息。携带 Idempotency-Key 时，同一个键和相同的内容重试返回之前创建的消息，并设置响应头 Idempotent-Replayed
 //	@Tags			message
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
-//	@Param			_	body		request.MessageCreateUpdateRequest	true	"创建的数据"
-//	@Success		200	{object}	response.Message					"创建成功"
-//	@Success		202	{object}	response.HTTPError					"创建失败"
-//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
-//	@Failure		401	{object}	response.HTTPError					"凭证错误"
-//	@Failure		502	{object}	response.HTTPError					"系统异常"
+//	@Param			Idempotency-Key	header		string								false	"幂等键，同一个发送者在有效期内只创建一条消息"
+//	@Param			_				body		request.MessageCreateUpdateRequest	true	"创建的数据"
+//	@Success		200				{object}	response.Message					"创建成功"
+//	@Success		202				{object}	response.HTTPError					"创建失败"
+//	@Failure		400				{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401				{object}	response.HTTPError					"凭证错误"
+//	@Failure		409				{object}	response.HTTPError					"幂等键已经用于内容不同的请求"
+//	@Failure		410				{object}	response.HTTPError					"幂等键之前创建的消息已经被彻底删除"
+//	@Failure		502				{object}	response.HTTPError					"系统异常"
 //	@Router			/message [post]
 func MessageCreate(ctx *gin.Context) {
 	// 从上下文中获取 token
@@ -101,7 +163,7 @@ func MessageCreate(ctx *gin.Context) {
 		response.NewError(
 			ctx,
 			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
+			response.ErrInternal,
 		)
 		return
 	}
@@ -111,28 +173,123 @@ func MessageCreate(ctx *gin.Context) {
 	// 将 messageCreateUpdate 转换为 MessageCreateUpdateRequest 类型
 	messageCreateRequest := messageCreate.(*request.MessageCreateUpdateRequest)
 
-	// 创建消息
-	message, err := repository.CreateMessage(
-		messageToken,
-		messageCreateRequest,
-	)
+	// 创建消息，携带幂等键时重试返回之前创建的消息
+	var message *response.Message
+	var replayed bool
+	var err error
+	if idempotencyKey := ctx.GetString("idempotencyKey"); idempotencyKey != "" {
+		message, replayed, err = repository.CreateIdempotentMessage(
+			messageToken,
+			idempotencyKey,
+			messageCreateRequest,
+			auditor(ctx),
+		)
+	} else {
+		message, err = repository.CreateMessage(
+			messageToken,
+			messageCreateRequest,
+			auditor(ctx),
+		)
+	}
 
+	if errors.Is(err, repository.ErrIdempotencyKeyReused) {
+		// 幂等键已经用于内容不同的请求，返回状态码 Conflict
+		response.NewError(
+			ctx,
+			http.StatusConflict,
+			response.ErrIdempotencyKeyReused,
+		)
+		logs.LogInfo.Infof("MessageCreate-失败-幂等键冲突 %s", messageToken)
+		return
+	}
+	if errors.Is(err, repository.ErrIdempotentMessageGone) {
+		// 幂等键之前创建的消息已经被彻底删除，返回状态码 Gone
+		response.NewError(
+			ctx,
+			http.StatusGone,
+			response.ErrIdempotentMessageGone,
+		)
+		logs.LogInfo.Infof("MessageCreate-失败-消息已删除 %s", messageToken)
+		return
+	}
 	if err != nil {
 		// 如果创建失败，返回状态码 Accepted
 		response.NewError(
 			ctx,
 			http.StatusAccepted,
-			lang.MustGetMessage(ctx, "createMessageFail"),
+			response.ErrCreateMessageFailed,
 		)
 
 		logs.LogInfo.Infof("MessageCreate-失败 %s %s", err, messageToken)
 		return
 	}
 
+	if replayed {
+		// 返回之前创建的消息，不重复记录审计日志
+		logs.LogInfo.Infof("MessageCreate-成功-重试 %s %s", message.MessageId, messageToken)
+		ctx.Header("Idempotent-Replayed", "true")
+		writeMessage(ctx, http.StatusOK, message)
+		return
+	}
+
 	logs.LogInfo.Infof("MessageCreate-成功 %s", messageToken)
 
 	// 返回创建成功的消息
-	ctx.JSON(http.StatusOK, message)
+	writeMessage(ctx, http.StatusOK, message)
+}
+
+// MessageBatchCreate 批量创建消息
+//
+//	@Summary		批量创建消息
+//	@Description	根据数组的数据批量创建消息，每条消息单独校验，返回每条消息的结果。atomic 为 true 时任意一条消息失败则全部不创建
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			atomic	query		bool									false	"任意一条消息失败则全部不创建"
+//	@Param			_		body		[]request.MessageCreateUpdateRequest	true	"创建的数据，最多 1000 条"
+//	@Success		200		{array}		[]response.MessageBatchResponse			"每条消息的结果"
+//	@Failure		400		{object}	request.ValidationError					"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError						"凭证错误"
+//	@Failure		502		{object}	response.HTTPError						"系统异常"
+//	@Router			/message/batch [post]
+func MessageBatchCreate(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageBatchQuery
+	messageBatchQuery, messageBatchQueryExists := ctx.Get("messageBatchQuery")
+	// 从上下文中获取 messageBatch
+	messageBatch, messageBatchExists := ctx.Get("messageBatch")
+
+	// 检查 token、messageBatchQuery 和 messageBatch 是否存在
+	if !tokenExists || !messageBatchQueryExists || !messageBatchExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageBatchQuery 转换为 MessageBatchQueryRequest 类型
+	messageBatchQueryRequest := messageBatchQuery.(*request.MessageBatchQueryRequest)
+	// 将 messageBatch 转换为 []MessageBatchItem 类型
+	messageBatchItems := messageBatch.(*[]request.MessageBatchItem)
+
+	logs.LogInfo.Infof("MessageBatchCreate %d %v %s", len(*messageBatchItems), messageBatchQueryRequest.Atomic, messageToken)
+
+	// 批量创建消息
+	results := repository.CreateMessagesInBatches(
+		messageToken,
+		messageBatchItems,
+		messageBatchQueryRequest.Atomic,
+		auditor(ctx),
+	)
+
+	// 返回每条消息的结果
+	response.JSON(ctx, http.StatusOK, results)
 }
 
 // MessageUpdate 更新消息
@@ -143,14 +300,17 @@ func MessageCreate(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
-//	@Param			id	path		string								true	"消息id"
-//	@Param			_	body		request.MessageCreateUpdateRequest	true	"更新消息"
-//	@Success		200	{object}	response.Message					"更新成功"
-//	@Success		202	{object}	response.HTTPError					"更新失败"
-//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
-//	@Failure		401	{object}	response.HTTPError					"凭证错误"
-//	@Failure		404	{object}	response.HTTPError					"找不到数据"
-//	@Failure		502	{object}	response.HTTPError					"系统异常"
+//	@Param			id			path		string								true	"消息id"
+//	@Param			If-Match	header		string								false	"消息的 ETag，消息已经被修改时返回 412"
+//	@Param			_			body		request.MessageCreateUpdateRequest	true	"更新消息"
+//	@Success		200			{object}	response.Message					"更新成功"
+//	@Success		202			{object}	response.HTTPError					"更新失败"
+//	@Failure		400			{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError					"凭证错误"
+//	@Failure		404			{object}	response.HTTPError					"找不到数据"
+//	@Failure		412			{object}	response.Message					"消息已经被修改，返回当前的消息"
+//	@Failure		428			{object}	response.HTTPError					"没有携带 If-Match 请求头"
+//	@Failure		502			{object}	response.HTTPError					"系统异常"
 //	@Router			/message/{id} [put]
 func MessageUpdate(ctx *gin.Context) {
 	// 从上下文中获取 token
@@ -163,7 +323,7 @@ func MessageUpdate(ctx *gin.Context) {
 		response.NewError(
 			ctx,
 			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
+			response.ErrInternal,
 		)
 		return
 	}
@@ -173,6 +333,70 @@ func MessageUpdate(ctx *gin.Context) {
 	// 将 messageCreateUpdate 转换为 MessageCreateUpdateRequest 类型
 	messageUpdateRequest := messageUpdate.(*request.MessageCreateUpdateRequest)
 
+	// 更新消息
+	updateMessage(ctx, "MessageUpdate", messageToken, func(message *model.Message) (*response.Message, error) {
+		return repository.UpdateMessage(message, messageUpdateRequest, auditor(ctx))
+	})
+}
+
+// MessagePatch 部分更新消息
+//
+//	@Summary		部分更新消息
+//	@Description	按照 JSON Merge Patch 的语义更新消息，只更新请求中存在的字段，可以单独添加或移除接收者
+//	@Tags			message
+//	@Accept			json
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string						true	"消息id"
+//	@Param			If-Match	header		string						false	"消息的 ETag，消息已经被修改时返回 412"
+//	@Param			_			body		request.MessagePatchRequest	true	"更新的字段"
+//	@Success		200			{object}	response.Message			"更新成功"
+//	@Success		202			{object}	response.HTTPError			"更新失败"
+//	@Failure		400			{object}	request.ValidationError		"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError			"凭证错误"
+//	@Failure		404			{object}	response.HTTPError			"找不到数据"
+//	@Failure		412			{object}	response.Message			"消息已经被修改，返回当前的消息"
+//	@Failure		428			{object}	response.HTTPError			"没有携带 If-Match 请求头"
+//	@Failure		502			{object}	response.HTTPError			"系统异常"
+//	@Router			/message/{id} [patch]
+func MessagePatch(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messagePatch
+	messagePatch, messagePatchExists := ctx.Get("messagePatch")
+
+	// 检查 token 和 messagePatch 是否存在
+	if !tokenExists || !messagePatchExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messagePatch 转换为 MessagePatchRequest 类型
+	messagePatchRequest := messagePatch.(*request.MessagePatchRequest)
+
+	// 部分更新消息
+	updateMessage(ctx, "MessagePatch", messageToken, func(message *model.Message) (*response.Message, error) {
+		return repository.PatchMessage(message, messagePatchRequest, auditor(ctx))
+	})
+}
+
+// updateMessage 查询发送者的消息，检查 If-Match 请求头后更新消息并返回更新后的消息
+func updateMessage(
+	ctx *gin.Context,
+	// 调用的接口名称，用于记录日志
+	name string,
+	// 消息凭证
+	messageToken string,
+	// 更新消息的方法
+	update func(message *model.Message) (*response.Message, error),
+) {
 	// 根据id查询消息
 	oldMessage := repository.QueryMessageById(
 		messageToken,
@@ -184,32 +408,60 @@ func MessageUpdate(ctx *gin.Context) {
 		response.NewError(
 			ctx,
 			http.StatusNotFound,
-			lang.MustGetMessage(ctx, "notFound"),
+			response.ErrNotFound,
 		)
 		return
 	}
 
+	// 检查 If-Match 请求头
+	if !checkIfMatch(ctx, response.NewMessage(oldMessage)) {
+		logs.LogInfo.Infof("%s-失败-If-Match 条件不满足 %s", name, messageToken)
+		return
+	}
+
 	// 更新消息
-	messageNew, err := repository.UpdateMessage(
-		oldMessage,
-		messageUpdateRequest,
-	)
+	messageNew, err := update(oldMessage)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		// 合并后的结构化数据超过大小限制
+		request.HandlingValidateErrors(ctx, err)
+		logs.LogInfo.Infof("%s-失败-参数错误 %s", name, messageToken)
+		return
+	}
+	if errors.Is(err, repository.ErrMessageNoIntroducer) {
+		// 如果更新后消息没有接收者，返回状态码 BadRequest
+		response.NewError(
+			ctx,
+			http.StatusBadRequest,
+			response.ErrIntroducerRequired,
+		)
+		logs.LogInfo.Infof("%s-失败-没有接收者 %s", name, messageToken)
+		return
+	}
+	if errors.Is(err, repository.ErrMessageConflict) {
+		// 如果消息在更新时被其他请求修改，返回状态码 PreconditionFailed 和当前的消息
+		if latestMessage := repository.QueryMessageById(messageToken, ctx.Param("id")); latestMessage != nil {
+			writePreconditionFailed(ctx, response.NewMessage(latestMessage))
+			logs.LogInfo.Infof("%s-失败-消息已经被修改 %s", name, messageToken)
+			return
+		}
+	}
 	if err != nil {
 		// 如果更新失败，返回状态码 Accepted
 		response.NewError(
 			ctx,
 			http.StatusAccepted,
-			lang.MustGetMessage(ctx, "updateMessageFail"),
+			response.ErrUpdateMessageFailed,
 		)
 
-		logs.LogInfo.Infof("MessageUpdate-失败 %s %s", err, messageToken)
+		logs.LogInfo.Infof("%s-失败 %s %s", name, err, messageToken)
 		return
 	}
 
-	logs.LogInfo.Infof("MessageUpdate-成功 %s", messageToken)
+	logs.LogInfo.Infof("%s-成功 %s", name, messageToken)
 
 	// 返回更新成功后的消息
-	ctx.JSON(http.StatusOK, messageNew)
+	writeMessage(ctx, http.StatusOK, messageNew)
 }
 
 // MessageUpdateStatus 更新消息状态
@@ -237,7 +489,7 @@ func MessageUpdateStatus(ctx *gin.Context) {
 		response.NewError(
 			ctx,
 			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
+			response.ErrInternal,
 		)
 		return
 	}
@@ -249,14 +501,63 @@ func MessageUpdateStatus(ctx *gin.Context) {
 
 	logs.LogInfo.Infof("MessageUpdateStatus %v %s", messageStatusRequest, messageToken)
 
-	// 更新消息状态，并返回更新后的结果
-	ctx.JSON(
-		http.StatusOK,
-		repository.UpdateMessageStatus(
-			messageToken,
-			messageStatusRequest,
-		),
+	// 更新消息状态
+	results := repository.UpdateMessageStatus(
+		messageToken,
+		messageStatusRequest,
+		auditor(ctx),
 	)
+
+	// 返回更新后的结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// MessageFlag 置顶和标星消息
+//
+//	@Summary		置顶和标星
+//	@Description	根据数组的数据批量设置接收者自己的置顶和标星，只修改请求中存在的标记
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageFlagRequest	true	"置顶和标星"
+//	@Success		200	{object}	[]response.MessageFlagResponse	"设置后返回的数据"
+//	@Failure		400	{object}	request.ValidationError			"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError				"凭证错误"
+//	@Failure		502	{object}	response.HTTPError				"系统异常"
+//	@Router			/message/flags [put]
+func MessageFlag(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageFlag
+	messageFlag, messageFlagExists := ctx.Get("messageFlag")
+
+	// 检查 token 和 messageFlag 是否存在
+	if !tokenExists || !messageFlagExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageFlag 转换为 []MessageFlagRequest 类型
+	messageFlagRequests := messageFlag.(*[]request.MessageFlagRequest)
+
+	logs.LogInfo.Infof("MessageFlag %v %s", messageFlagRequests, messageToken)
+
+	// 设置置顶和标星
+	results := repository.UpdateMessageFlags(
+		messageToken,
+		messageFlagRequests,
+		auditor(ctx),
+	)
+
+	// 返回设置结果
+	response.JSON(ctx, http.StatusOK, results)
 }
 
 // MessageDelete 删除消息
@@ -267,25 +568,27 @@ func MessageUpdateStatus(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
-//	@Param			_	body		[]request.MessageDeleteRequest		true	"删除的消息"
-//	@Success		200	{object}	[]response.MessageDeleteResponse	"更新后返回的数据"
-//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
-//	@Failure		401	{object}	response.HTTPError					"凭证错误"
-//	@Failure		404	{string}	string								"找不到兑换码"
-//	@Failure		502	{string}	string								"系统异常"
+//	@Param			atomic	query		bool								false	"任意一条消息删除失败则全部回滚"
+//	@Param			_		body		[]request.MessageDeleteRequest		true	"删除的消息"
+//	@Success		200		{object}	[]response.MessageDeleteResponse	"每条消息的删除结果"
+//	@Failure		400		{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401		{object}	response.HTTPError					"凭证错误"
+//	@Failure		502		{object}	response.HTTPError					"系统异常"
 //	@Router			/message [delete]
 func MessageDelete(ctx *gin.Context) {
 	// 从上下文中获取 token
 	token, tokenExists := ctx.Get("token")
 	// 从上下文中获取 messageDelete
 	messageDelete, messageDeleteExists := ctx.Get("messageDelete")
+	// 从上下文中获取 messageDeleteQuery
+	messageDeleteQuery, messageDeleteQueryExists := ctx.Get("messageDeleteQuery")
 
-	// 检查 token 和 messageDelete 是否存在
-	if !tokenExists || !messageDeleteExists {
+	// 检查 token、messageDelete 和 messageDeleteQuery 是否存在
+	if !tokenExists || !messageDeleteExists || !messageDeleteQueryExists {
 		response.NewError(
 			ctx,
 			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
+			response.ErrInternal,
 		)
 		return
 	}
@@ -294,15 +597,354 @@ func MessageDelete(ctx *gin.Context) {
 	messageToken := token.(string)
 	// 将 messageDelete 转换为 []MessageDeleteRequest 类型
 	messageDeleteRequests := messageDelete.(*[]request.MessageDeleteRequest)
+	// 将 messageDeleteQuery 转换为 MessageDeleteQueryRequest 类型
+	messageDeleteQueryRequest := messageDeleteQuery.(*request.MessageDeleteQueryRequest)
 
-	logs.LogInfo.Infof("MessageDelete %v %s", messageDeleteRequests, messageToken)
+	logs.LogInfo.Infof("MessageDelete %v %v %s", messageDeleteRequests, messageDeleteQueryRequest, messageToken)
 
-	// 删除消息，并返回删除结果
-	ctx.JSON(
-		http.StatusOK,
-		repository.DeleteMessagesById(
-			messageToken,
-			messageDeleteRequests,
-		),
+	// 删除消息
+	results := repository.DeleteMessagesById(
+		messageToken,
+		messageDeleteRequests,
+		messageDeleteQueryRequest.Atomic,
+		auditor(ctx),
+	)
+
+	// 返回删除结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// MessageTrash 查询已删除的消息
+//
+//	@Summary		查询已删除的消息
+//	@Description	根据用户凭证查询发送者已软删除的消息
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			filter		query		string					false	"过滤语句（title = 标题,status = 0|1|2,...）"
+//	@Param			sortColumn	query		string					false	"排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status|priority）"
+//	@Param			sortType	query		string					false	"排序类型（asc/desc）"
+//	@Param			page		query		int						false	"查询第几页数据"
+//	@Param			format		query		string					false	"复杂消息的返回格式（html/text），为空时原样返回"
+//	@Success		200			{array}		[]response.TrashMessage	"已删除的消息"
+//	@Failure		400			{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401			{object}	response.HTTPError		"凭证错误"
+//	@Failure		502			{object}	response.HTTPError		"系统异常"
+//	@Router			/message/trash [get]
+func MessageTrash(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 message
+	message, messageExists := ctx.Get("message")
+	// 从上下文中获取 messageFilters
+	messageFilter, messageFilterExists := ctx.Get("messageFilters")
+
+	// 检查 token 和 message 是否存在
+	if !tokenExists || !messageExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 message 转换为 MessageRequest 类型
+	messageRequest := message.(*request.MessageRequest)
+
+	var messageFilters []request.MessageFilterRequest
+	if messageFilterExists {
+		// 将 messageFilter 转换为 []MessageFilterRequest 类型
+		messageFilters = *messageFilter.(*[]request.MessageFilterRequest)
+	}
+
+	logs.LogInfo.Infof("MessageTrash %v %s", messageRequest, messageToken)
+
+	// 返回查询结果
+	messages := repository.QueryTrashMessagesByMessageToken(
+		messageToken,
+		messageRequest,
+		messageFilters,
+	)
+	response.Page(ctx, messageRequest.Page, messages)
+}
+
+// MessageRestore 恢复消息
+//
+//	@Summary		恢复消息
+//	@Description	根据数组的数据恢复发送者已软删除的消息
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageRestoreRequest		true	"恢复的消息"
+//	@Success		200	{object}	[]response.MessageRestoreResponse	"恢复后返回的数据"
+//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError					"凭证错误"
+//	@Failure		502	{object}	response.HTTPError					"系统异常"
+//	@Router			/message/restore [post]
+func MessageRestore(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageRestore
+	messageRestore, messageRestoreExists := ctx.Get("messageRestore")
+
+	// 检查 token 和 messageRestore 是否存在
+	if !tokenExists || !messageRestoreExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageRestore 转换为 []MessageRestoreRequest 类型
+	messageRestoreRequests := messageRestore.(*[]request.MessageRestoreRequest)
+
+	logs.LogInfo.Infof("MessageRestore %v %s", messageRestoreRequests, messageToken)
+
+	// 恢复消息
+	results := repository.RestoreMessagesById(
+		messageToken,
+		messageRestoreRequests,
+		auditor(ctx),
+	)
+
+	// 返回恢复结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// MessageHide 接收者删除消息
+//
+//	@Summary		接收者删除消息
+//	@Description	根据数组的数据删除接收者自己的消息，不影响其他接收者和发送者
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageHideRequest		true	"删除的消息"
+//	@Success		200	{object}	[]response.MessageDeleteResponse	"每条消息的删除结果"
+//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError					"凭证错误"
+//	@Failure		502	{object}	response.HTTPError					"系统异常"
+//	@Router			/message/inbox [delete]
+func MessageHide(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageHide
+	messageHide, messageHideExists := ctx.Get("messageHide")
+
+	// 检查 token 和 messageHide 是否存在
+	if !tokenExists || !messageHideExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageHide 转换为 []MessageHideRequest 类型
+	messageHideRequests := messageHide.(*[]request.MessageHideRequest)
+
+	logs.LogInfo.Infof("MessageHide %v %s", messageHideRequests, messageToken)
+
+	// 删除接收者自己的消息
+	results := repository.HideMessagesById(
+		messageToken,
+		messageHideRequests,
+		auditor(ctx),
+	)
+
+	// 返回删除结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// MessageRetract 发送者撤回消息
+//
+//	@Summary		发送者撤回消息
+//	@Description	根据数组的数据撤回发送者的消息，撤回后所有接收者都看不到消息，发送者可以在回收站中恢复
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageRetractRequest		true	"撤回的消息"
+//	@Success		200	{object}	[]response.MessageDeleteResponse	"每条消息的撤回结果"
+//	@Failure		400	{object}	request.ValidationError				"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError					"凭证错误"
+//	@Failure		502	{object}	response.HTTPError					"系统异常"
+//	@Router			/message/retract [post]
+func MessageRetract(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageRetract
+	messageRetract, messageRetractExists := ctx.Get("messageRetract")
+
+	// 检查 token 和 messageRetract 是否存在
+	if !tokenExists || !messageRetractExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageRetract 转换为 []MessageRetractRequest 类型
+	messageRetractRequests := messageRetract.(*[]request.MessageRetractRequest)
+
+	logs.LogInfo.Infof("MessageRetract %v %s", messageRetractRequests, messageToken)
+
+	// 撤回发送者的消息
+	results := repository.RetractMessagesById(
+		messageToken,
+		messageRetractRequests,
+		auditor(ctx),
+	)
+
+	// 返回撤回结果
+	response.JSON(ctx, http.StatusOK, results)
+}
+
+// MessageShow 查询一条消息
+//
+//	@Summary		查询一条消息
+//	@Description	根据消息id查询发送者或接收者可以看到的消息，可以指定查询的版本
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id				path		string					true	"消息id"
+//	@Param			version			query		int						false	"消息版本，为空时返回当前版本"
+//	@Param			format			query		string					false	"复杂消息的返回格式（html/text），为空时原样返回"
+//	@Param			If-None-Match	header		string					false	"消息的 ETag，消息没有修改时返回 304"
+//	@Success		200				{object}	response.Message		"消息信息"
+//	@Success		304				{string}	string					"消息没有修改"
+//	@Failure		400				{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401				{object}	response.HTTPError		"凭证错误"
+//	@Failure		404				{object}	response.HTTPError		"找不到数据"
+//	@Failure		502				{object}	response.HTTPError		"系统异常"
+//	@Router			/message/{id} [get]
+func MessageShow(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageVersion
+	messageVersion, messageVersionExists := ctx.Get("messageVersion")
+
+	// 检查 token 和 messageVersion 是否存在
+	if !tokenExists || !messageVersionExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageVersion 转换为 MessageVersionRequest 类型
+	messageVersionRequest := messageVersion.(*request.MessageVersionRequest)
+
+	logs.LogInfo.Infof("MessageShow %s %v %s", ctx.Param("id"), messageVersionRequest, messageToken)
+
+	// 根据id查询消息
+	message := repository.QueryVisibleMessageById(
+		messageToken,
+		ctx.Param("id"),
 	)
+
+	// 查询消息的指定版本
+	var messageResponse *response.Message
+	if message != nil {
+		messageResponse = repository.QueryMessageVersion(
+			message,
+			messageVersionRequest.Version,
+		)
+	}
+
+	if messageResponse == nil {
+		// 如果找不到对应的消息或版本，返回状态码 NotFound
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	}
+
+	// 将详细内容渲染为请求的格式
+	messageResponse.Render(messageVersionRequest.Format)
+
+	// 消息没有修改时返回 NotModified，ETag 包括渲染后的格式
+	if checkIfNoneMatch(ctx, messageResponse) {
+		return
+	}
+
+	// 返回查询到的消息
+	writeMessage(ctx, http.StatusOK, messageResponse)
+}
+
+// MessageHistory 查询消息的历史版本
+//
+//	@Summary		查询消息的历史版本
+//	@Description	根据消息id查询消息的所有版本，按照版本号从小到大排序，最后一个是当前版本
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string						true	"消息id"
+//	@Success		200	{array}		[]response.MessageVersion	"消息的所有版本"
+//	@Failure		400	{object}	request.ValidationError		"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError			"凭证错误"
+//	@Failure		404	{object}	response.HTTPError			"找不到数据"
+//	@Failure		502	{object}	response.HTTPError			"系统异常"
+//	@Router			/message/{id}/history [get]
+func MessageHistory(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+
+	// 检查 token 是否存在
+	if !tokenExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+
+	logs.LogInfo.Infof("MessageHistory %s %s", ctx.Param("id"), messageToken)
+
+	// 根据id查询消息
+	message := repository.QueryVisibleMessageById(
+		messageToken,
+		ctx.Param("id"),
+	)
+
+	if message == nil {
+		// 如果找不到对应的消息，返回状态码 NotFound
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	}
+
+	// 返回消息的所有版本
+	response.JSON(ctx, http.StatusOK, repository.QueryMessageHistory(message))
 }
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/logs"
+	"net/http"
+)
+
+// MessageTagIndex 查询标签
+//
+//	@Summary		查询标签
+//	@Description	查询发送者设置的标签和自己的标签，以及使用每个标签的消息数量
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		[]response.TagCount	"标签和消息数量"
+//	@Failure		401	{object}	response.HTTPError	"凭证错误"
+//	@Failure		502	{object}	response.HTTPError	"系统异常"
+//	@Router			/message/tags [get]
+func MessageTagIndex(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+
+	// 检查 token 是否存在
+	if !tokenExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+
+	logs.LogInfo.Infof("MessageTagIndex %s", messageToken)
+
+	// 返回查询结果
+	response.JSON(ctx, http.StatusOK, repository.QueryTags(messageToken))
+}
+
+// MessageTagAdd 添加标签
+//
+//	@Summary		添加标签
+//	@Description	根据数组的数据批量添加标签，发送者的标签只有发送者可以添加，自己的标签只有自己可以看到
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageTagRequest		true	"添加的标签"
+//	@Success		200	{object}	[]response.MessageTagResponse	"添加后返回的数据"
+//	@Failure		400	{object}	request.ValidationError			"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError				"凭证错误"
+//	@Failure		502	{object}	response.HTTPError				"系统异常"
+//	@Router			/message/tags [post]
+func MessageTagAdd(ctx *gin.Context) {
+	updateMessageTags(ctx, "MessageTagAdd", repository.AddMessageTags)
+}
+
+// MessageTagRemove 移除标签
+//
+//	@Summary		移除标签
+//	@Description	根据数组的数据批量移除标签，发送者的标签只有发送者可以移除
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		[]request.MessageTagRequest		true	"移除的标签"
+//	@Success		200	{object}	[]response.MessageTagResponse	"移除后返回的数据"
+//	@Failure		400	{object}	request.ValidationError			"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError				"凭证错误"
+//	@Failure		502	{object}	response.HTTPError				"系统异常"
+//	@Router			/message/tags [delete]
+func MessageTagRemove(ctx *gin.Context) {
+	updateMessageTags(ctx, "MessageTagRemove", repository.RemoveMessageTags)
+}
+
+// updateMessageTags 添加或移除标签
+func updateMessageTags(
+	ctx *gin.Context,
+	// 调用的接口名称，用于记录日志
+	name string,
+	// 添加或移除标签的方法
+	update func(token string, tagRequests *[]request.MessageTagRequest, auditor *repository.Auditor) []response.MessageTagResponse,
+) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 messageTag
+	messageTag, messageTagExists := ctx.Get("messageTag")
+
+	// 检查 token 和 messageTag 是否存在
+	if !tokenExists || !messageTagExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 messageTag 转换为 []MessageTagRequest 类型
+	messageTagRequests := messageTag.(*[]request.MessageTagRequest)
+
+	logs.LogInfo.Infof("%s %v %s", name, messageTagRequests, messageToken)
+
+	// 添加或移除标签
+	results := update(messageToken, messageTagRequests, auditor(ctx))
+
+	// 返回操作结果
+	response.JSON(ctx, http.StatusOK, results)
+}
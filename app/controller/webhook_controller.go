@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/logs"
+	"net/http"
+)
+
+// WebhookShow 查询自己注册的 Webhook
+//
+//	@Summary		查询 Webhook
+//	@Description	查询发送者自己注册的 Webhook，不返回签名密钥
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	response.Webhook	"注册的 Webhook"
+//	@Failure		401	{object}	response.HTTPError	"凭证错误"
+//	@Failure		404	{object}	response.HTTPError	"没有注册 Webhook"
+//	@Failure		502	{object}	response.HTTPError	"系统异常"
+//	@Router			/message/webhook [get]
+func WebhookShow(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+
+	// 检查 token 是否存在
+	if !tokenExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+
+	logs.LogInfo.Infof("WebhookShow %s", messageToken)
+
+	webhook := repository.QueryWebhook(messageToken)
+	if webhook == nil {
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, response.NewWebhook(webhook))
+}
+
+// WebhookUpdate 注册或替换自己的 Webhook
+//
+//	@Summary		注册 Webhook
+//	@Description	注册或替换发送者自己的 Webhook，接收者点击自己发送的消息的操作按钮时将 message.action 事件 POST 到该地址
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			_	body		request.WebhookRequest	true	"Webhook 的地址和签名密钥"
+//	@Success		200	{object}	response.Webhook		"注册的 Webhook"
+//	@Failure		400	{object}	request.ValidationError	"请求参数错误"
+//	@Failure		401	{object}	response.HTTPError		"凭证错误"
+//	@Failure		502	{object}	response.HTTPError		"系统异常"
+//	@Router			/message/webhook [put]
+func WebhookUpdate(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+	// 从上下文中获取 webhook
+	webhook, webhookExists := ctx.Get("webhook")
+
+	// 检查 token 和 webhook 是否存在
+	if !tokenExists || !webhookExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+	// 将 webhook 转换为 WebhookRequest 类型
+	webhookRequest := webhook.(*request.WebhookRequest)
+
+	logs.LogInfo.Infof("WebhookUpdate %s %s", webhookRequest.Url, messageToken)
+
+	saved, err := repository.SaveWebhook(messageToken, webhookRequest)
+	if err != nil || saved == nil {
+		logs.LogError.Errorf("WebhookUpdate %s %s", err, messageToken)
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, response.NewWebhook(saved))
+}
+
+// WebhookDelete 删除自己的 Webhook
+//
+//	@Summary		删除 Webhook
+//	@Description	删除发送者自己注册的 Webhook，返回删除前的 Webhook
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	response.Webhook	"删除的 Webhook"
+//	@Failure		401	{object}	response.HTTPError	"凭证错误"
+//	@Failure		404	{object}	response.HTTPError	"没有注册 Webhook"
+//	@Failure		502	{object}	response.HTTPError	"系统异常"
+//	@Router			/message/webhook [delete]
+func WebhookDelete(ctx *gin.Context) {
+	// 从上下文中获取 token
+	token, tokenExists := ctx.Get("token")
+
+	// 检查 token 是否存在
+	if !tokenExists {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	// 将 token 转换为 MessageToken 类型
+	messageToken := token.(string)
+
+	logs.LogInfo.Infof("WebhookDelete %s", messageToken)
+
+	webhook := repository.QueryWebhook(messageToken)
+	if webhook == nil {
+		response.NewError(
+			ctx,
+			http.StatusNotFound,
+			response.ErrNotFound,
+		)
+		return
+	}
+	if _, err := repository.DeleteWebhook(messageToken); err != nil {
+		logs.LogError.Errorf("WebhookDelete %s %s", err, messageToken)
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, response.NewWebhook(webhook))
+}
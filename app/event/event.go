@@ -0,0 +1,213 @@
+package event
+
+import (
+	"message/logs"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 定义事件名称的常量
+const (
+	MessageCreated = "message.created" // 创建了新消息，数据为 *response.Message
+	MessageAction  = "message.action"  // 接收者点击消息的操作按钮
+)
+
+// Event 消息系统中发生的事件
+type Event struct {
+	// Name 事件名称
+	Name string `json:"event"`
+	// MessageId 事件相关的消息 ID
+	MessageId string `json:"message_id"`
+	// SenderIds 消息的发送者，接收事件的应用根据发送者处理事件
+	SenderIds []string `json:"sender_ids"`
+	// Actor 触发事件的用户凭证
+	Actor string `json:"actor"`
+	// Payload 事件的数据
+	Payload interface{} `json:"payload"`
+	// CreatedAt 事件发生的时间
+	CreatedAt time.Time `json:"created_at"`
+	// Urgent 是否是紧急消息的事件，紧急事件在普通事件之前处理
+	Urgent bool `json:"-"`
+}
+
+// Handler 处理事件的函数
+type Handler func(event Event) error
+
+// subscription 一个订阅，id 用于取消订阅，每个订阅使用自己的队列和协程依次执行处理函数
+type subscription struct {
+	id    uint64
+	queue *queue
+}
+
+// handlers 每个事件名称已订阅的处理函数，名称为 * 时订阅所有事件
+var handlers = struct {
+	sync.RWMutex
+	nextId uint64
+	values map[string][]subscription
+}{values: make(map[string][]subscription)}
+
+// queueSize 每个订阅等待执行的普通事件的最大数量，队列满时丢弃新的普通事件
+const queueSize = 1024
+
+// dropped 因为队列已满丢弃的事件数量
+var dropped atomic.Uint64
+
+// running 用于等待队列中和正在执行的处理函数结束
+var running sync.WaitGroup
+
+// queue 等待处理的事件，普通事件最多保存 size 个，紧急事件不限制数量并且先处理
+type queue struct {
+	mu     sync.Mutex
+	name   string
+	size   int
+	urgent []Event
+	normal []Event
+	closed bool
+	// ready 有新的事件或者队列关闭时通知等待的协程
+	ready chan struct{}
+}
+
+// newQueue 创建事件队列，name 用于记录丢弃事件的日志
+func newQueue(name string, size int) *queue {
+	return &queue{name: name, size: size, ready: make(chan struct{}, 1)}
+}
+
+// push 将事件放入队列，不会阻塞；普通事件超过容量或者队列已经关闭时丢弃事件并返回 false
+func (q *queue) push(event Event) bool {
+	q.mu.Lock()
+	if q.closed || (!event.Urgent && len(q.normal) >= q.size) {
+		q.mu.Unlock()
+		total := dropped.Add(1)
+		logs.LogError.Errorf("Event-丢弃事件 %s %s %s %d", q.name, event.Name, event.MessageId, total)
+		return false
+	}
+	running.Add(1)
+	if event.Urgent {
+		q.urgent = append(q.urgent, event)
+	} else {
+		q.normal = append(q.normal, event)
+	}
+	q.notify()
+	q.mu.Unlock()
+	return true
+}
+
+// notify 通知一个等待的协程，调用时需要持有锁，避免和 close 同时执行
+func (q *queue) notify() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// run 依次处理队列中的事件，紧急事件先处理，直到队列关闭；多个协程可以同时处理同一个队列
+func (q *queue) run(handler Handler) {
+	for {
+		event, ok := q.pop()
+		if !ok {
+			return
+		}
+		if err := handler(event); err != nil {
+			logs.LogError.Errorf("Event-处理失败 %s %s %s %s", q.name, event.Name, event.MessageId, err)
+		}
+		running.Done()
+	}
+}
+
+// pop 取出下一个事件，队列为空时等待，队列关闭后返回 false
+func (q *queue) pop() (Event, bool) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return Event{}, false
+		}
+		var event Event
+		var ok bool
+		if len(q.urgent) > 0 {
+			event, q.urgent, ok = q.urgent[0], q.urgent[1:], true
+		} else if len(q.normal) > 0 {
+			event, q.normal, ok = q.normal[0], q.normal[1:], true
+		}
+		if ok && (len(q.urgent) > 0 || len(q.normal) > 0) {
+			// 还有事件时通知其他等待的协程
+			q.notify()
+		}
+		q.mu.Unlock()
+
+		if ok {
+			return event, true
+		}
+		<-q.ready
+	}
+}
+
+// close 关闭队列，丢弃还没有处理的事件，正在执行的处理函数执行完后 run 返回
+func (q *queue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	remaining := len(q.urgent) + len(q.normal)
+	q.urgent, q.normal = nil, nil
+	close(q.ready)
+	q.mu.Unlock()
+
+	for i := 0; i < remaining; i++ {
+		running.Done()
+	}
+}
+
+// Subscribe 订阅事件，name 为 * 时订阅所有事件，返回取消订阅的函数
+//
+// 每个订阅使用一个协程依次执行处理函数，处理较慢的订阅不会影响其他订阅。
+func Subscribe(name string, handler Handler) func() {
+	handlers.Lock()
+	defer handlers.Unlock()
+	handlers.nextId++
+	id := handlers.nextId
+	events := newQueue(name, queueSize)
+	handlers.values[name] = append(handlers.values[name], subscription{id: id, queue: events})
+	go events.run(handler)
+
+	return func() {
+		handlers.Lock()
+		handlers.values[name] = slices.DeleteFunc(handlers.values[name], func(value subscription) bool {
+			return value.id == id
+		})
+		handlers.Unlock()
+		events.close()
+	}
+}
+
+// Publish 发布事件，将事件放入每个订阅的队列后立即返回，不会阻塞调用方
+//
+// 订阅的队列满时丢弃普通事件，丢弃的数量记录在日志中并可以通过 Dropped 查询，紧急事件不会被丢弃。
+func Publish(event Event) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	handlers.RLock()
+	defer handlers.RUnlock()
+	for _, value := range handlers.values[event.Name] {
+		value.queue.push(event)
+	}
+	for _, value := range handlers.values["*"] {
+		value.queue.push(event)
+	}
+}
+
+// Dropped 返回启动后因为队列已满丢弃的事件数量
+func Dropped() uint64 {
+	return dropped.Load()
+}
+
+// Wait 等待队列中和正在执行的处理函数结束，例如停止服务前等待 Webhook 发送完成
+func Wait() {
+	running.Wait()
+}
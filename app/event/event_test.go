@@ -0,0 +1,114 @@
+package event
+
+import (
+	"go.uber.org/zap"
+	"message/logs"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPublishDoesNotWaitForSlowSubscribers(t *testing.T) {
+	logs.LogError = zap.NewNop().Sugar()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var slow, fast atomic.Int32
+	defer Subscribe("*", func(event Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		slow.Add(1)
+		return nil
+	})()
+	defer Subscribe("test.fast", func(event Event) error {
+		fast.Add(1)
+		return nil
+	})()
+
+	// 较慢的订阅正在执行时，其他订阅仍然处理事件
+	Publish(Event{Name: "test.slow"})
+	<-started
+	Publish(Event{Name: "test.fast"})
+	for deadline := time.Now().Add(5 * time.Second); fast.Load() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("fast subscriber waited for the slow one")
+		}
+	}
+
+	// 较慢的订阅的队列满后丢弃普通事件，Publish 不会阻塞，紧急事件不会被丢弃
+	const events = queueSize + 100
+	before := Dropped()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < events; i++ {
+			Publish(Event{Name: "test.slow"})
+		}
+		Publish(Event{Name: "test.slow", Urgent: true})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+	close(release)
+	Wait()
+
+	// 队列中已经有 test.fast 事件，只能再放入 queueSize-1 个普通事件
+	droppedCount := Dropped() - before
+	if droppedCount != events-queueSize+1 || slow.Load() != queueSize+2 {
+		t.Fatalf("slow subscriber handled %d and dropped %d, want %d and %d", slow.Load(), droppedCount, queueSize+2, events-queueSize+1)
+	}
+}
+
+func TestPublishOnlyMatchingSubscribers(t *testing.T) {
+	var actions, all atomic.Int32
+	defer Subscribe(MessageAction, func(event Event) error {
+		actions.Add(1)
+		return nil
+	})()
+	defer Subscribe("*", func(event Event) error {
+		all.Add(1)
+		return nil
+	})()
+
+	Publish(Event{Name: MessageCreated})
+	Publish(Event{Name: MessageAction})
+	Wait()
+
+	if actions.Load() != 1 || all.Load() != 2 {
+		t.Fatalf("actions = %d, all = %d, want 1 and 2", actions.Load(), all.Load())
+	}
+}
+
+func TestPublishHandlesUrgentEventsFirst(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var handled []string
+	defer Subscribe("test.urgent", func(event Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		handled = append(handled, event.MessageId)
+		return nil
+	})()
+
+	// 第一个事件执行期间发布的普通事件在队列中，之后发布的紧急事件先执行
+	Publish(Event{Name: "test.urgent", MessageId: "first"})
+	<-started
+	const events = 100
+	for i := 0; i < events; i++ {
+		Publish(Event{Name: "test.urgent", MessageId: "normal"})
+	}
+	Publish(Event{Name: "test.urgent", MessageId: "urgent", Urgent: true})
+	close(release)
+	Wait()
+
+	if len(handled) != events+2 || handled[1] != "urgent" {
+		t.Fatalf("handled %d events with %v first, want the urgent event right after the running one", len(handled), handled[:2])
+	}
+}
@@ -0,0 +1,151 @@
+package event
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"message/config"
+	"message/logs"
+	"message/utils"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// webhookAttempts 调用 Webhook 失败时的最多尝试次数
+const webhookAttempts = 3
+
+// webhookWorkers 同时发送 Webhook 的协程数量
+const webhookWorkers = 8
+
+// webhookBackoff 第一次重试前等待的时间，之后每次翻倍，并加上最多一半的随机抖动
+const webhookBackoff = time.Second
+
+// errWebhookRedirect Webhook 返回重定向时的错误，重定向的地址没有经过注册时的校验，所以不跟随
+var errWebhookRedirect = errors.New("webhook redirect refused")
+
+// WebhookResolver 根据发送者查询注册的 Webhook 地址和签名密钥，没有注册时返回 false
+type WebhookResolver func(senderId string) (url string, secret string, ok bool)
+
+// InitWebhook 订阅接收者点击操作按钮的事件，并将事件发送到消息的每个发送者注册的 Webhook
+//
+// event 包不能依赖 repository，所以由调用方传入查询 Webhook 的函数。
+func InitWebhook(resolve WebhookResolver) {
+	timeout := time.Duration(config.AppConfig.App.Webhook.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	client := newWebhookClient(timeout)
+
+	// Webhook 使用自己的队列和协程发送，较慢的 Webhook 不会影响其他订阅
+	deliveries := newQueue("webhook", queueSize)
+	deliver := func(event Event) error {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, senderId := range event.SenderIds {
+			url, secret, ok := resolve(senderId)
+			if !ok {
+				continue
+			}
+			if err := deliverWebhook(client, url, secret, event, body); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		go deliveries.run(deliver)
+	}
+
+	Subscribe(MessageAction, func(event Event) error {
+		deliveries.push(event)
+		return nil
+	})
+	logs.LogInfo.Infof("Webhook-启动")
+}
+
+// newWebhookClient 创建调用 Webhook 的 http.Client
+//
+// 连接前检查解析后的地址，拒绝回环、私有、链路本地等地址，避免通过 Webhook 访问内网服务；
+// 不使用环境变量中的代理，也不跟随重定向。
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: checkWebhookAddress}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return errWebhookRedirect
+		},
+	}
+}
+
+// checkWebhookAddress 在建立连接前检查 Webhook 的地址，DNS 解析后的每个地址都会检查
+func checkWebhookAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !utils.IsPublicAddr(addr) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+// deliverWebhook 将事件发送到一个 Webhook，失败时按照指数退避重试
+func deliverWebhook(client *http.Client, url string, secret string, event Event, body []byte) error {
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		err := sendWebhook(client, url, secret, event.Name, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		logs.LogInfo.Infof("Webhook-重试 %s %s %d %s", event.Name, event.MessageId, attempt, err)
+		time.Sleep(backoff + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		backoff *= 2
+	}
+}
+
+// sendWebhook 发送一次 Webhook 请求，设置了密钥时使用 HMAC-SHA256 对请求体签名
+func sendWebhook(client *http.Client, url string, secret string, name string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Message-Event", name)
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Message-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package event
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	// httptest 监听回环地址，连接前就被拒绝
+	client := newWebhookClient(time.Second)
+	if err := sendWebhook(client, server.URL, "", MessageAction, []byte("{}")); err == nil {
+		t.Fatal("sendWebhook to a loopback address succeeded, want an error")
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("loopback server received %d requests, want 0", calls.Load())
+	}
+
+	for address, public := range map[string]bool{
+		"93.184.216.34:443":       true,
+		"[2606:2800:220:1::]:443": true,
+		"127.0.0.1:80":            false,
+		"10.1.2.3:80":             false,
+		"172.16.0.1:80":           false,
+		"192.168.1.1:80":          false,
+		"169.254.169.254:80":      false,
+		"100.64.0.1:80":           false,
+		"0.0.0.0:80":              false,
+		"[::1]:80":                false,
+		"[fe80::1]:80":            false,
+		"[fd00::1]:80":            false,
+		"[::ffff:127.0.0.1]:80":   false,
+		"[64:ff9b::a00:1]:80":     false,
+		"224.0.0.1:80":            false,
+		"255.255.255.255:80":      false,
+	} {
+		if err := checkWebhookAddress("tcp", address, nil); (err == nil) != public {
+			t.Errorf("checkWebhookAddress(%s) = %v, want public %t", address, err, public)
+		}
+	}
+}
+
+func TestWebhookClientRefusesRedirects(t *testing.T) {
+	client := newWebhookClient(time.Second)
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/webhook", nil)
+	if err := client.CheckRedirect(req, []*http.Request{req}); !errors.Is(err, errWebhookRedirect) {
+		t.Fatalf("CheckRedirect = %v, want errWebhookRedirect", err)
+	}
+}
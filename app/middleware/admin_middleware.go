@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"message/app/response"
+	"message/config"
+	"message/logs"
+	"net/http"
+)
+
+// AdminToken 管理员在上下文中的凭证，避免在日志中输出管理员的真实凭证
+const AdminToken = "admin"
+
+// adminActor 返回请求凭证对应的管理员在上下文中的凭证，使用 operators 中的凭证时为 admin:名称，
+// 不是管理员凭证时返回空字符串
+func adminActor(token string) string {
+	actor := ""
+	adminToken := config.AppConfig.App.Admin.Token
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+		actor = AdminToken
+	}
+	// 比较所有管理员的凭证，比较的时间不取决于匹配的位置
+	for _, operator := range config.AppConfig.App.Admin.Operators {
+		if operator.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(operator.Token)) == 1 {
+			actor = AdminToken + ":" + operator.Name
+		}
+	}
+	return actor
+}
+
+// AdminAuthMiddleware 是一个 Gin 中间件函数，用于验证管理员的授权信息。
+//
+// 该中间件从请求头中获取 Authorization，并与配置文件中的管理员凭证以及每个管理员的凭证进行比较。
+//
+// 如果没有配置管理员凭证或者凭证不一致，则返回相应的错误响应。
+//
+// 否则，将 AdminToken 或者 admin:名称 设置到上下文中，审计日志据此记录操作的管理员，并继续处理后续请求。
+//
+// 返回一个 gin.HandlerFunc 处理程序函数。
+func AdminAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		actor := adminActor(ctx.GetHeader("Authorization"))
+
+		if actor == "" {
+			logs.LogInfo.Infof("AdminAuthMiddleware-失败 %s", ctx.ClientIP())
+			response.NewError(
+				ctx,
+				http.StatusUnauthorized,
+				response.ErrUnauthorized,
+			)
+			ctx.Abort()
+			return
+		}
+
+		logs.LogInfo.Infof("AdminAuthMiddleware-成功 %s %s", actor, ctx.ClientIP())
+		// 将管理员凭证设置到上下文中
+		ctx.Set("token", actor)
+		ctx.Next()
+	}
+}
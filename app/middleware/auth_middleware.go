@@ -1,8 +1,9 @@
 package middleware
 
 import (
-	lang "github.com/gin-contrib/i18n"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"message/app/repository"
 	"message/app/request"
 	"message/app/response"
@@ -16,7 +17,7 @@ import (
 //
 // 授权信息通过调用 repository.GetMessageToken() 方法获取消息令牌是否有效。
 //
-// 如果授权信息无效或获取消息令牌失败，则返回相应的错误响应。
+// 如果授权信息无效或消息令牌不存在，则返回 401；查询消息令牌失败时返回 503。
 //
 // 否则，将消息令牌设置到上下文中，并继续处理后续请求。
 //
@@ -26,18 +27,39 @@ func AuthMiddleware() gin.HandlerFunc {
 		token := ctx.GetHeader("Authorization")
 		if err := request.Validate.Var(token, "required,len=32"); err != nil {
 			logs.LogInfo.Infof("AuthMiddleware-失败 %s %s", err, ctx.ClientIP())
+			// /v1 接口凭证格式错误也是凭证错误，旧接口兼容返回参数校验错误
+			if response.Enveloped(ctx) {
+				response.NewError(
+					ctx,
+					http.StatusUnauthorized,
+					response.ErrUnauthorized,
+				)
+				ctx.Abort()
+				return
+			}
 			request.HandlingValidateErrors(ctx, err)
 			return
 		}
 
 		// 获取消息令牌
 		_, err := repository.GetMessageToken(token)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			// 查询凭证失败不是凭证错误，返回 503 让客户端稍后重试
+			logs.LogError.Errorf("AuthMiddleware-查询凭证失败 %s %s", err, ctx.ClientIP())
+			response.NewError(
+				ctx,
+				http.StatusServiceUnavailable,
+				response.ErrServiceUnavailable,
+			)
+			ctx.Abort()
+			return
+		}
 		if err != nil {
 			logs.LogInfo.Infof("AuthMiddleware-失败-找不到凭证 %s", ctx.ClientIP())
 			response.NewError(
 				ctx,
 				http.StatusUnauthorized,
-				lang.MustGetMessage(ctx, "unauthorized"),
+				response.ErrUnauthorized,
 			)
 			ctx.Abort()
 			return
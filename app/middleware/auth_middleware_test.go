@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"message/app/repository"
+	"message/config"
+	"message/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthMiddlewareRejectsUnknownAndRevokedTokens(t *testing.T) {
+	testutil.Setup(t)
+	r := testutil.NewRouter()
+	token := testutil.Token(t)
+
+	get := func(path string, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, path := range []string{"/message", "/v1/message"} {
+		if code := get(path, token); code != http.StatusOK {
+			t.Fatalf("GET %s with a valid token = %d, want 200", path, code)
+		}
+		if code := get(path, strings.Repeat("0", 32)); code != http.StatusUnauthorized {
+			t.Fatalf("GET %s with an unknown token = %d, want 401", path, code)
+		}
+	}
+
+	if revoked, err := repository.RevokeMessageToken(token); err != nil || revoked != 1 {
+		t.Fatalf("RevokeMessageToken = %d, %v", revoked, err)
+	}
+	for _, path := range []string{"/message", "/v1/message"} {
+		if code := get(path, token); code != http.StatusUnauthorized {
+			t.Fatalf("GET %s with a revoked token = %d, want 401", path, code)
+		}
+	}
+}
+
+func TestAuthMiddlewareReturnsUnavailableWhenTokenLookupFails(t *testing.T) {
+	db := testutil.Setup(t)
+	r := testutil.NewRouter()
+	token := testutil.Token(t)
+
+	// 查询凭证失败不是凭证错误，返回 503 而不是 401
+	if err := db.Migrator().DropTable(config.AppConfig.App.Verify.Table); err != nil {
+		t.Fatalf("drop verify table: %s", err)
+	}
+	for _, path := range []string{"/message", "/v1/message"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("GET %s when the token lookup fails = %d, want 503", path, w.Code)
+		}
+	}
+}
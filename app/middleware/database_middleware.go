@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/response"
+	"message/database"
+	"message/logs"
+	"net/http"
+)
+
+// DatabaseMiddleware 没有连接数据库时返回 503，避免后续的中间件和控制器使用空的数据库连接
+//
+// 启动时多次重试后仍然连接失败时在后台重新连接，连接成功前健康检查的 /readyz 同样返回 503。
+func DatabaseMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !database.Connected() {
+			logs.LogError.Errorf("DatabaseMiddleware-数据库未连接 %s %s", ctx.Request.URL.Path, ctx.ClientIP())
+			response.NewError(
+				ctx,
+				http.StatusServiceUnavailable,
+				response.ErrServiceUnavailable,
+			)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
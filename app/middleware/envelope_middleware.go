@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/response"
+)
+
+// EnvelopeMiddleware 标记请求使用统一的响应格式
+//
+// 后续的中间件和控制器通过 response.Enveloped 判断，返回 {data, error, meta} 格式的数据和稳定的错误码。
+func EnvelopeMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(response.EnvelopeKey, true)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	lang "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// I18nMiddleware 国际化中间件，根据 Accept-Language 选择 resources/lang 中的语言文件，默认为中文
+func I18nMiddleware() gin.HandlerFunc {
+	return lang.Localize(lang.WithBundle(&lang.BundleCfg{
+		DefaultLanguage:  language.Chinese,
+		FormatBundleFile: "yaml",
+		AcceptLanguage:   []language.Tag{language.Chinese, language.English},
+		RootPath:         "resources/lang",
+		UnmarshalFunc:    yaml.Unmarshal,
+	}))
+}
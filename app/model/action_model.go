@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// 定义操作按钮样式的常量
+const (
+	ActionStyleDefault = "default" // 默认样式
+	ActionStylePrimary = "primary" // 主要操作
+	ActionStyleDanger  = "danger"  // 危险操作
+)
+
+// MessageData 是一个自定义类型，表示消息附带的结构化数据。以 JSON 格式存储。
+type MessageData map[string]interface{}
+
+// Scan 实现了 sql.Scanner 接口，用于将数据库中的原始数据转换为 MessageData 类型。
+func (md *MessageData) Scan(src interface{}) error {
+	var source []byte
+	switch src := src.(type) {
+	case []byte:
+		source = src
+	case string:
+		source = []byte(src)
+	case nil:
+		*md = nil
+		return nil
+	default:
+		return errors.New("incompatible type for MessageData")
+	}
+
+	if len(source) == 0 {
+		*md = nil
+		return nil
+	}
+	return json.Unmarshal(source, md)
+}
+
+// Value 实现了 driver.Valuer 接口，用于将 MessageData 类型转换为数据库中的原始数据。
+func (md MessageData) Value() (driver.Value, error) {
+	if len(md) == 0 {
+		return "", nil
+	}
+	value, err := json.Marshal(md)
+	return string(value), err
+}
+
+// MessageAction 消息的操作按钮，Url 和 Callback 只能设置一个
+type MessageAction struct {
+	// Id 按钮 ID，同一条消息中唯一
+	Id string `json:"id"`
+	// Label 按钮的文字
+	Label string `json:"label"`
+	// Url 点击按钮后打开的链接
+	Url string `json:"url,omitempty"`
+	// Callback 点击按钮后通知发送者的回调 ID
+	Callback string `json:"callback,omitempty"`
+	// Style 按钮的样式
+	Style string `json:"style"`
+}
+
+// MessageActions 是一个自定义类型，表示消息的操作按钮集合。以 JSON 格式存储。
+type MessageActions []MessageAction
+
+// Scan 实现了 sql.Scanner 接口，用于将数据库中的原始数据转换为 MessageActions 类型。
+func (ma *MessageActions) Scan(src interface{}) error {
+	var source []byte
+	switch src := src.(type) {
+	case []byte:
+		source = src
+	case string:
+		source = []byte(src)
+	case nil:
+		*ma = MessageActions{}
+		return nil
+	default:
+		return errors.New("incompatible type for MessageActions")
+	}
+
+	if len(source) == 0 {
+		*ma = MessageActions{}
+		return nil
+	}
+	return json.Unmarshal(source, ma)
+}
+
+// Value 实现了 driver.Valuer 接口，用于将 MessageActions 类型转换为数据库中的原始数据。
+func (ma MessageActions) Value() (driver.Value, error) {
+	if len(ma) == 0 {
+		return "", nil
+	}
+	value, err := json.Marshal(ma)
+	return string(value), err
+}
+
+// Find 根据按钮 ID 查询操作按钮，找不到时返回 nil
+func (ma MessageActions) Find(id string) *MessageAction {
+	for i := range ma {
+		if ma[i].Id == id {
+			return &ma[i]
+		}
+	}
+	return nil
+}
+
+// MessageActionChoice 接收者点击的操作按钮，每个接收者只能选择一次
+type MessageActionChoice struct {
+	gorm.Model  `json:"-"`
+	MessageId   string `gorm:"type:varchar(32);uniqueIndex:idx_message_action_choice;not null;comment:消息id"`
+	RecipientId string `gorm:"type:varchar(32);uniqueIndex:idx_message_action_choice;not null;comment:接收者的ID"`
+	ActionId    string `gorm:"type:varchar(32);not null;comment:操作按钮的ID"`
+}
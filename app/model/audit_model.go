@@ -0,0 +1,73 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// 定义审计操作的常量
+const (
+	AuditCreate  = "create"  // 创建消息
+	AuditUpdate  = "update"  // 更新消息
+	AuditStatus  = "status"  // 更新消息状态
+	AuditDelete  = "delete"  // 删除消息
+	AuditRestore = "restore" // 恢复消息
+	AuditHide    = "hide"    // 接收者删除自己的消息
+	AuditRetract = "retract" // 发送者撤回消息
+	AuditFlag    = "flag"    // 接收者置顶或标星消息
+	AuditTag     = "tag"     // 添加或移除消息的标签
+	AuditAction  = "action"  // 接收者点击消息的操作按钮
+	AuditImport  = "import"  // 管理员导入消息
+)
+
+// AuditChange 字段修改前后的数据
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges 是一个自定义类型，表示每个字段修改前后的数据。以 JSON 格式存储。
+type AuditChanges map[string]AuditChange
+
+// Scan 实现了 sql.Scanner 接口，用于将数据库中的原始数据转换为 AuditChanges 类型。
+func (ac *AuditChanges) Scan(src interface{}) error {
+	var source []byte
+	switch src := src.(type) {
+	case []byte:
+		source = src
+	case string:
+		source = []byte(src)
+	case nil:
+		*ac = nil
+		return nil
+	default:
+		return errors.New("incompatible type for AuditChanges")
+	}
+
+	if len(source) == 0 {
+		*ac = nil
+		return nil
+	}
+	return json.Unmarshal(source, ac)
+}
+
+// Value 实现了 driver.Valuer 接口，用于将 AuditChanges 类型转换为数据库中的原始数据。
+func (ac AuditChanges) Value() (driver.Value, error) {
+	if len(ac) == 0 {
+		return "", nil
+	}
+	value, err := json.Marshal(ac)
+	return string(value), err
+}
+
+// Audit 审计日志，记录对消息的修改操作
+type Audit struct {
+	gorm.Model `json:"-"`
+	Actor      string       `gorm:"type:varchar(32);index;not null;comment:操作者的凭证"`
+	Ip         string       `gorm:"type:varchar(64);comment:操作者的IP"`
+	Action     string       `gorm:"type:varchar(32);index;not null;comment:操作类型"`
+	MessageId  string       `gorm:"type:varchar(32);index;comment:消息id"`
+	Changes    AuditChanges `gorm:"type:text;comment:修改前后的数据"`
+}
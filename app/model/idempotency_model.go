@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// MessageIdempotency 创建消息时使用的幂等键，同一个发送者在有效期内使用同一个键只创建一条消息
+type MessageIdempotency struct {
+	ID             uint      `gorm:"primarykey"`
+	SenderId       string    `gorm:"type:varchar(32);uniqueIndex:idx_message_idempotency;not null;comment:发送者的ID"`
+	IdempotencyKey string    `gorm:"type:varchar(255);uniqueIndex:idx_message_idempotency;not null;comment:幂等键"`
+	RequestHash    string    `gorm:"type:varchar(64);not null;comment:请求内容的哈希"`
+	MessageId      string    `gorm:"type:varchar(32);not null;comment:创建的消息id"`
+	CreatedAt      time.Time `gorm:"comment:创建时间"`
+	ExpiresAt      time.Time `gorm:"index;comment:过期时间"`
+}
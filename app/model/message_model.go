@@ -2,6 +2,7 @@ package model
 
 import (
 	"gorm.io/gorm"
+	"time"
 )
 
 // 定义消息状态的常量
@@ -14,15 +15,49 @@ const (
 // Message 消息
 type Message struct {
 	gorm.Model    `json:"-"`
-	MessageId     string      `gorm:"type:varchar(32);index;unique;not null;comment:消息id"`
-	SenderIds     StringArray `gorm:"type:text;comment:发送者的ID集合"`
-	Title         string      `gorm:"type:varchar(25);not null;comment:消息标题"`
-	Content       string      `gorm:"type:varchar(50);not null;comment:消息内容"`
-	Category      string      `gorm:"type:varchar(50);index;not null;comment:消息类别"`
-	BigContent    string      `gorm:"type:longtext;not null;comment:消息的详细内容"`
-	IntroducerIds StringArray `gorm:"type:text;comment:接收者的ID集合"`
-	Status        uint8       `gorm:"type:tinyint;default:0;comment:消息阅读状态"`
+	MessageId     string         `gorm:"type:varchar(32);index;unique;not null;comment:消息id"`
+	SenderIds     StringArray    `gorm:"type:text;comment:发送者的ID集合"`
+	Title         string         `gorm:"type:varchar(25);not null;comment:消息标题"`
+	Content       string         `gorm:"type:varchar(50);not null;comment:消息内容"`
+	Category      string         `gorm:"type:varchar(50);index;not null;comment:消息类别"`
+	BigContent    string         `gorm:"type:longtext;not null;comment:消息的详细内容"`
+	ContentType   string         `gorm:"type:varchar(16);default:text;not null;comment:详细内容的格式"`
+	IntroducerIds StringArray    `gorm:"type:text;comment:接收者的ID集合"`
+	Data          MessageData    `gorm:"type:text;comment:消息附带的结构化数据"`
+	Actions       MessageActions `gorm:"type:text;comment:消息的操作按钮"`
+	Status        uint8          `gorm:"type:tinyint;default:0;comment:消息阅读状态"`
+	Priority      Priority       `gorm:"type:tinyint;default:2;index;comment:消息优先级"`
+	Version       uint           `gorm:"default:1;not null;comment:消息版本"`
+	Edited        bool           `gorm:"default:false;comment:消息是否被编辑过"`
 }
 
 type MessageCategory struct {
 }
+
+// MessageRecipient 消息接收者的状态，每个接收者单独记录一条
+type MessageRecipient struct {
+	gorm.Model  `json:"-"`
+	MessageId   string `gorm:"type:varchar(32);uniqueIndex:idx_message_recipient;not null;comment:消息id"`
+	RecipientId string `gorm:"type:varchar(32);uniqueIndex:idx_message_recipient;not null;comment:接收者的ID"`
+	Status      uint8  `gorm:"type:tinyint;default:0;comment:消息阅读状态"`
+	Hidden      bool   `gorm:"default:false;comment:接收者是否删除了消息"`
+	Pinned      bool   `gorm:"default:false;comment:接收者是否置顶了消息"`
+	Starred     bool   `gorm:"default:false;comment:接收者是否标星了消息"`
+}
+
+// MessageVersion 消息的历史版本，消息每次更新前保存一份
+type MessageVersion struct {
+	gorm.Model    `json:"-"`
+	MessageId     string         `gorm:"type:varchar(32);uniqueIndex:idx_message_version;not null;comment:消息id"`
+	Version       uint           `gorm:"uniqueIndex:idx_message_version;not null;comment:消息版本"`
+	Title         string         `gorm:"type:varchar(25);not null;comment:消息标题"`
+	Content       string         `gorm:"type:varchar(50);not null;comment:消息内容"`
+	Category      string         `gorm:"type:varchar(50);not null;comment:消息类别"`
+	BigContent    string         `gorm:"type:longtext;not null;comment:消息的详细内容"`
+	ContentType   string         `gorm:"type:varchar(16);default:text;not null;comment:详细内容的格式"`
+	IntroducerIds StringArray    `gorm:"type:text;comment:接收者的ID集合"`
+	Data          MessageData    `gorm:"type:text;comment:消息附带的结构化数据"`
+	Actions       MessageActions `gorm:"type:text;comment:消息的操作按钮"`
+	Priority      Priority       `gorm:"type:tinyint;default:2;comment:消息优先级"`
+	EditedAt      time.Time      `gorm:"comment:该版本的修改时间"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Priority 消息优先级，数据库中保存为数值，接口中使用名称（low/normal/high/urgent）
+type Priority uint8
+
+// 定义消息优先级的常量，数值越大优先级越高，0 表示没有设置优先级
+const (
+	PriorityLow    Priority = iota + 1 // 低优先级
+	PriorityNormal                     // 普通优先级
+	PriorityHigh                       // 高优先级
+	PriorityUrgent                     // 紧急
+)
+
+// PriorityNames 所有优先级的名称，按照优先级从低到高排列
+var PriorityNames = []string{"low", "normal", "high", "urgent"}
+
+// ParsePriority 根据名称返回优先级，名称无效时返回 false
+func ParsePriority(name string) (Priority, bool) {
+	for i, priorityName := range PriorityNames {
+		if priorityName == name {
+			return Priority(i + 1), true
+		}
+	}
+	return 0, false
+}
+
+// String 返回优先级的名称，没有设置或者无效的优先级返回空字符串
+func (p Priority) String() string {
+	if p < PriorityLow || p > PriorityUrgent {
+		return ""
+	}
+	return PriorityNames[p-1]
+}
+
+// MarshalJSON 实现了 json.Marshaler 接口，将优先级转换为名称
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON 实现了 json.Unmarshaler 接口，将名称转换为优先级，空字符串表示没有设置
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	if name == "" {
+		*p = 0
+		return nil
+	}
+	priority, ok := ParsePriority(name)
+	if !ok {
+		return fmt.Errorf("invalid priority %q", name)
+	}
+	*p = priority
+	return nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Tag 消息标签，OwnerId 为空时是发送者设置的标签，否则是接收者自己的标签
+type Tag struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"type:varchar(32);uniqueIndex:idx_tag_owner;not null;comment:标签名称"`
+	OwnerId   string    `gorm:"type:varchar(32);uniqueIndex:idx_tag_owner;not null;default:'';comment:标签所有者的ID，为空时是发送者设置的标签"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+}
+
+// MessageTag 消息和标签的多对多关系，移除标签时直接删除记录
+type MessageTag struct {
+	ID        uint      `gorm:"primarykey"`
+	MessageId string    `gorm:"type:varchar(32);uniqueIndex:idx_message_tag;not null;comment:消息id"`
+	TagId     uint      `gorm:"uniqueIndex:idx_message_tag;index;not null;comment:标签id"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+}
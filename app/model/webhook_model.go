@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Webhook 发送者注册的 Webhook，接收者点击发送者消息的操作按钮时 POST 事件到 Url
+type Webhook struct {
+	ID        uint      `gorm:"primarykey"`
+	SenderId  string    `gorm:"type:varchar(32);uniqueIndex;not null;comment:发送者的ID"`
+	Url       string    `gorm:"type:varchar(2048);not null;comment:Webhook 地址"`
+	Secret    string    `gorm:"type:varchar(255);not null;default:'';comment:签名密钥，为空时不签名"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+	UpdatedAt time.Time `gorm:"comment:更新时间"`
+}
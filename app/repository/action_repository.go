@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/database"
+)
+
+// ErrMessageActionNotFound 表示消息没有该操作按钮或者 token 不是消息的接收者
+var ErrMessageActionNotFound = errors.New("message action not found")
+
+// ErrMessageActionChosen 表示接收者已经点击了其他操作按钮
+var ErrMessageActionChosen = errors.New("another message action has been chosen")
+
+// newMessageActions 根据请求创建消息的操作按钮，没有指定样式时使用默认样式
+func newMessageActions(actionRequests []request.MessageActionRequest) model.MessageActions {
+	if actionRequests == nil {
+		return nil
+	}
+
+	actions := make(model.MessageActions, 0, len(actionRequests))
+	for _, actionRequest := range actionRequests {
+		action := model.MessageAction{
+			Id:       actionRequest.Id,
+			Label:    actionRequest.Label,
+			Url:      actionRequest.Url,
+			Callback: actionRequest.Callback,
+			Style:    actionRequest.Style,
+		}
+		if action.Style == "" {
+			action.Style = model.ActionStyleDefault
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// ChooseMessageAction 记录接收者点击的操作按钮，返回点击记录以及是否是第一次点击
+//
+// 每个接收者只能选择一个操作按钮，重复点击同一个按钮时返回之前的记录，点击其他按钮时返回 ErrMessageActionChosen。
+func ChooseMessageAction(
+	// 消息凭证
+	token string,
+	// 接收者可以看到的消息
+	message *model.Message,
+	// 操作按钮 ID
+	actionId string,
+	// 审计日志的操作者
+	auditor *Auditor,
+) (*response.MessageActionResponse, bool, error) {
+	action := message.Actions.Find(actionId)
+	if action == nil || !isMessageRecipient(message, token) {
+		return nil, false, ErrMessageActionNotFound
+	}
+
+	// 第一次点击时在同一个事务中记录审计日志
+	created := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.MessageActionChoice{
+			MessageId:   message.MessageId,
+			RecipientId: token,
+			ActionId:    actionId,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		created = true
+		return auditor.record(tx, model.AuditAction, []string{message.MessageId}, model.AuditChanges{
+			"action": {After: actionId},
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 查询保存后的记录，已经点击过时是之前的记录
+	choice := &model.MessageActionChoice{}
+	err = database.DB.Where("message_id = ?", message.MessageId).
+		Where("recipient_id = ?", token).
+		First(choice).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	chosen := &response.MessageActionResponse{
+		MessageId: choice.MessageId,
+		ActionId:  choice.ActionId,
+		ChosenAt:  choice.CreatedAt,
+	}
+	if chosenAction := message.Actions.Find(choice.ActionId); chosenAction != nil {
+		chosen.Callback = chosenAction.Callback
+	}
+	if choice.ActionId != actionId {
+		return chosen, false, ErrMessageActionChosen
+	}
+	return chosen, created, nil
+}
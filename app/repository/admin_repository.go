@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/database"
+	"slices"
+)
+
+// QueryMessagesByAdminMessageRequest 根据管理员的消息请求查询所有消息，不限制发送者和接收者
+func QueryMessagesByAdminMessageRequest(
+	// 管理员的消息请求参数
+	adminRequest *request.AdminMessageRequest,
+	// 消息过滤器
+	filters []request.MessageFilterRequest,
+) []response.AdminMessage {
+	var messages []response.AdminMessage
+	// 创建消息查询对象
+	query := database.DB.Model(&model.Message{})
+
+	// 查询已软删除的消息
+	if adminRequest.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	// 根据过滤、排序和分页信息查询消息并存储在 messages 中
+	findMessagesByMessageRequest(query, "", &adminRequest.MessageRequest, filters, &messages)
+
+	// 将详细内容渲染为请求的格式
+	for i := range messages {
+		messages[i].Render(adminRequest.Format)
+	}
+
+	// 返回查询到的消息数组
+	return messages
+}
+
+// AdminDeleteMessagesById 根据消息 ID 批量撤回消息，不限制发送者
+func AdminDeleteMessagesById(
+	// 要删除的消息请求切片
+	deleteRequests *[]request.MessageDeleteRequest,
+	// 为 true 时任意一条消息删除失败则全部回滚
+	atomic bool,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageDeleteResponse {
+	return deleteMessagesById(deleteRequests, atomic, model.AuditDelete, auditor, func(message *model.Message) bool {
+		return true
+	})
+}
+
+// AdminRestoreMessagesById 根据消息 ID 批量恢复已软删除的消息，不限制发送者
+func AdminRestoreMessagesById(
+	// 要恢复的消息请求切片
+	restoreRequests *[]request.MessageRestoreRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageRestoreResponse {
+	return restoreMessagesById(restoreRequests, auditor)
+}
+
+// QueryMessageRecipientStatus 查询消息每个接收者的状态，找不到消息时返回 nil
+func QueryMessageRecipientStatus(
+	// 消息 ID
+	messageId string,
+) []response.MessageRecipientStatusResponse {
+	// 查询消息，包括已软删除的消息
+	message := &model.Message{}
+	result := database.DB.Unscoped().
+		Where("message_id = ?", messageId).
+		First(message)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+
+	// 查询已记录的接收者状态
+	var recipients []model.MessageRecipient
+	database.DB.Where("message_id = ?", messageId).Find(&recipients)
+
+	results := make([]response.MessageRecipientStatusResponse, 0)
+	// 消息的接收者没有记录状态时为未读状态
+	for _, recipientId := range message.IntroducerIds {
+		if recipientId == "" {
+			continue
+		}
+		status := response.MessageRecipientStatusResponse{
+			RecipientId: recipientId,
+			Status:      model.Unread,
+		}
+		index := slices.IndexFunc(recipients, func(recipient model.MessageRecipient) bool {
+			return recipient.RecipientId == recipientId
+		})
+		if index != -1 {
+			status.Status = recipients[index].Status
+			status.UpdatedAt = &recipients[index].UpdatedAt
+		}
+		results = append(results, status)
+	}
+
+	// 不在接收者集合中的记录（例如发给所有人的消息）
+	for i, recipient := range recipients {
+		if slices.Contains(message.IntroducerIds, recipient.RecipientId) {
+			continue
+		}
+		results = append(results, response.MessageRecipientStatusResponse{
+			RecipientId: recipient.RecipientId,
+			Status:      recipient.Status,
+			UpdatedAt:   &recipients[i].UpdatedAt,
+		})
+	}
+
+	// 返回每个接收者的状态
+	return results
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/config"
+	"message/database"
+	"slices"
+)
+
+// Auditor 审计日志的操作者，修改消息的方法在同一个事务中记录审计日志，审计日志写入失败时修改也会回滚
+type Auditor struct {
+	// 操作者的凭证，管理员为 admin 或者 admin:名称
+	Actor string
+	// 操作者的IP
+	Ip string
+}
+
+// record 在事务中为每条消息记录一条审计日志，auditor 为 nil 时不记录
+func (auditor *Auditor) record(
+	// 修改消息的事务
+	tx *gorm.DB,
+	// 操作类型
+	action string,
+	// 操作成功的消息 ID
+	messageIds []string,
+	// 修改前后的数据，只有一条消息时记录
+	changes model.AuditChanges,
+) error {
+	if auditor == nil || len(messageIds) == 0 {
+		return nil
+	}
+
+	audits := make([]model.Audit, 0, len(messageIds))
+	for _, messageId := range messageIds {
+		audit := model.Audit{
+			Actor:     auditor.Actor,
+			Ip:        auditor.Ip,
+			Action:    action,
+			MessageId: messageId,
+		}
+		if len(messageIds) == 1 {
+			audit.Changes = changes
+		}
+		audits = append(audits, audit)
+	}
+	return tx.CreateInBatches(&audits, messageBatchSize).Error
+}
+
+// diffMessage 比较消息更新前后修改的字段
+func diffMessage(before *model.Message, after *model.Message) model.AuditChanges {
+	changes := model.AuditChanges{}
+	if before.Title != after.Title {
+		changes["title"] = model.AuditChange{Before: before.Title, After: after.Title}
+	}
+	if before.Content != after.Content {
+		changes["content"] = model.AuditChange{Before: before.Content, After: after.Content}
+	}
+	if before.Category != after.Category {
+		changes["category"] = model.AuditChange{Before: before.Category, After: after.Category}
+	}
+	if before.BigContent != after.BigContent {
+		changes["big_content"] = model.AuditChange{Before: before.BigContent, After: after.BigContent}
+	}
+	if before.ContentType != after.ContentType {
+		changes["content_type"] = model.AuditChange{Before: before.ContentType, After: after.ContentType}
+	}
+	if before.Priority != after.Priority {
+		changes["priority"] = model.AuditChange{Before: before.Priority, After: after.Priority}
+	}
+	if !slices.Equal(before.IntroducerIds, after.IntroducerIds) {
+		changes["introducer_ids"] = model.AuditChange{Before: before.IntroducerIds, After: after.IntroducerIds}
+	}
+	// 结构化数据和操作按钮按照保存到数据库中的 JSON 比较
+	beforeData, _ := before.Data.Value()
+	afterData, _ := after.Data.Value()
+	if beforeData != afterData {
+		changes["data"] = model.AuditChange{Before: before.Data, After: after.Data}
+	}
+	beforeActions, _ := before.Actions.Value()
+	afterActions, _ := after.Actions.Value()
+	if beforeActions != afterActions {
+		changes["actions"] = model.AuditChange{Before: before.Actions, After: after.Actions}
+	}
+	return changes
+}
+
+// auditQuery 根据审计日志请求创建查询对象
+func auditQuery(auditRequest *request.AuditRequest) *gorm.DB {
+	query := database.DB.Model(&model.Audit{})
+	if auditRequest.Actor != "" {
+		query = query.Where("actor = ?", auditRequest.Actor)
+	}
+	if auditRequest.Action != "" {
+		query = query.Where("action = ?", auditRequest.Action)
+	}
+	if auditRequest.MessageId != "" {
+		query = query.Where("message_id = ?", auditRequest.MessageId)
+	}
+	if !auditRequest.From.IsZero() {
+		query = query.Where("created_at >= ?", auditRequest.From)
+	}
+	if !auditRequest.To.IsZero() {
+		query = query.Where("created_at < ?", auditRequest.To)
+	}
+	return query.Order("id desc")
+}
+
+// QueryAudits 根据审计日志请求分页查询审计日志
+func QueryAudits(auditRequest *request.AuditRequest) []response.Audit {
+	audits := make([]response.Audit, 0)
+
+	maxLimit := config.AppConfig.API.MaxLimit
+	if auditRequest.Page == 0 {
+		auditRequest.Page = 1
+	}
+	auditQuery(auditRequest).
+		Limit(maxLimit).
+		Offset((auditRequest.Page - 1) * maxLimit).
+		Find(&audits)
+
+	return audits
+}
+
+// ExportAudits 根据审计日志请求逐条读取审计日志，每读取一条调用一次 write
+func ExportAudits(
+	// 审计日志请求参数
+	auditRequest *request.AuditRequest,
+	// 处理每条审计日志的函数
+	write func(audit *response.Audit) error,
+) error {
+	rows, err := auditQuery(auditRequest).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		audit := &response.Audit{}
+		if err := database.DB.ScanRows(rows, audit); err != nil {
+			return err
+		}
+		if err := write(audit); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -6,21 +6,38 @@ import (
 	"message/database"
 )
 
-// GetMessageToken 尝试获取一个符合指定条件的MessageToken。
+// GetMessageToken 查询验证表中是否存在消息凭证
 //
-// 它返回找到的MessageToken和可能出现的错误。如果记录不存在，将返回nil和gorm.ErrRecordNotFound。
+// 凭证存在时返回 true，不存在时返回 false 和 gorm.ErrRecordNotFound，查询失败时返回数据库的错误。
 func GetMessageToken(messageToken string) (bool, error) {
 	verify := config.AppConfig.App.Verify
-	result := database.DB.Table(verify.Table).
+	row := map[string]interface{}{}
+	err := database.DB.Table(verify.Table).
 		Select(verify.Column).
 		Where(fmt.Sprintf("%s = ?", verify.Column), messageToken).
-		Limit(1)
-
-	if result.Error != nil {
+		Take(&row).Error
+	if err != nil {
 		// 直接返回错误，包括未找到记录的情况
-		return false, result.Error
+		return false, err
 	}
 
-	// 记录被成功找到，返回token的指针和nil作为错误
+	// 记录被成功找到
 	return true, nil
 }
+
+// CreateMessageToken 在验证表中添加消息凭证
+func CreateMessageToken(messageToken string) error {
+	verify := config.AppConfig.App.Verify
+	return database.DB.Table(verify.Table).
+		Create(map[string]interface{}{verify.Column: messageToken}).
+		Error
+}
+
+// RevokeMessageToken 从验证表中删除消息凭证，返回删除的数量
+func RevokeMessageToken(messageToken string) (int64, error) {
+	verify := config.AppConfig.App.Verify
+	result := database.DB.Table(verify.Table).
+		Where(fmt.Sprintf("%s = ?", verify.Column), messageToken).
+		Delete(map[string]interface{}{})
+	return result.RowsAffected, result.Error
+}
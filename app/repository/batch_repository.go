@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/database"
+	"message/logs"
+)
+
+// messageBatchSize 批量创建消息时每条 INSERT 语句插入的消息数量
+const messageBatchSize = 100
+
+// CreateMessagesInBatches 批量创建消息，返回每条消息的结果
+//
+// atomic 为 true 时任意一条消息校验或创建失败则全部不创建；否则跳过校验失败的消息，
+// 某一批插入失败时逐条插入该批消息，找出失败的消息。
+func CreateMessagesInBatches(
+	// 消息凭证
+	token string,
+	// 批量创建的消息
+	items *[]request.MessageBatchItem,
+	// 是否全部成功或全部失败
+	atomic bool,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageBatchResponse {
+	results := make([]response.MessageBatchResponse, len(*items))
+
+	// 生成校验通过的消息，indexes 记录每条消息在请求中的位置
+	var messages []*model.Message
+	var indexes []int
+	invalid := false
+	for i := range *items {
+		item := &(*items)[i]
+		results[i].Index = i
+		if len(item.Errors) > 0 {
+			results[i].Result = response.MessageBatchInvalid
+			results[i].Errors = item.Errors
+			invalid = true
+			continue
+		}
+		messages = append(messages, buildMessage(token, &item.Message))
+		indexes = append(indexes, i)
+	}
+
+	if atomic {
+		var err error
+		if !invalid {
+			err = database.DB.Transaction(func(tx *gorm.DB) error {
+				return createMessages(tx, messages, auditor)
+			})
+			if err != nil {
+				logs.LogError.Errorf("CreateMessagesInBatches %s %s", token, err)
+			}
+		}
+		for j, i := range indexes {
+			switch {
+			case invalid:
+				results[i].Result = response.MessageBatchRolledBack
+			case err != nil:
+				results[i].Result = response.MessageBatchFailed
+			default:
+				results[i].MessageId = messages[j].MessageId
+				results[i].Result = response.MessageBatchCreated
+			}
+		}
+		publishMessagesCreated(token, createdMessageIds(results))
+		return results
+	}
+
+	for start := 0; start < len(messages); start += messageBatchSize {
+		end := min(start+messageBatchSize, len(messages))
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return createMessages(tx, messages[start:end], auditor)
+		})
+		for j := start; j < end; j++ {
+			// 整批插入失败时逐条插入
+			if err != nil {
+				createErr := database.DB.Transaction(func(tx *gorm.DB) error {
+					return createMessages(tx, messages[j:j+1], auditor)
+				})
+				if createErr != nil {
+					logs.LogError.Errorf("CreateMessagesInBatches %s %d %s", token, indexes[j], createErr)
+					results[indexes[j]].Result = response.MessageBatchFailed
+					continue
+				}
+			}
+			results[indexes[j]].MessageId = messages[j].MessageId
+			results[indexes[j]].Result = response.MessageBatchCreated
+		}
+	}
+	publishMessagesCreated(token, createdMessageIds(results))
+	return results
+}
+
+// createMessages 在事务中插入消息，并为每条消息记录审计日志
+func createMessages(tx *gorm.DB, messages []*model.Message, auditor *Auditor) error {
+	if err := tx.CreateInBatches(messages, messageBatchSize).Error; err != nil {
+		return err
+	}
+	messageIds := make([]string, 0, len(messages))
+	for _, message := range messages {
+		messageIds = append(messageIds, message.MessageId)
+	}
+	return auditor.record(tx, model.AuditCreate, messageIds, nil)
+}
+
+// createdMessageIds 返回批量创建成功的消息 ID
+func createdMessageIds(results []response.MessageBatchResponse) []string {
+	var messageIds []string
+	for _, result := range results {
+		if result.Result == response.MessageBatchCreated {
+			messageIds = append(messageIds, result.MessageId)
+		}
+	}
+	return messageIds
+}
@@ -0,0 +1,139 @@
+package repository_test
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/testutil"
+	"testing"
+)
+
+// batchItem 返回批量创建的一条消息，invalid 为 true 时带有校验错误
+func batchItem(title string, invalid bool) request.MessageBatchItem {
+	item := request.MessageBatchItem{
+		Message: request.MessageCreateUpdateRequest{
+			Title:         title,
+			Content:       "content",
+			Category:      "notice",
+			BigContent:    "big content",
+			IntroducerIds: []string{"recipient"},
+		},
+	}
+	if invalid {
+		item.Errors = []request.ValidationError{{Field: "title", Message: "invalid"}}
+	}
+	return item
+}
+
+// failInsert 插入标题为 fail 的消息时数据库返回错误
+func failInsert(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Exec(`CREATE TRIGGER message_fail BEFORE INSERT ON message WHEN new.title = 'fail' BEGIN
+		SELECT RAISE(ABORT, 'fail');
+	END`).Error
+	if err != nil {
+		t.Fatalf("create trigger: %s", err)
+	}
+}
+
+// batchResults 返回批量创建每条消息的结果，创建成功的消息必须有消息 ID
+func batchResults(t *testing.T, results []response.MessageBatchResponse) []string {
+	t.Helper()
+	var got []string
+	for i, result := range results {
+		if result.Index != i || (result.Result == response.MessageBatchCreated) != (result.MessageId != "") {
+			t.Fatalf("result %d = %+v", i, result)
+		}
+		got = append(got, result.Result)
+	}
+	return got
+}
+
+// messageCount 返回数据库中的消息数量
+func messageCount(db *gorm.DB) int64 {
+	var count int64
+	db.Model(&model.Message{}).Count(&count)
+	return count
+}
+
+// auditCount 返回数据库中的审计日志数量
+func auditCount(db *gorm.DB) int64 {
+	var count int64
+	db.Model(&model.Audit{}).Count(&count)
+	return count
+}
+
+func TestCreateMessagesInBatchesBestEffort(t *testing.T) {
+	db := testutil.Setup(t)
+	failInsert(t, db)
+
+	// 跳过校验失败的消息，整批插入失败后逐条插入，只有出错的消息失败
+	items := []request.MessageBatchItem{
+		batchItem("first", false),
+		batchItem("invalid", true),
+		batchItem("fail", false),
+		batchItem("last", false),
+	}
+	results := repository.CreateMessagesInBatches("sender", &items, false, auditor)
+	want := []string{
+		response.MessageBatchCreated,
+		response.MessageBatchInvalid,
+		response.MessageBatchFailed,
+		response.MessageBatchCreated,
+	}
+	if got := batchResults(t, results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	if results[1].Errors == nil {
+		t.Fatalf("invalid result has no validation errors")
+	}
+	if count := messageCount(db); count != 2 {
+		t.Fatalf("%d messages created, want 2", count)
+	}
+	if count := auditCount(db); count != 2 {
+		t.Fatalf("%d audits recorded, want 2", count)
+	}
+}
+
+func TestCreateMessagesInBatchesAtomic(t *testing.T) {
+	db := testutil.Setup(t)
+	failInsert(t, db)
+
+	// 有校验失败的消息时不创建任何消息
+	items := []request.MessageBatchItem{batchItem("first", false), batchItem("invalid", true)}
+	results := repository.CreateMessagesInBatches("sender", &items, true, auditor)
+	want := []string{response.MessageBatchRolledBack, response.MessageBatchInvalid}
+	if got := batchResults(t, results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("invalid batch results = %v, want %v", got, want)
+	}
+
+	// 插入出错时整批回滚
+	items = []request.MessageBatchItem{batchItem("first", false), batchItem("fail", false)}
+	results = repository.CreateMessagesInBatches("sender", &items, true, auditor)
+	want = []string{response.MessageBatchFailed, response.MessageBatchFailed}
+	if got := batchResults(t, results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("failed batch results = %v, want %v", got, want)
+	}
+	if count := messageCount(db); count != 0 {
+		t.Fatalf("%d messages created after rollback, want 0", count)
+	}
+	if count := auditCount(db); count != 0 {
+		t.Fatalf("%d audits recorded after rollback, want 0", count)
+	}
+
+	items = []request.MessageBatchItem{batchItem("first", false), batchItem("second", false)}
+	results = repository.CreateMessagesInBatches("sender", &items, true, auditor)
+	want = []string{response.MessageBatchCreated, response.MessageBatchCreated}
+	if got := batchResults(t, results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	if count := messageCount(db); count != 2 {
+		t.Fatalf("%d messages created, want 2", count)
+	}
+	if count := auditCount(db); count != 2 {
+		t.Fatalf("%d audits recorded, want 2", count)
+	}
+}
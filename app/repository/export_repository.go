@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/database"
+	"slices"
+)
+
+// ExportMessages 逐条读取消息，每读取一条调用一次 write
+//
+// token 不为空时只导出 token 发送的消息和 token 作为接收者没有删除的消息，接收者状态只包括 token 自己的状态；
+// 为空时导出所有消息和所有接收者的状态。接收者状态每 messageBatchSize 条消息查询一次。
+func ExportMessages(
+	// 消息凭证，为空时导出所有消息
+	token string,
+	// 消息过滤器
+	filters []request.MessageFilterRequest,
+	// 处理每条消息的函数
+	write func(message *response.ExportedMessage) error,
+) error {
+	query := database.DB.Model(&model.Message{})
+	if token != "" {
+		query.Where(visibleCondition(token))
+	}
+	applyMessageFilters(query, token, filters)
+
+	rows, err := query.Order("id asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	messages := make([]*response.ExportedMessage, 0, messageBatchSize)
+	writeMessages := func() error {
+		if err := loadRecipientStates(token, messages); err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := write(message); err != nil {
+				return err
+			}
+		}
+		messages = messages[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		message := &response.ExportedMessage{}
+		if err := database.DB.ScanRows(rows, &message.Message); err != nil {
+			return err
+		}
+		messages = append(messages, message)
+		if len(messages) == messageBatchSize {
+			if err := writeMessages(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return writeMessages()
+}
+
+// loadRecipientStates 查询消息已经记录的接收者状态，token 不为空时只查询 token 自己的状态
+func loadRecipientStates(token string, messages []*response.ExportedMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	messageIds := make([]string, 0, len(messages))
+	for _, message := range messages {
+		message.Recipients = make([]response.MessageRecipientState, 0)
+		messageIds = append(messageIds, message.MessageId)
+	}
+
+	var recipients []model.MessageRecipient
+	query := database.DB.Where("message_id IN ?", messageIds)
+	if token != "" {
+		query = query.Where("recipient_id = ?", token)
+	}
+	if err := query.Order("id asc").Find(&recipients).Error; err != nil {
+		return err
+	}
+
+	for _, recipient := range recipients {
+		index := slices.IndexFunc(messages, func(message *response.ExportedMessage) bool {
+			return message.MessageId == recipient.MessageId
+		})
+		messages[index].Recipients = append(messages[index].Recipients, response.MessageRecipientState{
+			RecipientId: recipient.RecipientId,
+			Status:      recipient.Status,
+			Hidden:      recipient.Hidden,
+			Pinned:      recipient.Pinned,
+			Starred:     recipient.Starred,
+			UpdatedAt:   recipient.UpdatedAt,
+		})
+	}
+	return nil
+}
+
+// ImportMessages 在一个事务中导入已经读取和校验的消息，每批消息写入一次数据库，返回导入和跳过的数量
+//
+// 消息id已经存在的消息和之前的批次中已经导入的消息跳过。任意一批消息返回错误时回滚所有已经导入的消息，
+// 不会只导入一部分。调用方需要先读取完请求体，事务只在写入数据库期间持有连接。
+//
+// dryRun 为 true 时只检查消息id是否已经存在，不写入数据库。
+func ImportMessages(
+	// 是否只检查不写入
+	dryRun bool,
+	// 审计日志的操作者，每条导入的消息记录一条审计日志
+	auditor *Auditor,
+	// 分批导入的消息
+	batches [][]*request.MessageImportRecord,
+) (int, int, error) {
+	// 已经导入的消息id，用于跳过不同批次中重复的消息
+	imported := make(map[string]bool)
+	importBatches := func(tx *gorm.DB) (int, int, error) {
+		var importedCount, skippedCount int
+		for _, records := range batches {
+			importedBatch, skippedBatch, err := importMessages(tx, records, imported, auditor, dryRun)
+			if err != nil {
+				return 0, 0, err
+			}
+			importedCount += importedBatch
+			skippedCount += skippedBatch
+		}
+		return importedCount, skippedCount, nil
+	}
+	if dryRun {
+		return importBatches(database.DB)
+	}
+
+	var importedCount, skippedCount int
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		importedCount, skippedCount, err = importBatches(tx)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return importedCount, skippedCount, nil
+}
+
+// importMessages 导入一批消息和接收者的状态，返回导入和跳过的数量
+func importMessages(
+	// 数据库连接或事务
+	tx *gorm.DB,
+	// 导入的消息
+	records []*request.MessageImportRecord,
+	// 已经导入的消息id
+	imported map[string]bool,
+	// 审计日志的操作者
+	auditor *Auditor,
+	// 是否只检查不写入
+	dryRun bool,
+) (int, int, error) {
+	if len(records) == 0 {
+		return 0, 0, nil
+	}
+
+	// 查询已经存在的消息id，包括已经软删除的消息
+	messageIds := make([]string, 0, len(records))
+	for _, record := range records {
+		messageIds = append(messageIds, record.MessageId)
+	}
+	var existing []string
+	err := tx.Unscoped().
+		Model(&model.Message{}).
+		Where("message_id IN ?", messageIds).
+		Pluck("message_id", &existing).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var messages []*model.Message
+	var recipients []*model.MessageRecipient
+	for _, record := range records {
+		// 跳过已经存在的消息和已经导入的消息
+		if imported[record.MessageId] || slices.Contains(existing, record.MessageId) {
+			continue
+		}
+		imported[record.MessageId] = true
+		messages = append(messages, importedMessage(record))
+		recipients = append(recipients, importedRecipients(record)...)
+	}
+	skipped := len(records) - len(messages)
+	if dryRun || len(messages) == 0 {
+		return len(messages), skipped, nil
+	}
+
+	if err := tx.CreateInBatches(messages, messageBatchSize).Error; err != nil {
+		return 0, 0, err
+	}
+	if len(recipients) > 0 {
+		if err := tx.CreateInBatches(recipients, messageBatchSize).Error; err != nil {
+			return 0, 0, err
+		}
+	}
+	importedIds := make([]string, 0, len(messages))
+	for _, message := range messages {
+		importedIds = append(importedIds, message.MessageId)
+	}
+	if err := auditor.record(tx, model.AuditImport, importedIds, nil); err != nil {
+		return 0, 0, err
+	}
+	return len(messages), skipped, nil
+}
+
+// importedRecipients 根据导入的数据生成接收者的状态
+func importedRecipients(record *request.MessageImportRecord) []*model.MessageRecipient {
+	recipients := make([]*model.MessageRecipient, 0, len(record.Recipients))
+	for _, state := range record.Recipients {
+		recipient := &model.MessageRecipient{
+			MessageId:   record.MessageId,
+			RecipientId: state.RecipientId,
+			Status:      state.Status,
+			Hidden:      state.Hidden,
+			Pinned:      state.Pinned,
+			Starred:     state.Starred,
+		}
+		// 没有更新时间时使用消息的创建时间
+		recipient.CreatedAt = record.CreatedAt
+		recipient.UpdatedAt = state.UpdatedAt
+		if recipient.UpdatedAt.IsZero() {
+			recipient.UpdatedAt = record.CreatedAt
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
+
+// importedMessage 根据导入的数据生成消息，保留消息id、发送者、接收者、状态和时间
+func importedMessage(record *request.MessageImportRecord) *model.Message {
+	message := &model.Message{
+		MessageId:     record.MessageId,
+		SenderIds:     record.SenderIds,
+		Title:         record.Title,
+		Content:       record.Content,
+		Category:      record.Category,
+		BigContent:    sanitizeBigContent(record.BigContent, record.ContentType),
+		ContentType:   record.ContentType,
+		IntroducerIds: record.IntroducerIds,
+		Data:          record.Data,
+		Actions:       newMessageActions(record.Actions),
+		Status:        record.Status,
+		Priority:      messagePriority(record.Priority, record.Category),
+		Version:       record.Version,
+		Edited:        record.Edited,
+	}
+	message.CreatedAt = record.CreatedAt
+	message.UpdatedAt = record.UpdatedAt
+	// 没有更新时间时使用创建时间
+	if message.UpdatedAt.IsZero() {
+		message.UpdatedAt = record.CreatedAt
+	}
+	return message
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/config"
+	"message/database"
+	"time"
+)
+
+// ErrIdempotencyKeyReused 表示同一个幂等键被用于内容不同的请求
+var ErrIdempotencyKeyReused = errors.New("idempotency key has been used with a different request")
+
+// ErrIdempotentMessageGone 表示幂等键之前创建的消息已经被彻底删除，无法再返回
+var ErrIdempotentMessageGone = errors.New("message created with the idempotency key has been purged")
+
+// idempotencyTTL 返回幂等键的有效期，没有配置时为 24 小时
+func idempotencyTTL() time.Duration {
+	if config.AppConfig.API.IdempotencyTTL <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(config.AppConfig.API.IdempotencyTTL) * time.Hour
+}
+
+// requestHash 计算请求内容的哈希，请求按照 JSON 序列化，不受字段顺序和空白字符影响
+func requestHash(createMessage *request.MessageCreateUpdateRequest) (string, error) {
+	value, err := json.Marshal(createMessage)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CreateIdempotentMessage 使用幂等键创建消息，返回消息以及是否是之前创建的消息
+//
+// 同一个发送者在有效期内使用同一个键和相同的内容重试时返回之前创建的消息，内容不同时返回 ErrIdempotencyKeyReused。
+func CreateIdempotentMessage(
+	// 消息凭证
+	token string,
+	// 幂等键
+	key string,
+	// 创建消息的请求
+	createMessage *request.MessageCreateUpdateRequest,
+	// 审计日志的操作者，返回之前创建的消息时不记录
+	auditor *Auditor,
+) (*response.Message, bool, error) {
+	hash, err := requestHash(createMessage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 查询没有过期的幂等键
+	idempotency, err := queryIdempotency(token, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if idempotency != nil {
+		return replayIdempotentMessage(idempotency, hash)
+	}
+
+	message := buildMessage(token, createMessage)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 删除同一个键已经过期的记录
+		now := time.Now()
+		err := tx.Where("sender_id = ?", token).
+			Where("idempotency_key = ?", key).
+			Where("expires_at <= ?", now).
+			Delete(&model.MessageIdempotency{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(&model.MessageIdempotency{
+			SenderId:       token,
+			IdempotencyKey: key,
+			RequestHash:    hash,
+			MessageId:      message.MessageId,
+			ExpiresAt:      now.Add(idempotencyTTL()),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Message{}).Create(message).Error; err != nil {
+			return err
+		}
+		return auditor.record(tx, model.AuditCreate, []string{message.MessageId}, nil)
+	})
+	if err != nil {
+		// 并发的请求使用同一个键时唯一索引冲突，返回先创建的消息
+		idempotency, queryErr := queryIdempotency(token, key)
+		if queryErr != nil || idempotency == nil {
+			return nil, false, err
+		}
+		return replayIdempotentMessage(idempotency, hash)
+	}
+
+	newMessage := &response.Message{}
+	database.DB.Model(&model.Message{}).Where("message_id = ?", message.MessageId).First(newMessage)
+	publishMessageCreated(token, newMessage)
+	return newMessage, false, nil
+}
+
+// queryIdempotency 查询发送者没有过期的幂等键，找不到时返回 nil
+func queryIdempotency(token string, key string) (*model.MessageIdempotency, error) {
+	idempotency := &model.MessageIdempotency{}
+	err := database.DB.Where("sender_id = ?", token).
+		Where("idempotency_key = ?", key).
+		Where("expires_at > ?", time.Now()).
+		First(idempotency).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return idempotency, nil
+}
+
+// replayIdempotentMessage 返回幂等键之前创建的消息，请求内容不同时返回 ErrIdempotencyKeyReused，
+// 消息已经被彻底删除时返回 ErrIdempotentMessageGone
+func replayIdempotentMessage(
+	idempotency *model.MessageIdempotency,
+	hash string,
+) (*response.Message, bool, error) {
+	if idempotency.RequestHash != hash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+
+	// 消息被软删除时同样返回之前创建的消息，被回收站清理后不能再返回，也不能重新创建
+	message := &response.Message{}
+	err := database.DB.Unscoped().
+		Model(&model.Message{}).
+		Where("message_id = ?", idempotency.MessageId).
+		First(message).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrIdempotentMessageGone
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return message, true, nil
+}
+
+// PurgeExpiredIdempotencies 删除已经过期的幂等键，返回删除的数量
+func PurgeExpiredIdempotencies() (int64, error) {
+	result := database.DB.Where("expires_at <= ?", time.Now()).Delete(&model.MessageIdempotency{})
+	return result.RowsAffected, result.Error
+}
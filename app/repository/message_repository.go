@@ -1,17 +1,29 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"gorm.io/gorm"
+	"message/app/event"
 	"message/app/model"
 	"message/app/request"
 	"message/app/response"
 	"message/config"
 	"message/database"
+	"message/logs"
 	"message/utils"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrMessageConflict 表示更新消息时消息已经被其他请求更新
+var ErrMessageConflict = errors.New("message has been modified by another request")
+
+// ErrMessageNoIntroducer 表示部分更新消息后消息没有接收者
+var ErrMessageNoIntroducer = errors.New("message must have at least one introducer")
+
 // QueryMessagesByMessageTokenMessageRequest 根据消息凭证和消息请求查询消息
 func QueryMessagesByMessageTokenMessageRequest(
 	// 消息凭证
@@ -20,30 +32,128 @@ func QueryMessagesByMessageTokenMessageRequest(
 	messageRequest *request.MessageRequest,
 	// 消息过滤器
 	filters []request.MessageFilterRequest,
-) []response.Message {
-	var messages []response.Message
+) []response.InboxMessage {
+	var messages []response.InboxMessage
 	// 创建消息查询对象
 	query := database.DB.Model(&model.Message{})
 
-	// 根据消息凭证中的 AuthId 进行筛选或introducer_ids等于空字符串
-	query.Where(
-		query.Where(
-			"introducer_ids LIKE ?",
-			fmt.Sprintf("%%%s%%", token),
-		).Or("introducer_ids = ?", ""),
+	// 只查询发给当前接收者或者发给所有人的消息，并排除接收者已经删除的消息
+	query.Where(inboxCondition(token))
+
+	// 查询接收者是否置顶和标星了消息
+	query.Select(
+		"message.*, "+
+			"CASE WHEN message_id IN (?) THEN 1 ELSE 0 END AS pinned, "+
+			"CASE WHEN message_id IN (?) THEN 1 ELSE 0 END AS starred",
+		flaggedMessageIds(token, "pinned"),
+		flaggedMessageIds(token, "starred"),
 	)
 
+	// 置顶的消息排在最前面
+	if messageRequest.PinnedFirst {
+		query.Order("pinned desc")
+	}
+
+	// 根据过滤、排序和分页信息查询消息并存储在 messages 中
+	findMessagesByMessageRequest(query, token, messageRequest, filters, &messages)
+
+	// 查询每条消息可以看到的标签
+	messageIds := make([]string, 0, len(messages))
+	for _, message := range messages {
+		messageIds = append(messageIds, message.MessageId)
+	}
+	tags := messageTags(database.DB, token, messageIds)
+	for i := range messages {
+		messages[i].Render(messageRequest.Format)
+		messages[i].Tags = tags[messages[i].MessageId]
+		if messages[i].Tags == nil {
+			messages[i].Tags = make([]string, 0)
+		}
+	}
+
+	// 返回查询到的消息数组
+	return messages
+}
+
+// findMessagesByMessageRequest 根据消息请求对查询进行过滤、排序和分页，并将结果存储在 messages 中
+func findMessagesByMessageRequest(
+	// 消息查询对象
+	query *gorm.DB,
+	// 消息凭证，用于过滤接收者的标记，为空时不限制接收者
+	token string,
+	// 消息请求参数
+	messageRequest *request.MessageRequest,
+	// 消息过滤器
+	filters []request.MessageFilterRequest,
+	// 存储查询结果的切片指针
+	messages interface{},
+) {
+	// 根据传入的过滤器条件进行进一步筛选
+	applyMessageFilters(query, token, filters)
+
+	// 根据排序字段和排序类型进行排序
+	if messageRequest.SortColumn == "" {
+		messageRequest.SortColumn = "created_at"
+	}
+	if messageRequest.SortType == "" {
+		messageRequest.SortType = "desc"
+	}
+	query.Order(fmt.Sprintf(
+		"%s %s",
+		messageRequest.SortColumn,
+		messageRequest.SortType,
+	))
+	// 排序列相同时按照创建时间倒序，例如同一优先级的消息最新的排在前面
+	if messageRequest.SortColumn != "created_at" {
+		query.Order("created_at desc")
+	}
+
+	// 根据分页信息查询消息并存储在 messages 中
+	maxLimit := config.AppConfig.API.MaxLimit
+	if messageRequest.Page == 0 {
+		messageRequest.Page = 1
+	}
+	query.Limit(maxLimit).Offset((messageRequest.Page - 1) * maxLimit).Find(messages)
+}
+
+// applyMessageFilters 根据过滤器条件对查询进行筛选
+func applyMessageFilters(
+	// 消息查询对象
+	query *gorm.DB,
+	// 消息凭证，用于过滤接收者的标记，为空时不限制接收者
+	token string,
+	// 消息过滤器
+	filters []request.MessageFilterRequest,
+) {
 	if len(filters) > 0 {
-		// 根据传入的过滤器条件进行进一步筛选
 		for _, filter := range filters {
-			if filter.Comparison == "in" {
+			if slices.Contains(request.RecipientFlagColumns, filter.Column) {
+				// 接收者的标记保存在接收者的消息记录中，使用子查询过滤
+				value, _ := strconv.ParseBool(filter.Value)
+				if filter.Comparison == "!=" {
+					value = !value
+				}
+				if value {
+					query.Where("message_id IN (?)", flaggedMessageIds(token, filter.Column))
+				} else {
+					query.Where("message_id NOT IN (?)", flaggedMessageIds(token, filter.Column))
+				}
+			} else if filter.Column == "tag" {
+				// 标签保存在消息标签关系中，使用子查询过滤
+				names := strings.Split(filter.Value, "|")
+				if filter.Comparison == "!=" {
+					query.Where("message_id NOT IN (?)", taggedMessageIds(token, names))
+				} else {
+					query.Where("message_id IN (?)", taggedMessageIds(token, names))
+				}
+			} else if filter.Comparison == "in" {
 				query.Where(
 					fmt.Sprintf(
 						"%s %s ?",
 						filter.Column,
 						filter.Comparison,
 					),
-					strings.Split(filter.Value, "|"),
+					filterValues(filter),
 				)
 			} else {
 				query.Where(
@@ -52,46 +162,92 @@ func QueryMessagesByMessageTokenMessageRequest(
 						filter.Column,
 						filter.Comparison,
 					),
-					filter.Value,
+					filterValues(filter)[0],
 				)
 			}
 		}
 	}
+}
 
-	// 根据排序字段和排序类型进行排序
-	if messageRequest.SortColumn == "" {
-		messageRequest.SortColumn = "created_at"
+// filterValues 返回过滤条件的值，in 的多个值使用 | 分隔，优先级的名称转换为保存的优先级
+func filterValues(filter request.MessageFilterRequest) []interface{} {
+	values := []string{filter.Value}
+	if filter.Comparison == "in" {
+		values = strings.Split(filter.Value, "|")
 	}
-	if messageRequest.SortType == "" {
-		messageRequest.SortType = "desc"
-	}
-	query.Order(fmt.Sprintf(
-		"%s %s",
-		messageRequest.SortColumn,
-		messageRequest.SortType,
-	))
 
-	// 根据分页信息查询消息并存储在 messages 中
-	maxLimit := config.AppConfig.API.MaxLimit
-	if messageRequest.Page == 0 {
-		messageRequest.Page = 1
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if filter.Column == "priority" {
+			priority, _ := model.ParsePriority(value)
+			result = append(result, priority)
+			continue
+		}
+		result = append(result, value)
 	}
-	query.Limit(maxLimit).Offset((messageRequest.Page - 1) * maxLimit).Find(&messages)
-
-	// 返回查询到的消息数组
-	return messages
+	return result
 }
 
 // CreateMessage 创建一条新消息
 func CreateMessage(
 	token string,
 	createMessage *request.MessageCreateUpdateRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
 ) (*response.Message, error) {
-	messageId := utils.BuildMessageId()
-	// 将消息插入到数据库中
-	result := database.DB.Model(&model.Message{}).Create(&model.Message{
+	message := buildMessage(token, createMessage)
+	// 将消息和审计日志在同一个事务中插入到数据库中
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Message{}).Create(message).Error; err != nil {
+			return err
+		}
+		return auditor.record(tx, model.AuditCreate, []string{message.MessageId}, nil)
+	})
+	// 如果发生错误，则返回 nil
+	if err != nil {
+		return nil, err
+	}
+
+	newMessage := &response.Message{}
+	database.DB.Model(&model.Message{}).Where("message_id = ?", message.MessageId).First(newMessage)
+	publishMessageCreated(token, newMessage)
+	// 返回创建的消息对象
+	return newMessage, nil
+}
+
+// publishMessageCreated 发布创建了新消息的事件
+func publishMessageCreated(token string, message *response.Message) {
+	event.Publish(event.Event{
+		Name:      event.MessageCreated,
+		MessageId: message.MessageId,
+		SenderIds: message.SenderIds,
+		Actor:     token,
+		Payload:   message,
+		CreatedAt: message.CreatedAt,
+		Urgent:    message.Priority == model.PriorityUrgent,
+	})
+}
+
+// publishMessagesCreated 查询批量创建的消息并逐条发布创建了新消息的事件
+func publishMessagesCreated(token string, messageIds []string) {
+	if len(messageIds) == 0 {
+		return
+	}
+	var messages []response.Message
+	database.DB.Model(&model.Message{}).Where("message_id IN ?", messageIds).Find(&messages)
+	for i := range messages {
+		publishMessageCreated(token, &messages[i])
+	}
+}
+
+// buildMessage 根据创建消息的请求生成一条新消息
+func buildMessage(
+	token string,
+	createMessage *request.MessageCreateUpdateRequest,
+) *model.Message {
+	return &model.Message{
 		// 生成消息 ID
-		MessageId: messageId,
+		MessageId: utils.BuildMessageId(),
 		// 设置消息的发送者 ID
 		SenderIds: []string{token},
 		// 设置消息标题
@@ -100,20 +256,42 @@ func CreateMessage(
 		Content: createMessage.Content,
 		// 设置消息类别
 		Category: createMessage.Category,
-		// 设置消息大文本内容
-		BigContent: createMessage.BigContent,
+		// 设置消息大文本内容，HTML 内容保存前过滤
+		BigContent: sanitizeBigContent(createMessage.BigContent, createMessage.ContentType),
+		// 设置消息大文本内容的格式，为空时使用数据库默认的纯文本
+		ContentType: createMessage.ContentType,
 		// 设置消息介绍者 ID
 		IntroducerIds: createMessage.IntroducerIds,
-	})
-	// 如果发生错误或者影响的行数为 0，则返回 nil
-	if result.Error != nil {
-		return nil, result.Error
+		// 设置消息附带的结构化数据
+		Data: createMessage.Data,
+		// 设置消息的操作按钮
+		Actions: newMessageActions(createMessage.Actions),
+		// 设置消息优先级，没有指定时根据消息类别设置
+		Priority: messagePriority(createMessage.Priority, createMessage.Category),
 	}
+}
 
-	newMessage := &response.Message{}
-	database.DB.Model(&model.Message{}).Where("message_id = ?", messageId).First(newMessage)
-	// 返回创建的消息对象
-	return newMessage, nil
+// messagePriority 将优先级的名称转换为保存的优先级，没有指定优先级时使用消息类别对应的优先级
+//
+// 消息类别不区分大小写，没有配置优先级时使用默认优先级，默认优先级也没有配置时返回 0，由数据库设置为普通优先级。
+func messagePriority(name string, category string) model.Priority {
+	if priority, ok := model.ParsePriority(name); ok {
+		return priority
+	}
+	for key, categoryPriority := range config.AppConfig.App.Priority.Categories {
+		if strings.EqualFold(key, category) {
+			return model.Priority(categoryPriority)
+		}
+	}
+	return model.Priority(config.AppConfig.App.Priority.Default)
+}
+
+// sanitizeBigContent 过滤 HTML 格式的详细内容，其他格式原样返回
+func sanitizeBigContent(content string, contentType string) string {
+	if contentType == utils.ContentTypeHTML {
+		return utils.SanitizeHTML(content)
+	}
+	return content
 }
 
 // UpdateMessage 更新消息内容
@@ -122,7 +300,12 @@ func UpdateMessage(
 	message *model.Message,
 	// 消息更新的内容
 	messageUpdate *request.MessageCreateUpdateRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
 ) (*response.Message, error) {
+	// 保存更新前的消息，用于比较修改的字段
+	before := *message
+
 	// 更新消息标题
 	message.Title = messageUpdate.Title
 	// 更新消息内容
@@ -133,12 +316,138 @@ func UpdateMessage(
 	message.BigContent = messageUpdate.BigContent
 	// 更新消息介绍者 ID
 	message.IntroducerIds = messageUpdate.IntroducerIds
+	// 更新消息优先级，没有指定时不修改
+	if priority, ok := model.ParsePriority(messageUpdate.Priority); ok {
+		message.Priority = priority
+	}
+	// 更新消息大文本内容的格式，没有指定时不修改
+	if messageUpdate.ContentType != "" {
+		message.ContentType = messageUpdate.ContentType
+	}
+	// 更新消息附带的结构化数据和操作按钮，没有指定时不修改
+	if messageUpdate.Data != nil {
+		message.Data = messageUpdate.Data
+	}
+	if messageUpdate.Actions != nil {
+		message.Actions = newMessageActions(messageUpdate.Actions)
+	}
 
-	// 保存更新后的消息到数据库中
-	result := database.DB.Model(&model.Message{}).Where("id = ?", message.ID).Updates(message)
-	// 如果发生错误或者影响的行数为 0，则返回 nil
-	if result.Error != nil {
-		return nil, result.Error
+	// 保存更新后的消息到数据库中，并返回更新后的消息对象
+	return saveMessage(&before, message, auditor)
+}
+
+// PatchMessage 部分更新消息，只更新请求中存在的字段
+func PatchMessage(
+	// 待更新的消息对象
+	message *model.Message,
+	// 消息部分更新的内容
+	messagePatch *request.MessagePatchRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) (*response.Message, error) {
+	// 保存更新前的消息，用于比较修改的字段
+	before := *message
+
+	if messagePatch.Title != nil {
+		message.Title = *messagePatch.Title
+	}
+	if messagePatch.Content != nil {
+		message.Content = *messagePatch.Content
+	}
+	if messagePatch.Category != nil {
+		message.Category = *messagePatch.Category
+	}
+	if messagePatch.BigContent != nil {
+		message.BigContent = *messagePatch.BigContent
+	}
+	if messagePatch.Priority != nil {
+		message.Priority, _ = model.ParsePriority(*messagePatch.Priority)
+	}
+	if messagePatch.ContentType != nil {
+		message.ContentType = *messagePatch.ContentType
+	}
+	if messagePatch.ClearData {
+		message.Data = model.MessageData{}
+	}
+	if len(messagePatch.Data) > 0 {
+		// 按照 JSON Merge Patch 合并，合并后同样不能超过大小限制
+		data := utils.MergePatch(message.Data, messagePatch.Data)
+		if err := request.ValidateMessageData(data); err != nil {
+			return nil, err
+		}
+		message.Data = data
+	}
+	if messagePatch.Actions != nil {
+		message.Actions = newMessageActions(*messagePatch.Actions)
+	}
+
+	// 先替换全部接收者，再添加和移除单个接收者
+	introducerIds := slices.Clone(message.IntroducerIds)
+	if messagePatch.IntroducerIds != nil {
+		introducerIds = slices.Clone(*messagePatch.IntroducerIds)
+	}
+	for _, introducerId := range messagePatch.AddIntroducerIds {
+		if !slices.Contains(introducerIds, introducerId) {
+			introducerIds = append(introducerIds, introducerId)
+		}
+	}
+	introducerIds = slices.DeleteFunc(introducerIds, func(introducerId string) bool {
+		return slices.Contains(messagePatch.RemoveIntroducerIds, introducerId)
+	})
+	if len(introducerIds) == 0 {
+		return nil, ErrMessageNoIntroducer
+	}
+	message.IntroducerIds = introducerIds
+
+	// 保存更新后的消息到数据库中，并返回更新后的消息对象
+	return saveMessage(&before, message, auditor)
+}
+
+// saveMessage 保存更新后的消息，消息有修改时保存更新前的版本并增加版本号
+func saveMessage(
+	// 更新前的消息对象
+	before *model.Message,
+	// 更新后的消息对象
+	message *model.Message,
+	// 审计日志的操作者
+	auditor *Auditor,
+) (*response.Message, error) {
+	// HTML 内容保存前过滤
+	message.BigContent = sanitizeBigContent(message.BigContent, message.ContentType)
+
+	changes := diffMessage(before, message)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 没有修改的字段时不增加版本号
+		if len(changes) == 0 {
+			return auditor.record(tx, model.AuditUpdate, []string{message.MessageId}, changes)
+		}
+
+		// 保存更新前的版本
+		if err := createMessageVersion(tx, before); err != nil {
+			return err
+		}
+
+		// 增加版本号并标记为已编辑
+		message.Version = before.Version + 1
+		message.Edited = true
+
+		// 保存更新后的消息到数据库中，版本号不一致时表示消息已经被其他请求更新
+		result := tx.Model(&model.Message{}).
+			Where("id = ?", message.ID).
+			Where("version = ?", before.Version).
+			Updates(message)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMessageConflict
+		}
+		return auditor.record(tx, model.AuditUpdate, []string{message.MessageId}, changes)
+	})
+	// 如果发生错误，则返回 nil
+	if err != nil {
+		return nil, err
 	}
 
 	newMessage := &response.Message{}
@@ -153,24 +462,49 @@ func UpdateMessageStatus(
 	token string,
 	// 要更新的状态请求切片
 	status *[]request.MessageStatusRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
 ) []response.MessageStatusResponse {
 	// 初始化一个空的MessageStatusResponse切片用于存放每个状态更新的结果
 	results := make([]response.MessageStatusResponse, 0)
 
 	// 遍历状态请求切片，对每个请求进行处理
 	for _, statusRequest := range *status {
-		// 对model.Message模型执行更新操作，设置新的状态
-		// 使用LIKE查询匹配introducer_ids，并确保message_id与AuthId相符
-		result := database.DB.Model(&model.Message{}).
-			Where("introducer_ids LIKE ?", fmt.Sprintf("%%%s%%", token)).
-			Where("message_id = ?", statusRequest.Id).
-			Update("status", statusRequest.Status)
+		// 每条消息在单独的事务中更新状态、记录接收者的状态和审计日志
+		updated := false
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// 只更新当前接收者的消息，并确保message_id与请求相符
+			result := tx.Model(&model.Message{}).
+				Where(recipientCondition(token)).
+				Where("message_id = ?", statusRequest.Id).
+				Update("status", statusRequest.Status)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			// 更新成功后记录当前接收者的消息状态
+			err := saveMessageRecipient(tx, &model.MessageRecipient{
+				MessageId:   statusRequest.Id,
+				RecipientId: token,
+				Status:      statusRequest.Status,
+			}, "status")
+			if err != nil {
+				return err
+			}
+			updated = true
+			return auditor.record(tx, model.AuditStatus, []string{statusRequest.Id}, model.AuditChanges{
+				"status": {After: statusRequest.Status},
+			})
+		})
+		if err != nil {
+			logs.LogError.Errorf("UpdateMessageStatus %s %s %s", statusRequest.Id, token, err)
+		}
 
 		// 将每次更新操作的结果封装到MessageStatusResponse中，并追加到结果切片中
 		results = append(results, response.MessageStatusResponse{
 			Id:     statusRequest.Id,
 			Status: statusRequest.Status,
-			Result: result.Error == nil && result.RowsAffected != 0,
+			Result: err == nil && updated,
 		})
 	}
 
@@ -190,7 +524,7 @@ func QueryMessageById(
 
 	// 在数据库中查询匹配条件的消息
 	result := database.DB.Model(model.Message{}).
-		Where("sender_ids LIKE ?", fmt.Sprintf("%%%s%%", authId)).
+		Where(containsCondition("message.sender_ids", authId)).
 		Where("message_id = ?", id).
 		First(message)
 
@@ -203,74 +537,343 @@ func QueryMessageById(
 	return message
 }
 
-// DeleteMessagesById 根据消息 ID 批量删除消息
+// DeleteMessagesById 根据消息 ID 批量删除发送者的消息
 func DeleteMessagesById(
 	// 消息凭证
 	token string,
 	// 要删除的消息请求切片
 	deleteRequests *[]request.MessageDeleteRequest,
+	// 为 true 时任意一条消息删除失败则全部回滚
+	atomic bool,
+	// 审计日志的操作者
+	auditor *Auditor,
 ) []response.MessageDeleteResponse {
-	// 存储要物理删除的消息 ID
-	var deletes []string
-	// 存储要软删除的消息 ID
-	var softDeletes []string
+	return deleteMessagesById(deleteRequests, atomic, model.AuditDelete, auditor, senderCanDelete(token))
+}
+
+// senderCanDelete 返回判断 token 是否是消息发送者的方法，只有发送者可以删除和撤回消息
+func senderCanDelete(token string) func(message *model.Message) bool {
+	return func(message *model.Message) bool {
+		return slices.Contains(message.SenderIds, token)
+	}
+}
+
+// RetractMessagesById 根据消息 ID 批量撤回发送者的消息，撤回后所有接收者都看不到消息
+func RetractMessagesById(
+	// 消息凭证
+	token string,
+	// 要撤回的消息请求切片
+	retractRequests *[]request.MessageRetractRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageDeleteResponse {
+	// 撤回的消息会被软删除，发送者可以在回收站中恢复
+	deleteRequests := make([]request.MessageDeleteRequest, 0, len(*retractRequests))
+	for _, messageRetract := range *retractRequests {
+		deleteRequests = append(deleteRequests, request.MessageDeleteRequest{
+			MessageId: messageRetract.MessageId,
+			Delete:    false,
+		})
+	}
+
+	return deleteMessagesById(&deleteRequests, false, model.AuditRetract, auditor, senderCanDelete(token))
+}
+
+// deleteMessagesById 根据消息 ID 批量删除消息，canDelete 用于判断是否有权限删除消息
+func deleteMessagesById(
+	// 要删除的消息请求切片
+	deleteRequests *[]request.MessageDeleteRequest,
+	// 为 true 时任意一条消息删除失败则全部回滚
+	atomic bool,
+	// 审计日志的操作类型
+	action string,
+	// 审计日志的操作者
+	auditor *Auditor,
+	// 判断是否有权限删除消息
+	canDelete func(message *model.Message) bool,
+) []response.MessageDeleteResponse {
+	// 存储删除操作的结果切片
+	results := make([]response.MessageDeleteResponse, len(*deleteRequests))
+
+	if !atomic {
+		// 每条消息在单独的事务中删除，互不影响
+		for i, messageDelete := range *deleteRequests {
+			database.DB.Transaction(func(tx *gorm.DB) error {
+				var err error
+				results[i], err = deleteMessageById(tx, messageDelete, action, auditor, canDelete)
+				return err
+			})
+		}
+
+		// 返回删除操作的结果切片
+		return results
+	}
+
+	// 所有消息在同一个事务中删除，任意一条消息删除失败则全部回滚
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, messageDelete := range *deleteRequests {
+			var err error
+			results[i], err = deleteMessageById(tx, messageDelete, action, auditor, canDelete)
+			if err != nil {
+				return err
+			}
+			if !results[i].Status {
+				return errors.New(results[i].Result)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for i, messageDelete := range *deleteRequests {
+			// 已经删除成功或者还没有处理的消息都被回滚
+			if results[i].Status || results[i].Result == "" {
+				results[i] = response.MessageDeleteResponse{
+					Id:     messageDelete.MessageId,
+					Delete: messageDelete.Delete,
+					Status: false,
+					Result: response.MessageDeleteRolledBack,
+				}
+			}
+		}
+	}
+
+	// 返回删除操作的结果切片
+	return results
+}
+
+// deleteMessageById 在事务中删除一条消息并返回删除结果，只有数据库出现错误时返回 error
+func deleteMessageById(
+	// 事务
+	tx *gorm.DB,
+	// 要删除的消息请求
+	messageDelete request.MessageDeleteRequest,
+	// 审计日志的操作类型
+	action string,
+	// 审计日志的操作者
+	auditor *Auditor,
+	// 判断是否有权限删除消息
+	canDelete func(message *model.Message) bool,
+) (response.MessageDeleteResponse, error) {
+	result := response.MessageDeleteResponse{
+		Id:     messageDelete.MessageId,
+		Delete: messageDelete.Delete,
+	}
 
-	// 遍历消息删除请求切片，将要删除和要软删除的消息 ID 分别添加到对应的切片中
-	for _, messageDelete := range *deleteRequests {
+	// 物理删除时包括已经软删除的消息
+	query := func() *gorm.DB {
 		if messageDelete.Delete {
-			deletes = append(deletes, messageDelete.MessageId)
-		} else {
-			softDeletes = append(softDeletes, messageDelete.MessageId)
+			return tx.Unscoped().Where("message_id = ?", messageDelete.MessageId)
+		}
+		return tx.Where("message_id = ?", messageDelete.MessageId)
+	}
+
+	// 查询要删除的消息
+	message := &model.Message{}
+	if err := query().First(message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Result = response.MessageDeleteNotFound
+			return result, nil
 		}
+		result.Result = response.MessageDeleteFailed
+		return result, err
 	}
 
-	// 物理删除要删除的消息
-	database.DB.Unscoped().
-		Where("sender_ids LIKE ?", fmt.Sprintf("%%%s%%", token)).
-		Where("message_id in ?", deletes).
-		Delete(&model.Message{})
-
-	// 软删除要软删除的消息
-	database.DB.
-		Where("sender_ids LIKE ?", fmt.Sprintf("%%%s%%", token)).
-		Where("message_id in ?", softDeletes).
-		Delete(&model.Message{})
-
-	// 存储物理删除失败的消息 ID
-	var failDeletes []string
-	database.DB.
-		Select("message_id").
-		Where("sender_ids LIKE ?", fmt.Sprintf("%%%s%%", token)).
-		Where("message_id in ?", softDeletes).Find(&failDeletes)
-
-	// 存储软删除失败的消息 ID
-	var failSoftDeletes []string
-	database.DB.
-		Select("message_id").
-		Where("sender_ids LIKE ?", fmt.Sprintf("%%%s%%", token)).
-		Where("message_id in ?", softDeletes).Find(&failSoftDeletes)
+	// 检查是否有权限删除消息
+	if !canDelete(message) {
+		result.Result = response.MessageDeleteForbidden
+		return result, nil
+	}
 
-	// 存储删除操作的结果切片
-	var results []response.MessageDeleteResponse
-
-	// 遍历物理删除的消息 ID，将每个 ID 和删除状态封装到 MessageDeleteResponse 中，并根据删除是否成功设置相应的状态
-	for _, id := range deletes {
-		results = append(results, response.MessageDeleteResponse{
-			Id:     id,
-			Delete: true,
-			Status: slices.Contains(failDeletes, id),
-		})
+	// 删除消息
+	if err := query().Delete(&model.Message{}).Error; err != nil {
+		result.Result = response.MessageDeleteFailed
+		return result, err
+	}
+
+	// 物理删除消息后同时删除接收者的状态
+	if messageDelete.Delete {
+		err := tx.Unscoped().
+			Where("message_id = ?", messageDelete.MessageId).
+			Delete(&model.MessageRecipient{}).Error
+		if err != nil {
+			result.Result = response.MessageDeleteFailed
+			return result, err
+		}
+
+		// 删除消息的历史版本
+		err = tx.Unscoped().
+			Where("message_id = ?", messageDelete.MessageId).
+			Delete(&model.MessageVersion{}).Error
+		if err != nil {
+			result.Result = response.MessageDeleteFailed
+			return result, err
+		}
+
+		// 删除消息的标签
+		err = tx.Where("message_id = ?", messageDelete.MessageId).
+			Delete(&model.MessageTag{}).Error
+		if err != nil {
+			result.Result = response.MessageDeleteFailed
+			return result, err
+		}
+
+		// 删除接收者点击操作按钮的记录
+		err = tx.Unscoped().
+			Where("message_id = ?", messageDelete.MessageId).
+			Delete(&model.MessageActionChoice{}).Error
+		if err != nil {
+			result.Result = response.MessageDeleteFailed
+			return result, err
+		}
+	}
+
+	// 在同一个事务中记录审计日志
+	if err := auditor.record(tx, action, []string{messageDelete.MessageId}, nil); err != nil {
+		result.Result = response.MessageDeleteFailed
+		return result, err
 	}
 
-	// 遍历软删除失败的消息 ID，将每个 ID 和删除状态封装到 MessageDeleteResponse 中，并根据删除是否成功设置相应的状态
-	for _, id := range failSoftDeletes {
-		results = append(results, response.MessageDeleteResponse{
-			Id:     id,
-			Delete: false,
-			Status: slices.Contains(failSoftDeletes, id),
+	result.Status = true
+	result.Result = response.MessageDeleteDeleted
+	return result, nil
+}
+
+// QueryTrashMessagesByMessageToken 根据消息凭证查询发送者已软删除的消息
+func QueryTrashMessagesByMessageToken(
+	// 消息凭证
+	token string,
+	// 消息请求参数
+	messageRequest *request.MessageRequest,
+	// 消息过滤器
+	filters []request.MessageFilterRequest,
+) []response.TrashMessage {
+	var messages []response.TrashMessage
+	// 创建消息查询对象，包括已软删除的消息
+	query := database.DB.Unscoped().
+		Model(&model.Message{}).
+		Where(containsCondition("message.sender_ids", token)).
+		Where("deleted_at IS NOT NULL")
+
+	// 根据过滤、排序和分页信息查询消息并存储在 messages 中
+	findMessagesByMessageRequest(query, token, messageRequest, filters, &messages)
+
+	// 将详细内容渲染为请求的格式
+	for i := range messages {
+		messages[i].Render(messageRequest.Format)
+	}
+
+	// 返回查询到的消息数组
+	return messages
+}
+
+// RestoreMessagesById 根据消息 ID 批量恢复发送者已软删除的消息
+func RestoreMessagesById(
+	// 消息凭证
+	token string,
+	// 要恢复的消息请求切片
+	restoreRequests *[]request.MessageRestoreRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageRestoreResponse {
+	return restoreMessagesById(restoreRequests, auditor, func(db *gorm.DB) *gorm.DB {
+		return db.Where(containsCondition("message.sender_ids", token))
+	})
+}
+
+// restoreMessagesById 根据消息 ID 批量恢复已软删除的消息，scopes 用于限制可以恢复的消息
+func restoreMessagesById(
+	// 要恢复的消息请求切片
+	restoreRequests *[]request.MessageRestoreRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+	// 查询的限制条件
+	scopes ...func(*gorm.DB) *gorm.DB,
+) []response.MessageRestoreResponse {
+	// 存储恢复操作的结果切片
+	results := make([]response.MessageRestoreResponse, 0)
+
+	for _, messageRestore := range *restoreRequests {
+		// 每条消息在单独的事务中恢复并记录审计日志
+		restored := false
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Unscoped().
+				Model(&model.Message{}).
+				Scopes(scopes...).
+				Where("message_id = ?", messageRestore.MessageId).
+				Where("deleted_at IS NOT NULL").
+				Update("deleted_at", nil)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			restored = true
+			return auditor.record(tx, model.AuditRestore, []string{messageRestore.MessageId}, nil)
+		})
+		if err != nil {
+			logs.LogError.Errorf("restoreMessagesById %s %s", messageRestore.MessageId, err)
+		}
+
+		results = append(results, response.MessageRestoreResponse{
+			Id:     messageRestore.MessageId,
+			Status: err == nil && restored,
 		})
 	}
 
-	// 返回删除操作的结果切片
+	// 返回恢复操作的结果切片
 	return results
 }
+
+// PurgeTrashMessages 物理删除在 before 之前软删除的消息，返回删除的消息数量
+func PurgeTrashMessages(before time.Time) (int64, error) {
+	var purged int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 查询需要清理的消息 ID
+		var messageIds []string
+		result := tx.Unscoped().
+			Model(&model.Message{}).
+			Where("deleted_at IS NOT NULL").
+			Where("deleted_at < ?", before).
+			Pluck("message_id", &messageIds)
+		if result.Error != nil || len(messageIds) == 0 {
+			return result.Error
+		}
+
+		// 删除消息接收者的状态
+		result = tx.Unscoped().
+			Where("message_id in ?", messageIds).
+			Delete(&model.MessageRecipient{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// 删除消息的历史版本
+		result = tx.Unscoped().
+			Where("message_id in ?", messageIds).
+			Delete(&model.MessageVersion{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// 删除消息的标签
+		result = tx.Where("message_id in ?", messageIds).
+			Delete(&model.MessageTag{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// 删除接收者点击操作按钮的记录
+		result = tx.Unscoped().
+			Where("message_id in ?", messageIds).
+			Delete(&model.MessageActionChoice{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// 物理删除消息
+		result = tx.Unscoped().
+			Where("message_id in ?", messageIds).
+			Delete(&model.Message{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
@@ -0,0 +1,117 @@
+package repository_test
+
+import (
+	"fmt"
+	"message/app/model"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/testutil"
+	"testing"
+)
+
+// auditor 测试中修改消息的操作者
+var auditor = &repository.Auditor{Actor: "sender", Ip: "127.0.0.1"}
+
+// deleteResults 返回批量删除每条消息的结果
+func deleteResults(results []response.MessageDeleteResponse) []string {
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Result)
+	}
+	return got
+}
+
+func TestDeleteMessagesByIdBestEffort(t *testing.T) {
+	db := testutil.Setup(t)
+
+	own := createMessage(t, db, "sender", "recipient")
+	other := createMessage(t, db, "other", "recipient")
+	missing := fmt.Sprintf("%032d", 99)
+	deleteRequests := []request.MessageDeleteRequest{
+		{MessageId: own},
+		{MessageId: missing},
+		{MessageId: other},
+	}
+
+	results := repository.DeleteMessagesById("sender", &deleteRequests, false, auditor)
+	want := []string{response.MessageDeleteDeleted, response.MessageDeleteNotFound, response.MessageDeleteForbidden}
+	if got := deleteResults(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	for i, result := range results {
+		if result.Id != deleteRequests[i].MessageId || result.Status != (i == 0) {
+			t.Fatalf("result %d = %+v, want id %s", i, result, deleteRequests[i].MessageId)
+		}
+	}
+
+	// 删除成功的消息被软删除，其他消息不受影响
+	var count int64
+	db.Model(&model.Message{}).Where("message_id = ?", own).Count(&count)
+	if count != 0 {
+		t.Fatalf("deleted message is still visible")
+	}
+	db.Unscoped().Model(&model.Message{}).Where("message_id = ?", own).Count(&count)
+	if count != 1 {
+		t.Fatalf("soft deleted message was removed")
+	}
+	db.Model(&model.Message{}).Where("message_id = ?", other).Count(&count)
+	if count != 1 {
+		t.Fatalf("message of another sender was deleted")
+	}
+	if count := auditCount(db); count != 1 {
+		t.Fatalf("%d audits recorded, want 1", count)
+	}
+}
+
+func TestDeleteMessagesByIdAtomic(t *testing.T) {
+	db := testutil.Setup(t)
+
+	first := createMessage(t, db, "sender", "recipient")
+	second := createMessage(t, db, "sender", "recipient")
+	other := createMessage(t, db, "other", "recipient")
+
+	// 没有权限删除第二条消息，已经删除的第一条和还没有处理的第三条都被回滚
+	deleteRequests := []request.MessageDeleteRequest{
+		{MessageId: first, Delete: true},
+		{MessageId: other, Delete: true},
+		{MessageId: second, Delete: true},
+	}
+	results := repository.DeleteMessagesById("sender", &deleteRequests, true, auditor)
+	want := []string{response.MessageDeleteRolledBack, response.MessageDeleteForbidden, response.MessageDeleteRolledBack}
+	if got := deleteResults(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	var count int64
+	db.Unscoped().Model(&model.Message{}).Count(&count)
+	if count != 3 {
+		t.Fatalf("%d messages after rollback, want 3", count)
+	}
+	if count := auditCount(db); count != 0 {
+		t.Fatalf("%d audits recorded after rollback, want 0", count)
+	}
+
+	// 全部有权限时在同一个事务中删除，物理删除同时删除接收者的状态
+	repository.HideMessagesById("recipient", &[]request.MessageHideRequest{{MessageId: first}}, nil)
+	deleteRequests = []request.MessageDeleteRequest{
+		{MessageId: first, Delete: true},
+		{MessageId: second},
+	}
+	results = repository.DeleteMessagesById("sender", &deleteRequests, true, auditor)
+	want = []string{response.MessageDeleteDeleted, response.MessageDeleteDeleted}
+	if got := deleteResults(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	db.Unscoped().Model(&model.Message{}).Where("message_id = ?", first).Count(&count)
+	if count != 0 {
+		t.Fatalf("physically deleted message still exists")
+	}
+	db.Unscoped().Model(&model.MessageRecipient{}).Where("message_id = ?", first).Count(&count)
+	if count != 0 {
+		t.Fatalf("recipient state of a physically deleted message still exists")
+	}
+	db.Unscoped().Model(&model.Message{}).Where("message_id = ?", second).Where("deleted_at IS NOT NULL").Count(&count)
+	if count != 1 {
+		t.Fatalf("second message was not soft deleted")
+	}
+}
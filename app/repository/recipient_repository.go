@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/database"
+	"message/logs"
+	"slices"
+)
+
+// saveMessageRecipient 保存接收者的消息记录，记录已存在时只更新 columns 中的列
+func saveMessageRecipient(
+	// 数据库连接或事务
+	tx *gorm.DB,
+	// 接收者的消息记录
+	recipient *model.MessageRecipient,
+	// 记录已存在时需要更新的列
+	columns ...string,
+) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "recipient_id"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).Create(recipient).Error
+}
+
+// hiddenMessageIds 返回接收者已经删除的消息 ID 子查询
+func hiddenMessageIds(recipientId string) *gorm.DB {
+	return database.DB.Model(&model.MessageRecipient{}).
+		Select("message_id").
+		Where("recipient_id = ?", recipientId).
+		Where("hidden = ?", true)
+}
+
+// flaggedMessageIds 返回接收者设置了标记的消息 ID 子查询，接收者为空时返回任意接收者设置了标记的消息
+func flaggedMessageIds(recipientId string, column string) *gorm.DB {
+	query := database.DB.Model(&model.MessageRecipient{}).
+		Select("message_id").
+		Where(column+" = ?", true)
+	if recipientId != "" {
+		query = query.Where("recipient_id = ?", recipientId)
+	}
+	return query
+}
+
+// containsCondition 返回逗号分隔的 ID 列 column 中包含 id 的查询条件，与 slices.Contains 的判断相同
+func containsCondition(column string, id string) *gorm.DB {
+	like := database.EscapeLike(id)
+	escape := " " + database.LikeEscape(database.DB)
+	return database.DB.Where(column+" = ?", id).
+		Or(column+" LIKE ?"+escape, like+",%").
+		Or(column+" LIKE ?"+escape, "%,"+like).
+		Or(column+" LIKE ?"+escape, "%,"+like+",%")
+}
+
+// recipientCondition 返回 token 是消息接收者的查询条件，与 IsRecipient 的判断相同，接收者为空时表示发给所有人
+func recipientCondition(token string) *gorm.DB {
+	return database.DB.Where("message.introducer_ids = ?", "").
+		Or(containsCondition("message.introducer_ids", token))
+}
+
+// inboxCondition 返回消息在 token 收件箱中的查询条件，即 token 是接收者并且没有删除消息
+func inboxCondition(token string) *gorm.DB {
+	return database.DB.Where(recipientCondition(token)).
+		Where("message.message_id NOT IN (?)", hiddenMessageIds(token))
+}
+
+// visibleCondition 返回 token 可以看到消息的查询条件，与 QueryVisibleMessageById 的判断相同：
+// 发送者总是可以看到消息，接收者可以看到没有删除的消息
+func visibleCondition(token string) *gorm.DB {
+	return database.DB.Where(containsCondition("message.sender_ids", token)).
+		Or(inboxCondition(token))
+}
+
+// isMessageRecipient 判断 token 是否是消息的接收者，接收者为空时表示发给所有人
+func isMessageRecipient(message *model.Message, token string) bool {
+	return IsRecipient(message.IntroducerIds, token)
+}
+
+// IsRecipient 判断 token 是否在消息的接收者中，接收者为空时表示发给所有人
+func IsRecipient(introducerIds model.StringArray, token string) bool {
+	if len(introducerIds) == 0 || slices.Equal(introducerIds, model.StringArray{""}) {
+		return true
+	}
+	return slices.Contains(introducerIds, token)
+}
+
+// HideMessagesById 根据消息 ID 批量删除接收者自己的消息，不影响其他接收者和发送者
+func HideMessagesById(
+	// 消息凭证
+	token string,
+	// 要删除的消息请求切片
+	hideRequests *[]request.MessageHideRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageDeleteResponse {
+	// 存储删除操作的结果切片
+	results := make([]response.MessageDeleteResponse, 0)
+
+	for _, messageHide := range *hideRequests {
+		result := response.MessageDeleteResponse{
+			Id:     messageHide.MessageId,
+			Delete: false,
+		}
+
+		// 查询要删除的消息
+		message := &model.Message{}
+		err := database.DB.Where("message_id = ?", messageHide.MessageId).First(message).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			result.Result = response.MessageDeleteNotFound
+		case err != nil:
+			result.Result = response.MessageDeleteFailed
+		case !isMessageRecipient(message, token):
+			// 只有接收者可以删除自己的消息
+			result.Result = response.MessageDeleteForbidden
+		default:
+			err = database.DB.Transaction(func(tx *gorm.DB) error {
+				err := saveMessageRecipient(tx, &model.MessageRecipient{
+					MessageId:   messageHide.MessageId,
+					RecipientId: token,
+					Hidden:      true,
+				}, "hidden")
+				if err != nil {
+					return err
+				}
+				return auditor.record(tx, model.AuditHide, []string{messageHide.MessageId}, nil)
+			})
+			if err != nil {
+				logs.LogError.Errorf("HideMessagesById %s %s %s", messageHide.MessageId, token, err)
+				result.Result = response.MessageDeleteFailed
+			} else {
+				result.Status = true
+				result.Result = response.MessageDeleteDeleted
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	// 返回删除操作的结果切片
+	return results
+}
+
+// UpdateMessageFlags 根据消息 ID 批量设置接收者的置顶和标星，只修改请求中存在的标记
+func UpdateMessageFlags(
+	// 消息凭证
+	token string,
+	// 要设置的标记请求切片
+	flagRequests *[]request.MessageFlagRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageFlagResponse {
+	results := make([]response.MessageFlagResponse, 0)
+
+	for _, flagRequest := range *flagRequests {
+		result := response.MessageFlagResponse{
+			Id: flagRequest.Id,
+		}
+
+		// 只有接收者可以标记没有删除的消息
+		message := &model.Message{}
+		err := database.DB.Where("message_id = ?", flagRequest.Id).
+			Where("message_id NOT IN (?)", hiddenMessageIds(token)).
+			First(message).Error
+		if err == nil && isMessageRecipient(message, token) {
+			recipient := &model.MessageRecipient{
+				MessageId:   flagRequest.Id,
+				RecipientId: token,
+			}
+			var columns []string
+			changes := model.AuditChanges{}
+			if flagRequest.Pinned != nil {
+				recipient.Pinned = *flagRequest.Pinned
+				columns = append(columns, "pinned")
+				changes["pinned"] = model.AuditChange{After: recipient.Pinned}
+			}
+			if flagRequest.Starred != nil {
+				recipient.Starred = *flagRequest.Starred
+				columns = append(columns, "starred")
+				changes["starred"] = model.AuditChange{After: recipient.Starred}
+			}
+			err = database.DB.Transaction(func(tx *gorm.DB) error {
+				if err := saveMessageRecipient(tx, recipient, columns...); err != nil {
+					return err
+				}
+				return auditor.record(tx, model.AuditFlag, []string{flagRequest.Id}, changes)
+			})
+			if err != nil {
+				logs.LogError.Errorf("UpdateMessageFlags %s %s %s", flagRequest.Id, token, err)
+			} else {
+				// 查询保存后的标记
+				saved := &model.MessageRecipient{}
+				database.DB.Where("message_id = ?", flagRequest.Id).
+					Where("recipient_id = ?", token).
+					First(saved)
+				result.Pinned = saved.Pinned
+				result.Starred = saved.Starred
+				result.Result = true
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	// 返回所有标记操作的结果
+	return results
+}
@@ -0,0 +1,118 @@
+package repository_test
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/testutil"
+	"slices"
+	"testing"
+)
+
+// createMessage 直接在数据库中创建一条消息，返回消息 ID
+func createMessage(t *testing.T, db *gorm.DB, senderId string, introducerIds ...string) string {
+	t.Helper()
+	// 包括已删除的消息，避免消息 ID 重复
+	var count int64
+	db.Unscoped().Model(&model.Message{}).Count(&count)
+	message := &model.Message{
+		MessageId:     fmt.Sprintf("%032d", count+1),
+		SenderIds:     model.StringArray{senderId},
+		Title:         "hello",
+		Content:       "hello",
+		Category:      "notice",
+		IntroducerIds: introducerIds,
+	}
+	if err := db.Create(message).Error; err != nil {
+		t.Fatalf("create message: %s", err)
+	}
+	return message.MessageId
+}
+
+func TestRecipientCheckIsConsistent(t *testing.T) {
+	db := testutil.Setup(t)
+
+	sender := "sender"
+	recipient := "abc_1"
+	// 接收者、发给所有人、只包含接收者子串、通配符可以匹配接收者、发送者自己的消息
+	exact := createMessage(t, db, sender, "other", recipient, "another")
+	broadcast := createMessage(t, db, sender)
+	substring := createMessage(t, db, sender, "xabc_1x", "abc_12")
+	wildcard := createMessage(t, db, sender, "abcx1")
+	own := createMessage(t, db, recipient, "other")
+	hidden := createMessage(t, db, sender, recipient)
+	repository.HideMessagesById(recipient, &[]request.MessageHideRequest{{MessageId: hidden}}, nil)
+
+	all := []string{exact, broadcast, substring, wildcard, own, hidden}
+	inbox := []string{exact, broadcast}
+	visible := []string{exact, broadcast, own}
+
+	// 单条查询是判断的基准，其他查询必须和它一致
+	for _, id := range all {
+		got := repository.QueryVisibleMessageById(recipient, id) != nil
+		if got != slices.Contains(visible, id) {
+			t.Fatalf("QueryVisibleMessageById(%s) = %t, want %t", id, got, !got)
+		}
+	}
+
+	var listed []string
+	for _, message := range repository.QueryMessagesByMessageTokenMessageRequest(recipient, &request.MessageRequest{}, nil) {
+		listed = append(listed, message.MessageId)
+	}
+	assertIds(t, "inbox", listed, inbox)
+
+	var searched []string
+	for _, message := range repository.SearchMessages(recipient, &request.MessageSearchRequest{Q: "hello"}, nil) {
+		searched = append(searched, message.MessageId)
+	}
+	assertIds(t, "search", searched, inbox)
+
+	var exported []string
+	err := repository.ExportMessages(recipient, nil, func(message *response.ExportedMessage) error {
+		exported = append(exported, message.MessageId)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportMessages: %s", err)
+	}
+	assertIds(t, "export", exported, visible)
+
+	// 发送者给所有消息添加同一个标签，接收者只统计可以看到的消息
+	var tagRequests []request.MessageTagRequest
+	for _, id := range all {
+		tagRequests = append(tagRequests, request.MessageTagRequest{Id: id, Tags: []string{"invoice"}})
+	}
+	repository.AddMessageTags(sender, &tagRequests, nil)
+	repository.AddMessageTags(recipient, &[]request.MessageTagRequest{{Id: own, Tags: []string{"invoice"}}}, nil)
+	tags := repository.QueryTags(recipient)
+	if len(tags) != 1 || tags[0].Count != int64(len(visible)) {
+		t.Fatalf("QueryTags = %+v, want invoice counted %d times", tags, len(visible))
+	}
+
+	// 只有接收者可以更新消息状态
+	var statusRequests []request.MessageStatusRequest
+	for _, id := range all {
+		statusRequests = append(statusRequests, request.MessageStatusRequest{Id: id, Status: model.Read})
+	}
+	var updated []string
+	for _, result := range repository.UpdateMessageStatus(recipient, &statusRequests, nil) {
+		if result.Result {
+			updated = append(updated, result.Id)
+		}
+	}
+	assertIds(t, "status", updated, []string{exact, broadcast, hidden})
+}
+
+// assertIds 比较查询到的消息 ID 和期望的消息 ID，不考虑顺序
+func assertIds(t *testing.T, name string, got []string, want []string) {
+	t.Helper()
+	slices.Sort(got)
+	want = slices.Clone(want)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/config"
+	"message/database"
+	"message/utils"
+	"strings"
+)
+
+// snippetRadius 摘要中关键字前后保留的字符数
+const snippetRadius = 30
+
+// SearchMessages 根据关键字全文搜索接收者的消息，按照相关度排序
+func SearchMessages(
+	// 消息凭证
+	token string,
+	// 搜索请求参数
+	searchRequest *request.MessageSearchRequest,
+	// 消息过滤器
+	filters []request.MessageFilterRequest,
+) []response.MessageSearchResult {
+	// 创建消息查询对象
+	query := database.DB.Model(&model.Message{})
+
+	// 与查询消息相同，只搜索当前接收者收件箱中的消息
+	query.Where(inboxCondition(token))
+
+	// 根据传入的过滤器条件进行进一步筛选
+	applyMessageFilters(query, token, filters)
+
+	// 添加全文检索条件，并按照相关度排序
+	query = database.MessageSearcher().Search(query, searchRequest.Q)
+
+	// 根据分页信息查询消息
+	maxLimit := config.AppConfig.API.MaxLimit
+	if searchRequest.Page == 0 {
+		searchRequest.Page = 1
+	}
+	results := make([]response.MessageSearchResult, 0)
+	query.Order("score desc").Order("created_at desc").
+		Limit(maxLimit).
+		Offset((searchRequest.Page - 1) * maxLimit).
+		Find(&results)
+
+	// 为每条消息生成高亮的摘要
+	keywords := strings.Fields(searchRequest.Q)
+	for i := range results {
+		// 先将详细内容渲染为请求的格式，再生成摘要
+		results[i].Render(searchRequest.Format)
+		snippets := map[string]string{}
+		for column, text := range map[string]string{
+			"title":       results[i].Title,
+			"content":     results[i].Content,
+			"big_content": results[i].BigContent,
+		} {
+			if snippet := utils.Highlight(text, keywords, snippetRadius); snippet != "" {
+				snippets[column] = snippet
+			}
+		}
+		results[i].Snippets = snippets
+	}
+
+	// 返回搜索到的消息
+	return results
+}
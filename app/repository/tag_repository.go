@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/database"
+	"message/logs"
+	"slices"
+)
+
+// visibleTags 限制只查询发送者设置的标签和 token 自己的标签，token 为空时不限制
+func visibleTags(query *gorm.DB, token string) *gorm.DB {
+	if token == "" {
+		return query
+	}
+	return query.Where(
+		database.DB.Where("tag.owner_id = ?", "").Or("tag.owner_id = ?", token),
+	)
+}
+
+// taggedMessageIds 返回带有任意一个标签的消息 ID 子查询
+func taggedMessageIds(token string, names []string) *gorm.DB {
+	query := database.DB.Model(&model.MessageTag{}).
+		Select("message_tag.message_id").
+		Joins("JOIN tag ON tag.id = message_tag.tag_id").
+		Where("tag.name IN ?", names)
+	return visibleTags(query, token)
+}
+
+// messageTags 查询消息可以看到的标签，返回消息 ID 到标签名称的映射
+func messageTags(db *gorm.DB, token string, messageIds []string) map[string][]string {
+	tags := make(map[string][]string)
+	if len(messageIds) == 0 {
+		return tags
+	}
+
+	var rows []struct {
+		MessageId string
+		Name      string
+	}
+	query := db.Model(&model.MessageTag{}).
+		Select("message_tag.message_id, tag.name").
+		Joins("JOIN tag ON tag.id = message_tag.tag_id").
+		Where("message_tag.message_id IN ?", messageIds)
+	visibleTags(query, token).Order("tag.name asc").Scan(&rows)
+
+	for _, row := range rows {
+		// 发送者的标签和自己的标签可能同名，只返回一次
+		if !slices.Contains(tags[row.MessageId], row.Name) {
+			tags[row.MessageId] = append(tags[row.MessageId], row.Name)
+		}
+	}
+	return tags
+}
+
+// AddMessageTags 根据消息 ID 批量添加标签，标签不存在时自动创建
+func AddMessageTags(
+	// 消息凭证
+	token string,
+	// 要添加的标签请求切片
+	tagRequests *[]request.MessageTagRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageTagResponse {
+	return updateMessageTags(token, tagRequests, auditor, func(tx *gorm.DB, messageId string, tags []model.Tag) error {
+		for _, tag := range tags {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.MessageTag{MessageId: messageId, TagId: tag.ID}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMessageTags 根据消息 ID 批量移除标签
+func RemoveMessageTags(
+	// 消息凭证
+	token string,
+	// 要移除的标签请求切片
+	tagRequests *[]request.MessageTagRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+) []response.MessageTagResponse {
+	return updateMessageTags(token, tagRequests, auditor, func(tx *gorm.DB, messageId string, tags []model.Tag) error {
+		tagIds := make([]uint, 0, len(tags))
+		for _, tag := range tags {
+			tagIds = append(tagIds, tag.ID)
+		}
+		return tx.Where("message_id = ?", messageId).
+			Where("tag_id IN ?", tagIds).
+			Delete(&model.MessageTag{}).Error
+	})
+}
+
+// updateMessageTags 检查权限后在事务中修改每条消息的标签，并返回修改后可以看到的标签
+//
+// 发送者的标签只有发送者可以修改，自己的标签发送者和没有删除消息的接收者都可以修改。
+func updateMessageTags(
+	// 消息凭证
+	token string,
+	// 标签请求切片
+	tagRequests *[]request.MessageTagRequest,
+	// 审计日志的操作者
+	auditor *Auditor,
+	// 修改消息标签的方法
+	update func(tx *gorm.DB, messageId string, tags []model.Tag) error,
+) []response.MessageTagResponse {
+	results := make([]response.MessageTagResponse, 0)
+
+	for _, tagRequest := range *tagRequests {
+		result := response.MessageTagResponse{
+			Id: tagRequest.Id,
+		}
+
+		message := QueryVisibleMessageById(token, tagRequest.Id)
+		if message != nil && (tagRequest.Personal || slices.Contains(message.SenderIds, token)) {
+			ownerId := ""
+			if tagRequest.Personal {
+				ownerId = token
+			}
+
+			err := database.DB.Transaction(func(tx *gorm.DB) error {
+				tags, err := findOrCreateTags(tx, ownerId, tagRequest.Tags)
+				if err != nil {
+					return err
+				}
+				if err := update(tx, tagRequest.Id, tags); err != nil {
+					return err
+				}
+				// 审计日志记录修改后可以看到的标签
+				result.Tags = messageTags(tx, token, []string{tagRequest.Id})[tagRequest.Id]
+				return auditor.record(tx, model.AuditTag, []string{tagRequest.Id}, model.AuditChanges{
+					"tags": {After: result.Tags},
+				})
+			})
+			if err != nil {
+				logs.LogError.Errorf("updateMessageTags %s %s %s", tagRequest.Id, token, err)
+			} else {
+				result.Result = true
+			}
+		}
+
+		if !result.Result {
+			result.Tags = messageTags(database.DB, token, []string{tagRequest.Id})[tagRequest.Id]
+		}
+		if result.Tags == nil {
+			result.Tags = make([]string, 0)
+		}
+		results = append(results, result)
+	}
+
+	// 返回所有标签操作的结果
+	return results
+}
+
+// findOrCreateTags 查询所有者的标签，不存在的标签自动创建
+func findOrCreateTags(tx *gorm.DB, ownerId string, names []string) ([]model.Tag, error) {
+	for _, name := range names {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.Tag{Name: name, OwnerId: ownerId}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tags []model.Tag
+	err := tx.Where("owner_id = ?", ownerId).Where("name IN ?", names).Find(&tags).Error
+	return tags, err
+}
+
+// QueryTags 查询 token 可以看到的标签以及使用每个标签的消息数量
+//
+// 只统计 token 发送的消息和 token 作为接收者没有删除的消息。
+func QueryTags(token string) []response.TagCount {
+	query := database.DB.Model(&model.Tag{}).
+		Select(
+			"tag.name, tag.owner_id <> ? AS personal, COUNT(DISTINCT message_tag.message_id) AS count",
+			"",
+		).
+		Joins("JOIN message_tag ON message_tag.tag_id = tag.id").
+		Joins("JOIN message ON message.message_id = message_tag.message_id AND message.deleted_at IS NULL").
+		Where(visibleCondition(token))
+
+	tags := make([]response.TagCount, 0)
+	visibleTags(query, token).
+		Group("tag.name, tag.owner_id").
+		Order("count desc").
+		Order("tag.name asc").
+		Scan(&tags)
+	return tags
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"message/app/model"
+	"message/app/response"
+	"message/database"
+	"slices"
+)
+
+// createMessageVersion 在事务中保存消息的一个历史版本
+func createMessageVersion(tx *gorm.DB, message *model.Message) error {
+	return tx.Create(&model.MessageVersion{
+		MessageId:     message.MessageId,
+		Version:       message.Version,
+		Title:         message.Title,
+		Content:       message.Content,
+		Category:      message.Category,
+		BigContent:    message.BigContent,
+		ContentType:   message.ContentType,
+		IntroducerIds: message.IntroducerIds,
+		Data:          message.Data,
+		Actions:       message.Actions,
+		Priority:      message.Priority,
+		EditedAt:      message.UpdatedAt,
+	}).Error
+}
+
+// QueryVisibleMessageById 通过消息 ID 查询发送者或接收者可以看到的消息
+func QueryVisibleMessageById(
+	// 消息凭证
+	token string,
+	// 消息 ID
+	id string,
+) *model.Message {
+	message := &model.Message{}
+	result := database.DB.Where("message_id = ?", id).First(message)
+	// 如果查询出错或者没有匹配到数据，则返回 nil
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+
+	// 发送者可以看到自己的消息
+	if slices.Contains(message.SenderIds, token) {
+		return message
+	}
+
+	// 接收者可以看到没有删除的消息
+	if !isMessageRecipient(message, token) {
+		return nil
+	}
+	var hidden int64
+	hiddenMessageIds(token).Where("message_id = ?", id).Count(&hidden)
+	if hidden != 0 {
+		return nil
+	}
+
+	return message
+}
+
+// QueryMessageHistory 查询消息的所有版本，按照版本号从小到大排序，最后一个是当前版本
+func QueryMessageHistory(message *model.Message) []response.MessageVersion {
+	var versions []model.MessageVersion
+	database.DB.Where("message_id = ?", message.MessageId).Order("version asc").Find(&versions)
+
+	history := make([]response.MessageVersion, 0, len(versions)+1)
+	for _, version := range versions {
+		history = append(history, response.MessageVersion{
+			Version:       version.Version,
+			Title:         version.Title,
+			Content:       version.Content,
+			Category:      version.Category,
+			BigContent:    version.BigContent,
+			ContentType:   version.ContentType,
+			IntroducerIds: version.IntroducerIds,
+			Data:          version.Data,
+			Actions:       version.Actions,
+			Priority:      version.Priority,
+			EditedAt:      version.EditedAt,
+		})
+	}
+
+	// 添加当前版本
+	history = append(history, response.MessageVersion{
+		Version:       message.Version,
+		Title:         message.Title,
+		Content:       message.Content,
+		Category:      message.Category,
+		BigContent:    message.BigContent,
+		ContentType:   message.ContentType,
+		IntroducerIds: message.IntroducerIds,
+		Data:          message.Data,
+		Actions:       message.Actions,
+		Priority:      message.Priority,
+		EditedAt:      message.UpdatedAt,
+	})
+
+	return history
+}
+
+// QueryMessageVersion 查询消息的指定版本，version 为 0 时返回当前版本，找不到时返回 nil
+func QueryMessageVersion(message *model.Message, version uint) *response.Message {
+	newMessage := response.NewMessage(message)
+	if version == 0 || version == message.Version {
+		return newMessage
+	}
+
+	messageVersion := &model.MessageVersion{}
+	result := database.DB.
+		Where("message_id = ?", message.MessageId).
+		Where("version = ?", version).
+		First(messageVersion)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+
+	// 使用历史版本的内容替换当前版本的内容
+	newMessage.Title = messageVersion.Title
+	newMessage.Content = messageVersion.Content
+	newMessage.Category = messageVersion.Category
+	newMessage.BigContent = messageVersion.BigContent
+	newMessage.ContentType = messageVersion.ContentType
+	newMessage.IntroducerIds = messageVersion.IntroducerIds
+	newMessage.Data = messageVersion.Data
+	newMessage.Actions = messageVersion.Actions
+	newMessage.Priority = messageVersion.Priority
+	newMessage.Version = messageVersion.Version
+	newMessage.Edited = messageVersion.Version > 1
+	newMessage.UpdatedAt = messageVersion.EditedAt
+	return newMessage
+}
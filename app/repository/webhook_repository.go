@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"gorm.io/gorm/clause"
+	"message/app/model"
+	"message/app/request"
+	"message/database"
+)
+
+// QueryWebhook 查询发送者注册的 Webhook，没有注册时返回 nil
+func QueryWebhook(senderId string) *model.Webhook {
+	webhook := &model.Webhook{}
+	if err := database.DB.Where("sender_id = ?", senderId).Take(webhook).Error; err != nil {
+		return nil
+	}
+	return webhook
+}
+
+// SaveWebhook 注册或替换发送者的 Webhook
+func SaveWebhook(senderId string, webhookRequest *request.WebhookRequest) (*model.Webhook, error) {
+	webhook := &model.Webhook{
+		SenderId: senderId,
+		Url:      webhookRequest.Url,
+		Secret:   webhookRequest.Secret,
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sender_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "secret", "updated_at"}),
+	}).Create(webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return QueryWebhook(senderId), nil
+}
+
+// DeleteWebhook 删除发送者的 Webhook，返回是否删除了记录
+func DeleteWebhook(senderId string) (bool, error) {
+	result := database.DB.Where("sender_id = ?", senderId).Delete(&model.Webhook{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ResolveWebhook 返回发送者的 Webhook 地址和签名密钥，没有注册时返回 false，用于发送事件
+func ResolveWebhook(senderId string) (string, string, bool) {
+	webhook := QueryWebhook(senderId)
+	if webhook == nil {
+		return "", "", false
+	}
+	return webhook.Url, webhook.Secret, true
+}
@@ -0,0 +1,40 @@
+package request
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/logs"
+)
+
+type AdminMessageRequest struct {
+	MessageRequest
+	Deleted bool `description:"是否查询已软删除的消息" form:"deleted" example:"false"`
+}
+
+// ValidateAdminMessageRequestMiddleware 用于验证管理员查询消息请求参数的中间件
+func ValidateAdminMessageRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		message := &AdminMessageRequest{}
+		if !validateStructAndSetContext(
+			ctx,
+			message,
+			"adminMessage",
+		) {
+			logs.LogInfo.Infof("ValidateAdminMessageRequestMiddleware-参数错误 %s", messageToken)
+			return
+		}
+
+		if message.Filter != "" {
+			if !validateFiltersAndSetContext(ctx, message.Filter) {
+				logs.LogInfo.Infof("ValidateAdminMessageRequestMiddleware-失败-查询过滤语法 %s", messageToken)
+				return
+			}
+		}
+
+		logs.LogInfo.Infof("ValidateAdminMessageRequestMiddleware-成功 %s", messageToken)
+	}
+}
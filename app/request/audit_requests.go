@@ -0,0 +1,36 @@
+package request
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/logs"
+	"time"
+)
+
+type AuditRequest struct {
+	Actor     string    `description:"操作者的凭证" form:"actor" validate:"omitempty,max=32" example:"2f14ec370621a8be08c8f0ece459e7e0"`
+	Action    string    `description:"操作类型" form:"action" validate:"omitempty,oneof=create update status delete restore hide retract flag tag action import" example:"update"`
+	MessageId string    `description:"消息id" form:"messageId" validate:"omitempty,len=32" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
+	From      time.Time `description:"开始时间" form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-02-15T00:00:00Z"`
+	To        time.Time `description:"结束时间" form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-02-16T00:00:00Z"`
+	Page      int       `description:"查询第几页" form:"page" validate:"omitempty,min=1,max=99999999" example:"1"`
+}
+
+// ValidateAuditRequestMiddleware 用于验证审计日志请求参数的中间件
+func ValidateAuditRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateStructAndSetContext(
+			ctx,
+			&AuditRequest{},
+			"audit",
+		) {
+			logs.LogInfo.Infof("ValidateAuditRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateAuditRequestMiddleware-成功 %s", messageToken)
+	}
+}
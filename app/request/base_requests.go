@@ -2,7 +2,6 @@ package request
 
 import (
 	"errors"
-	lang "github.com/gin-contrib/i18n"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"message/app/response"
@@ -27,11 +26,7 @@ func validateSliceAndSetContext(ctx *gin.Context, object interface{}, saveKey st
 	// 检查并绑定 JSON 数据到对象
 	if err := ctx.ShouldBindJSON(object); err != nil {
 		logs.LogError.Errorf("validateSliceAndSetContext %s %s %s", saveKey, object, err)
-		response.NewError(
-			ctx,
-			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
-		)
+		handlingBindError(ctx, err)
 		return false
 	}
 
@@ -41,9 +36,8 @@ func validateSliceAndSetContext(ctx *gin.Context, object interface{}, saveKey st
 		return false
 	}
 
-	// 将校验通过的数据存储到 Gin 上下文中
+	// 将校验通过的数据存储到 Gin 上下文中，由调用方继续处理后续请求
 	ctx.Set(saveKey, object)
-	ctx.Next()
 	return true
 }
 
@@ -52,11 +46,7 @@ func validateStructAndSetContext(ctx *gin.Context, object interface{}, saveKey s
 	// 检查并绑定数据到对象
 	if err := ctx.ShouldBind(object); err != nil {
 		logs.LogError.Errorf("validateStructAndSetContext %s %s %s", saveKey, object, err)
-		response.NewError(
-			ctx,
-			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
-		)
+		handlingBindError(ctx, err)
 		return false
 	}
 
@@ -66,9 +56,8 @@ func validateStructAndSetContext(ctx *gin.Context, object interface{}, saveKey s
 		return false
 	}
 
-	// 将校验通过的数据存储到 Gin 上下文中
+	// 将校验通过的数据存储到 Gin 上下文中，由调用方继续处理后续请求
 	ctx.Set(saveKey, object)
-	ctx.Next()
 	return true
 }
 
@@ -81,12 +70,42 @@ func HandlingValidateErrors(ctx *gin.Context, err error) {
 		response.NewError(
 			ctx,
 			http.StatusBadGateway,
-			lang.MustGetMessage(ctx, "badGateway"),
+			response.ErrInternal,
 		)
 		ctx.Abort()
 		return
 	}
 
+	// 返回校验错误信息给客户端
+	handlingValidationErrors(ctx, ValidationErrors(err))
+}
+
+// handlingValidationErrors 返回校验错误信息，旧接口直接返回校验错误数组
+func handlingValidationErrors(ctx *gin.Context, errorValidations []ValidationError) {
+	if response.Enveloped(ctx) {
+		response.NewErrorDetails(ctx, http.StatusBadRequest, response.ErrValidationFailed, errorValidations)
+	} else {
+		ctx.JSON(http.StatusBadRequest, errorValidations)
+	}
+	ctx.Abort()
+}
+
+// handlingBindError 处理请求参数无法解析的错误，/v1 接口返回 400，旧接口兼容原来的 502
+func handlingBindError(ctx *gin.Context, err error) {
+	if response.Enveloped(ctx) {
+		response.NewErrorDetails(ctx, http.StatusBadRequest, response.ErrInvalidRequest, err.Error())
+	} else {
+		response.NewError(
+			ctx,
+			http.StatusBadGateway,
+			response.ErrInternal,
+		)
+	}
+	ctx.Abort()
+}
+
+// ValidationErrors 将校验错误转换为返回给客户端的校验错误信息
+func ValidationErrors(err error) []ValidationError {
 	var errorValidations []ValidationError
 	for _, err := range err.(validator.ValidationErrors) {
 		errorValidations = append(errorValidations, ValidationError{
@@ -97,8 +116,5 @@ func HandlingValidateErrors(ctx *gin.Context, err error) {
 			Message: err.Tag(),
 		})
 	}
-
-	// 返回校验错误信息给客户端
-	ctx.JSON(http.StatusBadRequest, errorValidations)
-	ctx.Abort()
+	return errorValidations
 }
@@ -0,0 +1,110 @@
+package request_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"message/app/request"
+	"message/logs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newValidateRouter 创建只有验证中间件和 handler 的路由，handler 执行时记录上下文中的值
+func newValidateRouter(middleware gin.HandlerFunc, handler gin.HandlerFunc) *gin.Engine {
+	logs.LogInfo = zap.NewNop().Sugar()
+	logs.LogError = zap.NewNop().Sugar()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Any("/", func(ctx *gin.Context) {
+		ctx.Set("token", strings.Repeat("0", 32))
+	}, middleware, handler)
+	return r
+}
+
+func TestValidateMessageRequestMiddlewareSetsFiltersBeforeHandler(t *testing.T) {
+	calls := 0
+	r := newValidateRouter(request.ValidateMessageRequestMiddleware(), func(ctx *gin.Context) {
+		calls++
+		if _, ok := ctx.Get("message"); !ok {
+			t.Error("handler ran before message was set")
+		}
+		if _, ok := ctx.Get("messageFilters"); !ok {
+			t.Error("handler ran before messageFilters was set")
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?filter=status+%3D+1", nil))
+	if w.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("GET with a filter = %d, handler calls %d, want 200 and 1", w.Code, calls)
+	}
+}
+
+func TestValidateSliceMiddlewareRunsHandlerOnce(t *testing.T) {
+	calls := 0
+	r := newValidateRouter(request.ValidateMessageStatusRequestMiddleware(), func(ctx *gin.Context) {
+		calls++
+		if _, ok := ctx.Get("messageStatus"); !ok {
+			t.Error("handler ran before messageStatus was set")
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	body := `[{"id": "` + strings.Repeat("a", 32) + `", "status": 1}]`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)))
+	if w.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("PUT status = %d, handler calls %d, want 200 and 1", w.Code, calls)
+	}
+}
+
+func TestValidateMessageSearchRequestMiddlewareTrimsQ(t *testing.T) {
+	var q string
+	r := newValidateRouter(request.ValidateMessageSearchRequestMiddleware(), func(ctx *gin.Context) {
+		search, _ := ctx.Get("messageSearch")
+		q = search.(*request.MessageSearchRequest).Q
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?q=+%E6%A0%87%E9%A2%98%09", nil))
+	if w.Code != http.StatusOK || q != "标题" {
+		t.Fatalf("GET with a padded q = %d %q, want 200 and the trimmed keyword", w.Code, q)
+	}
+
+	// 只有空白的关键字返回 400，不会执行空的全文检索
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?q=+%09+", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("GET with a blank q = %d, want 400", w.Code)
+	}
+}
+
+func TestValidateWebhookRequestMiddlewareRejectsInternalUrl(t *testing.T) {
+	r := newValidateRouter(request.ValidateWebhookRequestMiddleware(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	for url, want := range map[string]int{
+		"https://example.com/webhook":     http.StatusOK,
+		"http://localhost:8080/webhook":   http.StatusBadRequest,
+		"http://127.0.0.1/webhook":        http.StatusBadRequest,
+		"http://10.0.0.1/webhook":         http.StatusBadRequest,
+		"http://169.254.169.254/metadata": http.StatusBadRequest,
+		"http://[::1]/webhook":            http.StatusBadRequest,
+		"http://[::ffff:192.168.1.1]/":    http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		body := strings.NewReader(`{"url":"` + url + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", body)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("PUT %s = %d, want %d", url, w.Code, want)
+		}
+	}
+}
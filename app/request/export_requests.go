@@ -0,0 +1,313 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"message/logs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 定义导出和导入格式的常量
+const (
+	FormatJSONL = "jsonl" // 每行一条 JSON 数据
+	FormatCSV   = "csv"   // CSV，第一行为表头
+)
+
+type MessageExportRequest struct {
+	Format string `description:"导出格式（jsonl/csv），为空时为 jsonl" form:"format" validate:"omitempty,oneof=jsonl csv" example:"csv"`
+	Filter string `description:"过滤的语句" form:"filter" example:"status = 1"`
+}
+
+// ValidateMessageExportRequestMiddleware 用于验证导出消息请求参数的中间件
+func ValidateMessageExportRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		message := &MessageExportRequest{}
+		if !validateStructAndSetContext(
+			ctx,
+			message,
+			"messageExport",
+		) {
+			logs.LogInfo.Infof("ValidateMessageExportRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+
+		if message.Filter != "" {
+			if !validateFiltersAndSetContext(ctx, message.Filter) {
+				logs.LogInfo.Infof("ValidateMessageExportRequestMiddleware-失败-查询过滤语法 %s", messageToken)
+				return
+			}
+		}
+
+		logs.LogInfo.Infof("ValidateMessageExportRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+type MessageImportRequest struct {
+	Format string `description:"导入格式（jsonl/csv），为空时为 jsonl" form:"format" validate:"omitempty,oneof=jsonl csv" example:"csv"`
+	DryRun bool   `description:"如果为true表示只校验数据，不写入数据库" form:"dryRun" example:"true"`
+}
+
+// ValidateMessageImportRequestMiddleware 用于验证导入消息请求参数的中间件，请求体在导入时逐条读取
+func ValidateMessageImportRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 检查并绑定查询参数，请求体是导入的数据，不能绑定
+		message := &MessageImportRequest{}
+		if err := ctx.ShouldBindQuery(message); err != nil {
+			logs.LogError.Errorf("ValidateMessageImportRequestMiddleware %s %s", message, err)
+			handlingBindError(ctx, err)
+			return
+		}
+
+		if err := Validate.Struct(message); err != nil {
+			HandlingValidateErrors(ctx, err)
+			logs.LogInfo.Infof("ValidateMessageImportRequestMiddleware-失败-参数错误")
+			return
+		}
+
+		ctx.Set("messageImport", message)
+		logs.LogInfo.Infof("ValidateMessageImportRequestMiddleware-成功")
+	}
+}
+
+// MessageImportRecord 导入的一条消息，字段与导出的消息相同
+type MessageImportRecord struct {
+	MessageId     string                   `json:"message_id" validate:"required,len=32"`
+	SenderIds     []string                 `json:"sender_ids" validate:"required,gt=0,dive,required,max=32"`
+	IntroducerIds []string                 `json:"introducer_ids" validate:"omitempty,dive,max=32"`
+	Title         string                   `json:"title" validate:"required,max=25"`
+	Content       string                   `json:"content" validate:"required,max=50"`
+	Category      string                   `json:"category" validate:"required,max=50"`
+	BigContent    string                   `json:"big_content" validate:"required"`
+	ContentType   string                   `json:"content_type" validate:"omitempty,oneof=text markdown html"`
+	Data          map[string]interface{}   `json:"data" validate:"omitempty,jsonmax=4096"`
+	Actions       []MessageActionRequest   `json:"actions" validate:"omitempty,max=5,unique=Id,dive"`
+	Status        uint8                    `json:"status" validate:"max=2"`
+	Priority      string                   `json:"priority" validate:"omitempty,oneof=low normal high urgent"`
+	Version       uint                     `json:"version" validate:"omitempty,min=1"`
+	Edited        bool                     `json:"edited"`
+	CreatedAt     time.Time                `json:"created_at" validate:"required"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	Recipients    []MessageRecipientRecord `json:"recipients" validate:"omitempty,unique=RecipientId,dive"`
+}
+
+// MessageRecipientRecord 导入的一个接收者的状态，字段与导出的接收者状态相同
+type MessageRecipientRecord struct {
+	RecipientId string    `json:"recipient_id" validate:"required,max=32"`
+	Status      uint8     `json:"status" validate:"max=2"`
+	Hidden      bool      `json:"hidden"`
+	Pinned      bool      `json:"pinned"`
+	Starred     bool      `json:"starred"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ReadMessageImport 从 reader 中逐条读取导入的消息并校验，每读取一条调用一次 handle
+//
+// 解析或校验失败时 record 可能为 nil，errors 为失败的原因。读取数据或 handle 出错时停止读取并返回错误。
+func ReadMessageImport(
+	// 导入的数据
+	reader io.Reader,
+	// 导入格式
+	format string,
+	// 处理每条消息的函数，line 为数据所在的行号
+	handle func(line int, record *MessageImportRecord, errors []ValidationError) error,
+) error {
+	if format == FormatCSV {
+		return readMessageImportCSV(reader, handle)
+	}
+	return readMessageImportJSONL(reader, handle)
+}
+
+// readMessageImportJSONL 逐行读取 JSON Lines 格式的消息，跳过空行
+func readMessageImportJSONL(
+	reader io.Reader,
+	handle func(line int, record *MessageImportRecord, errors []ValidationError) error,
+) error {
+	buffered := bufio.NewReader(reader)
+	for line := 1; ; line++ {
+		data, err := buffered.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			record := &MessageImportRecord{}
+			if jsonErr := json.Unmarshal(data, record); jsonErr != nil {
+				err := handle(line, nil, []ValidationError{{Message: "json", Param: jsonErr.Error()}})
+				if err != nil {
+					return err
+				}
+			} else if err := handle(line, record, validateMessageImportRecord(record)); err != nil {
+				return err
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
+
+// readMessageImportCSV 读取 CSV 格式的消息，第一行为表头，列的顺序可以和导出时不同
+func readMessageImportCSV(
+	reader io.Reader,
+	handle func(line int, record *MessageImportRecord, errors []ValidationError) error,
+) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(header, "message_id") {
+		return handle(1, nil, []ValidationError{{Field: "message_id", Message: "required"}})
+	}
+
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			// 格式错误的行跳过，继续读取下一行
+			err = handle(line, nil, []ValidationError{{Message: "csv", Param: parseError.Err.Error()}})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		record, errorValidations := parseMessageImportCSV(header, row)
+		if len(errorValidations) == 0 {
+			errorValidations = validateMessageImportRecord(record)
+		}
+		if err := handle(line, record, errorValidations); err != nil {
+			return err
+		}
+	}
+}
+
+// parseMessageImportCSV 根据表头将 CSV 的一行转换为导入的消息
+func parseMessageImportCSV(header []string, row []string) (*MessageImportRecord, []ValidationError) {
+	record := &MessageImportRecord{}
+	var errorValidations []ValidationError
+	invalid := func(column string, value string) {
+		errorValidations = append(errorValidations, ValidationError{
+			Field:   column,
+			Type:    "string",
+			Value:   value,
+			Message: "invalid",
+		})
+	}
+
+	for i, column := range header {
+		if i >= len(row) {
+			break
+		}
+		value := row[i]
+		switch column {
+		case "message_id":
+			record.MessageId = value
+		case "sender_ids":
+			record.SenderIds = splitIds(value)
+		case "introducer_ids":
+			record.IntroducerIds = splitIds(value)
+		case "title":
+			record.Title = value
+		case "content":
+			record.Content = value
+		case "category":
+			record.Category = value
+		case "big_content":
+			record.BigContent = value
+		case "content_type":
+			record.ContentType = value
+		case "data":
+			if value != "" && json.Unmarshal([]byte(value), &record.Data) != nil {
+				invalid(column, value)
+			}
+		case "actions":
+			if value != "" && json.Unmarshal([]byte(value), &record.Actions) != nil {
+				invalid(column, value)
+			}
+		case "recipients":
+			if value != "" && json.Unmarshal([]byte(value), &record.Recipients) != nil {
+				invalid(column, value)
+			}
+		case "status":
+			number, err := strconv.ParseUint(value, 10, 8)
+			if value != "" && err != nil {
+				invalid(column, value)
+			}
+			record.Status = uint8(number)
+		case "priority":
+			record.Priority = value
+		case "version":
+			number, err := strconv.ParseUint(value, 10, 32)
+			if value != "" && err != nil {
+				invalid(column, value)
+			}
+			record.Version = uint(number)
+		case "edited":
+			edited, err := strconv.ParseBool(value)
+			if value != "" && err != nil {
+				invalid(column, value)
+			}
+			record.Edited = edited
+		case "created_at", "updated_at":
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				invalid(column, value)
+			} else if column == "created_at" {
+				record.CreatedAt = parsed
+			} else {
+				record.UpdatedAt = parsed
+			}
+		}
+	}
+	return record, errorValidations
+}
+
+// splitIds 将逗号连接的 ID 转换为切片，为空时返回 nil
+func splitIds(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// validateMessageImportRecord 校验导入的消息，返回校验失败的原因
+func validateMessageImportRecord(record *MessageImportRecord) []ValidationError {
+	if err := Validate.Struct(record); err != nil {
+		var invalidValidationError *validator.InvalidValidationError
+		if errors.As(err, &invalidValidationError) {
+			return []ValidationError{{Message: err.Error()}}
+		}
+		return ValidationErrors(err)
+	}
+	return nil
+}
@@ -1,22 +1,91 @@
 package request
 
 import (
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+	"message/app/model"
 	"message/logs"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 type MessageFilterRequest struct {
-	Column     string `description:"过滤的列" validate:"required,oneof=created_at updated_at sender_ids title content category big_content introducer_ids status"`
+	Column     string `description:"过滤的列" validate:"required,oneof=created_at updated_at sender_ids title content category big_content introducer_ids status priority pinned starred tag"`
 	Comparison string `description:"比较" validate:"required,oneof=> = < >= <= != like in"`
 	Value      string `description:"过滤的值" validate:"required"`
 }
 
+// RecipientFlagColumns 接收者自己的标记列，只能比较是否相等，值为布尔值
+var RecipientFlagColumns = []string{"pinned", "starred"}
+
+func init() {
+	Validate.RegisterStructValidation(validateMessageFilter, MessageFilterRequest{})
+	Validate.RegisterValidation("jsonmax", validateJSONMax)
+	Validate.RegisterValidation("notblank", validators.NotBlank)
+}
+
+// validateJSONMax 校验字段序列化为 JSON 后的字节数不超过参数
+func validateJSONMax(fl validator.FieldLevel) bool {
+	size, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	value, err := json.Marshal(fl.Field().Interface())
+	return err == nil && len(value) <= size
+}
+
+// ValidateMessageData 校验合并后的结构化数据，和创建消息时的限制相同
+func ValidateMessageData(data map[string]interface{}) error {
+	return Validate.Struct(&struct {
+		Data map[string]interface{} `validate:"omitempty,jsonmax=4096"`
+	}{Data: data})
+}
+
+// validateMessageFilter 校验接收者标记列、标签和优先级的比较和值
+func validateMessageFilter(sl validator.StructLevel) {
+	filter := sl.Current().Interface().(MessageFilterRequest)
+	if filter.Column == "priority" {
+		// 优先级使用名称比较，like 没有意义
+		if filter.Comparison == "like" {
+			sl.ReportError(filter.Comparison, "Comparison", "Comparison", "oneof", "> = < >= <= != in")
+		}
+		for _, name := range strings.Split(filter.Value, "|") {
+			if _, ok := model.ParsePriority(name); !ok {
+				sl.ReportError(filter.Value, "Value", "Value", "oneof", strings.Join(model.PriorityNames, " "))
+				break
+			}
+		}
+		return
+	}
+	if filter.Column == "tag" {
+		// 标签只能比较是否相等或者在多个标签中
+		if filter.Comparison != "=" && filter.Comparison != "!=" && filter.Comparison != "in" {
+			sl.ReportError(filter.Comparison, "Comparison", "Comparison", "oneof", "= != in")
+		}
+		return
+	}
+	if !slices.Contains(RecipientFlagColumns, filter.Column) {
+		return
+	}
+	if filter.Comparison != "=" && filter.Comparison != "!=" {
+		sl.ReportError(filter.Comparison, "Comparison", "Comparison", "oneof", "= !=")
+	}
+	if _, err := strconv.ParseBool(filter.Value); err != nil {
+		sl.ReportError(filter.Value, "Value", "Value", "boolean", "")
+	}
+}
+
 type MessageRequest struct {
-	Filter     string `description:"过滤的语句" form:"filter" example:"status = 1"`
-	SortColumn string `description:"排序列" form:"sortColumn" validate:"omitempty,oneof=created_at updated_at sender_ids title content category big_content introducer_ids status" example:"title"`
-	SortType   string `description:"排序类型" form:"sortType" validate:"omitempty,oneof=desc asc" example:"asc"`
-	Page       int    `description:"查询第几页" form:"page" validate:"omitempty,min=1,max=99999999" example:"1"`
+	Filter      string `description:"过滤的语句" form:"filter" example:"status = 1"`
+	SortColumn  string `description:"排序列" form:"sortColumn" validate:"omitempty,oneof=created_at updated_at sender_ids title content category big_content introducer_ids status priority" example:"title"`
+	SortType    string `description:"排序类型" form:"sortType" validate:"omitempty,oneof=desc asc" example:"asc"`
+	Page        int    `description:"查询第几页" form:"page" validate:"omitempty,min=1,max=99999999" example:"1"`
+	PinnedFirst bool   `description:"置顶的消息排在最前面" form:"pinnedFirst" example:"true"`
+	Format      string `description:"复杂消息的返回格式（html/text），为空时原样返回" form:"format" validate:"omitempty,oneof=html text" example:"text"`
 }
 
 // ValidateMessageRequestMiddleware 用于验证消息请求参数的中间件
@@ -38,53 +107,120 @@ func ValidateMessageRequestMiddleware() gin.HandlerFunc {
 		}
 
 		if message.Filter != "" {
-			var filters []MessageFilterRequest
-			for _, filterStr := range strings.Split(message.Filter, ",") {
-				filter := strings.SplitN(filterStr, " ", 3)
-				switch len(filter) {
-				case 1:
-					filters = append(filters, MessageFilterRequest{
-						Column: filter[0],
-					})
-					break
-				case 2:
-					filters = append(filters, MessageFilterRequest{
-						Column:     filter[0],
-						Comparison: filter[1],
-					})
-					break
-				case 3:
-					filters = append(filters, MessageFilterRequest{
-						Column:     filter[0],
-						Comparison: filter[1],
-						Value:      filter[2],
-					})
-					break
-				default:
-					filters = append(filters, MessageFilterRequest{})
-					break
-				}
-			}
-
-			if err := Validate.Var(&filters, "required,gt=0,dive,required"); err != nil {
-				HandlingValidateErrors(ctx, err)
+			if !validateFiltersAndSetContext(ctx, message.Filter) {
 				logs.LogInfo.Infof("ValidateMessageRequestMiddleware-失败-查询过滤语法 %s", messageToken)
 				return
 			}
-			ctx.Set("messageFilters", &filters)
-			ctx.Next()
 		}
 
 		logs.LogInfo.Infof("ValidateMessageRequestMiddleware-成功 %s", messageToken)
 	}
 }
 
+type MessageSearchRequest struct {
+	Q      string `description:"搜索的关键字，去掉首尾的空白后不能为空" form:"q" validate:"required,notblank,max=100" example:"标题"`
+	Filter string `description:"过滤的语句" form:"filter" example:"status = 1"`
+	Page   int    `description:"查询第几页" form:"page" validate:"omitempty,min=1,max=99999999" example:"1"`
+	Format string `description:"复杂消息的返回格式（html/text），为空时原样返回" form:"format" validate:"omitempty,oneof=html text" example:"text"`
+}
+
+// ValidateMessageSearchRequestMiddleware 用于验证搜索消息请求参数的中间件
+func ValidateMessageSearchRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		message := &MessageSearchRequest{}
+		if !validateStructAndSetContext(
+			ctx,
+			message,
+			"messageSearch",
+		) {
+			logs.LogInfo.Infof("ValidateMessageSearchRequestMiddleware-参数错误 %s", messageToken)
+			return
+		}
+		// 只有空白的关键字在全文索引中是空的查询条件，校验时已经拒绝，这里去掉首尾的空白
+		message.Q = strings.TrimSpace(message.Q)
+
+		if message.Filter != "" {
+			if !validateFiltersAndSetContext(ctx, message.Filter) {
+				logs.LogInfo.Infof("ValidateMessageSearchRequestMiddleware-失败-查询过滤语法 %s", messageToken)
+				return
+			}
+		}
+
+		logs.LogInfo.Infof("ValidateMessageSearchRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+// validateFiltersAndSetContext 解析过滤语句并校验，将校验通过的过滤条件存储到 Gin 上下文中
+func validateFiltersAndSetContext(ctx *gin.Context, filter string) bool {
+	filters, err := ParseMessageFilters(filter)
+	if err != nil {
+		HandlingValidateErrors(ctx, err)
+		return false
+	}
+	ctx.Set("messageFilters", &filters)
+	return true
+}
+
+// ParseMessageFilters 解析过滤语句并校验，返回过滤条件
+func ParseMessageFilters(filter string) ([]MessageFilterRequest, error) {
+	var filters []MessageFilterRequest
+	for _, filterStr := range strings.Split(filter, ",") {
+		filter := strings.SplitN(filterStr, " ", 3)
+		switch len(filter) {
+		case 1:
+			filters = append(filters, MessageFilterRequest{
+				Column: filter[0],
+			})
+			break
+		case 2:
+			filters = append(filters, MessageFilterRequest{
+				Column:     filter[0],
+				Comparison: filter[1],
+			})
+			break
+		case 3:
+			filters = append(filters, MessageFilterRequest{
+				Column:     filter[0],
+				Comparison: filter[1],
+				Value:      filter[2],
+			})
+			break
+		default:
+			filters = append(filters, MessageFilterRequest{})
+			break
+		}
+	}
+
+	if err := Validate.Var(&filters, "required,gt=0,dive,required"); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// MessageActionRequest 消息的操作按钮，url 和 callback 必须设置其中一个
+type MessageActionRequest struct {
+	Id       string `description:"按钮 ID，同一条消息中唯一" json:"id" validate:"required,alphanum,max=32" example:"approve"`
+	Label    string `description:"按钮的文字" json:"label" validate:"required,max=32" example:"同意"`
+	Url      string `description:"点击按钮后打开的链接" json:"url" validate:"required_without=Callback,excluded_with=Callback,omitempty,http_url,max=2048" example:"https://example.com/orders/1"`
+	Callback string `description:"点击按钮后通知发送者的回调 ID" json:"callback" validate:"omitempty,max=64" example:"order.approve"`
+	Style    string `description:"按钮的样式（default/primary/danger），为空时为 default" json:"style" validate:"omitempty,oneof=default primary danger" example:"primary"`
+}
+
 type MessageCreateUpdateRequest struct {
-	Title         string   `description:"标题" json:"title" validate:"required" example:"标题"`
-	Content       string   `description:"简单的内容" json:"content" validate:"required" example:"简单的内容"`
-	Category      string   `description:"消息类型" json:"category" validate:"required" example:"important"`
-	BigContent    string   `description:"复杂消息" json:"bigContent" validate:"required" example:"复杂的内容"`
-	IntroducerIds []string `description:"发给谁" json:"introducerIds" validate:"required,gt=0,dive,required" example:"发给谁"`
+	Title         string                 `description:"标题" json:"title" validate:"required" example:"标题"`
+	Content       string                 `description:"简单的内容" json:"content" validate:"required" example:"简单的内容"`
+	Category      string                 `description:"消息类型" json:"category" validate:"required" example:"important"`
+	BigContent    string                 `description:"复杂消息" json:"bigContent" validate:"required" example:"复杂的内容"`
+	IntroducerIds []string               `description:"发给谁" json:"introducerIds" validate:"required,gt=0,dive,required" example:"发给谁"`
+	Priority      string                 `description:"优先级（low/normal/high/urgent），创建时为空则根据消息类型设置，更新时为空则不修改" json:"priority" validate:"omitempty,oneof=low normal high urgent" example:"normal"`
+	ContentType   string                 `description:"复杂消息的格式（text/markdown/html），创建时为空则为 text，更新时为空则不修改" json:"contentType" validate:"omitempty,oneof=text markdown html" example:"markdown"`
+	Data          map[string]interface{} `description:"附带的结构化数据，更新时为空则不修改，为 {} 时清空" json:"data" validate:"omitempty,jsonmax=4096" swaggertype:"object"`
+	Actions       []MessageActionRequest `description:"操作按钮，更新时为空则不修改，为 [] 时清空" json:"actions" validate:"omitempty,max=5,unique=Id,dive"`
 }
 
 // ValidateMessageCreateUpdateRequestMiddleware 用于验证创建或更新消息请求参数的中间件
@@ -107,6 +243,167 @@ func ValidateMessageCreateUpdateRequestMiddleware() gin.HandlerFunc {
 	}
 }
 
+// ValidateIdempotencyKeyRequestMiddleware 用于验证 Idempotency-Key 请求头的中间件
+func ValidateIdempotencyKeyRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		idempotencyKey := ctx.GetHeader("Idempotency-Key")
+		if idempotencyKey == "" {
+			return
+		}
+
+		err := Validate.Var(idempotencyKey, "max=255,printascii")
+		if err != nil {
+			HandlingValidateErrors(ctx, err)
+			logs.LogInfo.Infof("ValidateIdempotencyKeyRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+
+		ctx.Set("idempotencyKey", idempotencyKey)
+		logs.LogInfo.Infof("ValidateIdempotencyKeyRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+type MessageBatchQueryRequest struct {
+	Atomic bool `description:"如果为true表示任意一条消息校验或创建失败则全部不创建" form:"atomic" example:"false"`
+}
+
+// MessageBatchItem 批量创建的一条消息，Errors 不为空时表示校验失败
+type MessageBatchItem struct {
+	Message MessageCreateUpdateRequest
+	Errors  []ValidationError
+}
+
+// ValidateMessageBatchRequestMiddleware 用于验证批量创建消息请求参数的中间件
+//
+// 每条消息单独校验，校验失败的消息记录错误信息，由调用方决定是否创建其他消息。
+func ValidateMessageBatchRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		// 检查并绑定查询参数
+		batchQuery := &MessageBatchQueryRequest{}
+		if err := ctx.ShouldBindQuery(batchQuery); err != nil {
+			logs.LogError.Errorf("ValidateMessageBatchRequestMiddleware %s %s", batchQuery, err)
+			handlingBindError(ctx, err)
+			return
+		}
+
+		// 检查并绑定 JSON 数据
+		var messages []MessageCreateUpdateRequest
+		if err := ctx.ShouldBindJSON(&messages); err != nil {
+			logs.LogError.Errorf("ValidateMessageBatchRequestMiddleware %s %s", err, messageToken)
+			handlingBindError(ctx, err)
+			return
+		}
+		if err := Validate.Var(messages, "required,gt=0,max=1000"); err != nil {
+			HandlingValidateErrors(ctx, err)
+			logs.LogInfo.Infof("ValidateMessageBatchRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+
+		// 单独校验每条消息
+		items := make([]MessageBatchItem, 0, len(messages))
+		for _, message := range messages {
+			item := MessageBatchItem{Message: message}
+			if err := Validate.Struct(&item.Message); err != nil {
+				item.Errors = ValidationErrors(err)
+			}
+			items = append(items, item)
+		}
+
+		ctx.Set("messageBatchQuery", batchQuery)
+		ctx.Set("messageBatch", &items)
+		logs.LogInfo.Infof("ValidateMessageBatchRequestMiddleware-成功 %d %s", len(items), messageToken)
+	}
+}
+
+// MessagePatchRequest 部分更新消息的请求，只更新请求中存在的字段
+type MessagePatchRequest struct {
+	Title               *string                 `description:"标题" json:"title" validate:"omitnil,min=1" example:"标题"`
+	Content             *string                 `description:"简单的内容" json:"content" validate:"omitnil,min=1" example:"简单的内容"`
+	Category            *string                 `description:"消息类型" json:"category" validate:"omitnil,min=1" example:"important"`
+	BigContent          *string                 `description:"复杂消息" json:"bigContent" validate:"omitnil,min=1" example:"复杂的内容"`
+	IntroducerIds       *[]string               `description:"替换全部接收者" json:"introducerIds" validate:"omitnil,gt=0,dive,required" example:"发给谁"`
+	Priority            *string                 `description:"优先级（low/normal/high/urgent）" json:"priority" validate:"omitnil,oneof=low normal high urgent" example:"normal"`
+	ContentType         *string                 `description:"复杂消息的格式（text/markdown/html）" json:"contentType" validate:"omitnil,oneof=text markdown html" example:"markdown"`
+	Data                map[string]interface{}  `description:"按照 JSON Merge Patch 合并到附带的结构化数据，值为 null 的键被删除，为 null 时清空" json:"data" validate:"omitempty,jsonmax=4096" swaggertype:"object"`
+	Actions             *[]MessageActionRequest `description:"替换操作按钮，为 null 时清空" json:"actions" validate:"omitnil,max=5,unique=Id,dive"`
+	AddIntroducerIds    []string                `description:"添加的接收者" json:"addIntroducerIds" validate:"omitempty,dive,required" example:"发给谁"`
+	RemoveIntroducerIds []string                `description:"移除的接收者" json:"removeIntroducerIds" validate:"omitempty,dive,required" example:"发给谁"`
+	// ClearData 请求中的 data 为 null，清空附带的结构化数据
+	ClearData bool `json:"-" swaggerignore:"true"`
+}
+
+// ValidateMessagePatchRequestMiddleware 用于验证部分更新消息请求参数的中间件
+//
+// 按照 JSON Merge Patch 的语义，字段的值为 null 表示删除该字段，消息的字段都是必填的，所以不允许为 null。
+// 结构化数据和操作按钮可以为 null，表示清空；结构化数据为对象时按照 JSON Merge Patch 合并。
+func ValidateMessagePatchRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		// 请求的 Content-Type 可能是 application/merge-patch+json，所以固定按照 JSON 解析
+		message := &MessagePatchRequest{}
+		if err := ctx.ShouldBindBodyWith(message, binding.JSON); err != nil {
+			logs.LogError.Errorf("ValidateMessagePatchRequestMiddleware %s %s", err, messageToken)
+			handlingBindError(ctx, err)
+			return
+		}
+
+		// 检查值为 null 的字段
+		var fields map[string]json.RawMessage
+		_ = json.Unmarshal(ctx.MustGet(gin.BodyBytesKey).([]byte), &fields)
+		if value, ok := fields["data"]; ok && string(value) == "null" {
+			message.ClearData = true
+		}
+		if value, ok := fields["actions"]; ok && string(value) == "null" {
+			message.Actions = &[]MessageActionRequest{}
+		}
+		var errorValidations []ValidationError
+		for key, field := range map[string]string{
+			"title":         "Title",
+			"content":       "Content",
+			"category":      "Category",
+			"bigContent":    "BigContent",
+			"introducerIds": "IntroducerIds",
+			"priority":      "Priority",
+			"contentType":   "ContentType",
+		} {
+			if value, ok := fields[key]; ok && string(value) == "null" {
+				errorValidations = append(errorValidations, ValidationError{
+					Field:   field,
+					Message: "required",
+				})
+			}
+		}
+		if len(errorValidations) > 0 {
+			handlingValidationErrors(ctx, errorValidations)
+			logs.LogInfo.Infof("ValidateMessagePatchRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+
+		if err := Validate.Struct(message); err != nil {
+			HandlingValidateErrors(ctx, err)
+			logs.LogInfo.Infof("ValidateMessagePatchRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+
+		ctx.Set("messagePatch", message)
+		logs.LogInfo.Infof("ValidateMessagePatchRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
 type MessageStatusRequest struct {
 	Id     string `json:"id,omitempty" validate:"required,len=32" example:"1"`
 	Status uint8  `json:"status,omitempty" validate:"required,max=2,min=0" example:"1"`
@@ -132,11 +429,67 @@ func ValidateMessageStatusRequestMiddleware() gin.HandlerFunc {
 	}
 }
 
+type MessageFlagRequest struct {
+	Id      string `json:"id" validate:"required,len=32" example:"1"`
+	Pinned  *bool  `description:"是否置顶，为空时不修改" json:"pinned" validate:"required_without=Starred" example:"true"`
+	Starred *bool  `description:"是否标星，为空时不修改" json:"starred" validate:"required_without=Pinned" example:"true"`
+}
+
+// ValidateMessageFlagRequestMiddleware 用于验证置顶和标星请求参数的中间件
+func ValidateMessageFlagRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateSliceAndSetContext(
+			ctx,
+			&[]MessageFlagRequest{},
+			"messageFlag",
+		) {
+			logs.LogInfo.Infof("ValidateMessageFlagRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateMessageFlagRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+type MessageTagRequest struct {
+	Id       string   `json:"id" validate:"required,len=32" example:"1"`
+	Tags     []string `description:"标签名称" json:"tags" validate:"required,gt=0,dive,required,max=32" example:"invoice"`
+	Personal bool     `description:"为 true 时是接收者自己的标签，否则是发送者设置的标签" json:"personal" example:"false"`
+}
+
+// ValidateMessageTagRequestMiddleware 用于验证添加或移除标签请求参数的中间件
+func ValidateMessageTagRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateSliceAndSetContext(
+			ctx,
+			&[]MessageTagRequest{},
+			"messageTag",
+		) {
+			logs.LogInfo.Infof("ValidateMessageTagRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateMessageTagRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
 type MessageDeleteRequest struct {
 	MessageId string `description:"消息id" json:"messageId" validate:"required,len=32" example:"id"`
 	Delete    bool   `description:"如果为true表示删除数据否则软删除" json:"delete" example:"false"`
 }
 
+type MessageDeleteQueryRequest struct {
+	Atomic bool `description:"如果为true表示任意一条消息删除失败则全部回滚" form:"atomic" example:"false"`
+}
+
 // ValidateMessageDeleteRequestMiddleware 用于验证删除消息请求参数的中间件
 func ValidateMessageDeleteRequestMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -145,6 +498,15 @@ func ValidateMessageDeleteRequestMiddleware() gin.HandlerFunc {
 		// 将 token 转换为 MessageToken 类型
 		messageToken := token.(string)
 
+		// 检查并绑定查询参数
+		deleteQuery := &MessageDeleteQueryRequest{}
+		if err := ctx.ShouldBindQuery(deleteQuery); err != nil {
+			logs.LogError.Errorf("ValidateMessageDeleteRequestMiddleware %s %s", deleteQuery, err)
+			handlingBindError(ctx, err)
+			return
+		}
+		ctx.Set("messageDeleteQuery", deleteQuery)
+
 		if !validateSliceAndSetContext(
 			ctx,
 			&[]MessageDeleteRequest{},
@@ -157,6 +519,103 @@ func ValidateMessageDeleteRequestMiddleware() gin.HandlerFunc {
 	}
 }
 
+type MessageRestoreRequest struct {
+	MessageId string `description:"消息id" json:"messageId" validate:"required,len=32" example:"id"`
+}
+
+// ValidateMessageRestoreRequestMiddleware 用于验证恢复消息请求参数的中间件
+func ValidateMessageRestoreRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateSliceAndSetContext(
+			ctx,
+			&[]MessageRestoreRequest{},
+			"messageRestore",
+		) {
+			logs.LogInfo.Infof("ValidateMessageRestoreRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateMessageRestoreRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+type MessageHideRequest struct {
+	MessageId string `description:"消息id" json:"messageId" validate:"required,len=32" example:"id"`
+}
+
+// ValidateMessageHideRequestMiddleware 用于验证接收者删除消息请求参数的中间件
+func ValidateMessageHideRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateSliceAndSetContext(
+			ctx,
+			&[]MessageHideRequest{},
+			"messageHide",
+		) {
+			logs.LogInfo.Infof("ValidateMessageHideRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateMessageHideRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+type MessageRetractRequest struct {
+	MessageId string `description:"消息id" json:"messageId" validate:"required,len=32" example:"id"`
+}
+
+// ValidateMessageRetractRequestMiddleware 用于验证发送者撤回消息请求参数的中间件
+func ValidateMessageRetractRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateSliceAndSetContext(
+			ctx,
+			&[]MessageRetractRequest{},
+			"messageRetract",
+		) {
+			logs.LogInfo.Infof("ValidateMessageRetractRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateMessageRetractRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
+type MessageVersionRequest struct {
+	Version uint   `description:"消息版本，为空时返回当前版本" form:"version" validate:"omitempty,min=1" example:"1"`
+	Format  string `description:"复杂消息的返回格式（html/text），为空时原样返回" form:"format" validate:"omitempty,oneof=html text" example:"text"`
+}
+
+// ValidateMessageVersionRequestMiddleware 用于验证查询消息版本请求参数的中间件
+func ValidateMessageVersionRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateStructAndSetContext(
+			ctx,
+			&MessageVersionRequest{},
+			"messageVersion",
+		) {
+			logs.LogInfo.Infof("ValidateMessageVersionRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateMessageVersionRequestMiddleware-成功 %s", messageToken)
+	}
+}
+
 // ValidateMessageIdRequestMiddleware 用于验证消息ID请求参数的中间件
 func ValidateMessageIdRequestMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -176,3 +635,23 @@ func ValidateMessageIdRequestMiddleware() gin.HandlerFunc {
 		logs.LogInfo.Infof("ValidateMessageIdRequestMiddleware-成功 %s", messageToken)
 	}
 }
+
+// ValidateMessageActionIdRequestMiddleware 用于验证操作按钮ID请求参数的中间件
+func ValidateMessageActionIdRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		err := Validate.Var(ctx.Param("actionId"), "required,alphanum,max=32")
+		if err != nil {
+			HandlingValidateErrors(ctx, err)
+			logs.LogInfo.Infof("ValidateMessageActionIdRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+
+		ctx.Next()
+		logs.LogInfo.Infof("ValidateMessageActionIdRequestMiddleware-成功 %s", messageToken)
+	}
+}
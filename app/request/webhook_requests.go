@@ -0,0 +1,59 @@
+package request
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"message/logs"
+	"message/utils"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Validate.RegisterValidation("public_url", validatePublicURL)
+}
+
+// validatePublicURL 校验地址的主机不是 localhost 或者回环、私有、链路本地等非公网 IP
+//
+// 域名解析到的地址在调用 Webhook 连接时再检查。
+func validatePublicURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return utils.IsPublicAddr(addr)
+	}
+	return true
+}
+
+// WebhookRequest 注册 Webhook 的请求
+type WebhookRequest struct {
+	Url    string `description:"接收事件的地址，只支持 http 和 https，不能是 localhost 或内网地址" json:"url" validate:"required,http_url,public_url,max=2048" example:"https://example.com/webhook"`
+	Secret string `description:"签名密钥，为空时不签名" json:"secret" validate:"omitempty,max=255" example:"secret"`
+}
+
+// ValidateWebhookRequestMiddleware 用于验证注册 Webhook 请求参数的中间件
+func ValidateWebhookRequestMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// 从上下文中获取 token
+		token, _ := ctx.Get("token")
+		// 将 token 转换为 MessageToken 类型
+		messageToken := token.(string)
+
+		if !validateStructAndSetContext(
+			ctx,
+			&WebhookRequest{},
+			"webhook",
+		) {
+			logs.LogInfo.Infof("ValidateWebhookRequestMiddleware-失败-参数错误 %s", messageToken)
+			return
+		}
+		logs.LogInfo.Infof("ValidateWebhookRequestMiddleware-成功 %s", messageToken)
+	}
+}
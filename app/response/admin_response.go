@@ -0,0 +1,23 @@
+package response
+
+import (
+	"time"
+)
+
+// AdminMessage 管理员查询到的消息，包含消息的删除时间
+type AdminMessage struct {
+	Message
+	DeletedAt *time.Time `json:"deleted_at" example:"2024-02-15T05:49:57Z"`
+}
+
+// MessageRecipientStatusResponse 表示消息每个接收者的状态
+type MessageRecipientStatusResponse struct {
+	// RecipientId 表示接收者的ID。
+	RecipientId string `json:"recipient_id"`
+
+	// Status 表示接收者的消息状态。
+	Status uint8 `json:"status"`
+
+	// UpdatedAt 表示接收者最后一次更新状态的时间，未更新过时为空。
+	UpdatedAt *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package response
+
+import (
+	"message/app/model"
+	"time"
+)
+
+// Audit 审计日志
+type Audit struct {
+	Actor     string             `json:"actor" example:"2f14ec370621a8be08c8f0ece459e7e0"`
+	Ip        string             `json:"ip" example:"127.0.0.1"`
+	Action    string             `json:"action" example:"update"`
+	MessageId string             `json:"message_id" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
+	Changes   model.AuditChanges `json:"changes"`
+	CreatedAt time.Time          `json:"created_at" example:"2024-02-15T05:49:57Z"`
+}
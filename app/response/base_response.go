@@ -1,15 +1,35 @@
 package response
 
-import "github.com/gin-gonic/gin"
+import (
+	lang "github.com/gin-contrib/i18n"
+	"github.com/gin-gonic/gin"
+)
 
 // NewError 是一个示例函数，用于在 Gin 上下文中返回错误响应。
 //
-// 它接收一个 Gin 上下文对象、状态码和错误信息作为参数。
+// 它接收一个 Gin 上下文对象、状态码和接口错误作为参数。
 //
-// 创建一个 HTTPError 对象，将状态码和错误信息填充到该对象中。
+// 旧接口创建一个 HTTPError 对象，将状态码和翻译后的错误信息填充到该对象中，并以指定的状态码返回给客户端。
 //
-// 最后，使用 JSON 方法将 HTTPError 对象以指定的状态码作为响应返回给客户端。
-func NewError(ctx *gin.Context, status int, message string) {
+// /v1 接口使用统一的响应格式，状态码和错误码由 apiError 决定。
+func NewError(ctx *gin.Context, status int, apiError APIError) {
+	NewErrorDetails(ctx, status, apiError, nil)
+}
+
+// NewErrorDetails 返回带有详细信息的错误响应，详细信息只在 /v1 接口中返回
+func NewErrorDetails(ctx *gin.Context, status int, apiError APIError, details interface{}) {
+	message := lang.MustGetMessage(ctx, apiError.Key)
+	if Enveloped(ctx) {
+		ctx.JSON(apiError.Status, Envelope{
+			Error: &EnvelopeError{
+				Code:    apiError.Code,
+				Message: message,
+				Details: details,
+			},
+		})
+		return
+	}
+
 	er := HTTPError{
 		Code:    status,
 		Message: message,
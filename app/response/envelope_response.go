@@ -0,0 +1,104 @@
+package response
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/config"
+	"net/http"
+)
+
+// EnvelopeKey 上下文中标记使用统一响应格式的键，由 /v1 路由组设置
+const EnvelopeKey = "envelope"
+
+// Envelope /v1 接口统一的响应格式
+type Envelope struct {
+	// Data 请求成功时返回的数据，失败时为 null
+	Data interface{} `json:"data"`
+
+	// Error 请求失败时的错误信息
+	Error *EnvelopeError `json:"error,omitempty"`
+
+	// Meta 分页等附加信息
+	Meta interface{} `json:"meta,omitempty" swaggertype:"object"`
+}
+
+// EnvelopeError /v1 接口的错误信息
+type EnvelopeError struct {
+	// Code 稳定的错误码，客户端应该根据错误码而不是错误信息判断错误类型
+	Code string `json:"code" example:"not_found"`
+
+	// Message 翻译后的错误信息
+	Message string `json:"message" example:"找不到数据"`
+
+	// Details 错误的详细信息，例如参数校验失败的字段
+	Details interface{} `json:"details,omitempty" swaggertype:"object"`
+}
+
+// PageMeta 分页查询的附加信息
+type PageMeta struct {
+	Page    int  `json:"page" example:"1"`
+	Limit   int  `json:"limit" example:"100"`
+	Count   int  `json:"count" example:"100"`
+	HasMore bool `json:"has_more" example:"true"`
+}
+
+// APIError 接口错误，包括 /v1 接口的状态码、错误码和错误信息的翻译键
+type APIError struct {
+	Status int
+	Code   string
+	Key    string
+}
+
+// 定义接口错误的常量，错误码发布后不能修改
+var (
+	ErrInvalidRequest        = APIError{http.StatusBadRequest, "invalid_request", "badRequest"}                     // 请求体或查询参数无法解析
+	ErrValidationFailed      = APIError{http.StatusBadRequest, "validation_failed", "validationFailed"}             // 参数校验失败
+	ErrIntroducerRequired    = APIError{http.StatusBadRequest, "introducer_required", "introducerRequired"}         // 消息没有接收者
+	ErrUnauthorized          = APIError{http.StatusUnauthorized, "unauthorized", "unauthorized"}                    // 凭证错误
+	ErrNotFound              = APIError{http.StatusNotFound, "not_found", "notFound"}                               // 找不到数据
+	ErrMessageActionChosen   = APIError{http.StatusConflict, "message_action_chosen", "messageActionChosen"}        // 已经选择了其他操作
+	ErrIdempotencyKeyReused  = APIError{http.StatusConflict, "idempotency_key_reused", "idempotencyKeyReused"}      // Idempotency-Key 已经用于其他请求
+	ErrIdempotentMessageGone = APIError{http.StatusGone, "idempotent_message_gone", "idempotentMessageGone"}        // Idempotency-Key 之前创建的消息已经被彻底删除
+	ErrPreconditionFailed    = APIError{http.StatusPreconditionFailed, "precondition_failed", "preconditionFailed"} // 消息已经被修改
+	ErrPreconditionRequired  = APIError{http.StatusPreconditionRequired, "precondition_required", "preconditionRequired"}
+	ErrImportTooLarge        = APIError{http.StatusRequestEntityTooLarge, "import_too_large", "importTooLarge"}       // 导入的消息过多
+	ErrCreateMessageFailed   = APIError{http.StatusInternalServerError, "create_message_failed", "createMessageFail"} // 创建消息失败
+	ErrUpdateMessageFailed   = APIError{http.StatusInternalServerError, "update_message_failed", "updateMessageFail"} // 更新消息失败
+	ErrInternal              = APIError{http.StatusInternalServerError, "internal_error", "badGateway"}               // 系统异常
+	ErrServiceUnavailable    = APIError{http.StatusServiceUnavailable, "service_unavailable", "serviceUnavailable"}   // 数据库等依赖不可用
+)
+
+// Enveloped 判断当前请求是否使用统一的响应格式
+func Enveloped(ctx *gin.Context) bool {
+	return ctx.GetBool(EnvelopeKey)
+}
+
+// JSON 返回数据，/v1 接口将数据放到统一的响应格式中
+func JSON(ctx *gin.Context, status int, data interface{}) {
+	if Enveloped(ctx) {
+		ctx.JSON(status, Envelope{Data: data})
+		return
+	}
+	ctx.JSON(status, data)
+}
+
+// Page 返回分页查询的数据，/v1 接口在 meta 中返回分页信息
+func Page[T any](ctx *gin.Context, page int, data []T) {
+	if Enveloped(ctx) {
+		// 没有数据时返回空数组而不是 null
+		if data == nil {
+			data = []T{}
+		}
+		limit := config.AppConfig.API.MaxLimit
+		ctx.JSON(http.StatusOK, Envelope{
+			Data: data,
+			Meta: PageMeta{
+				Page:    page,
+				Limit:   limit,
+				Count:   len(data),
+				HasMore: len(data) >= limit,
+			},
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, data)
+}
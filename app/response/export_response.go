@@ -0,0 +1,132 @@
+package response
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportErrorTrailer 导出中途出错时在响应结束后发送的 trailer，值为错误码，导出完整时不发送
+const ExportErrorTrailer = "X-Export-Error"
+
+// MessageCSVColumns 导出和导入 CSV 时的列，与消息 JSON 的字段名称相同
+var MessageCSVColumns = []string{
+	"message_id",
+	"sender_ids",
+	"introducer_ids",
+	"title",
+	"content",
+	"category",
+	"big_content",
+	"content_type",
+	"data",
+	"actions",
+	"status",
+	"priority",
+	"version",
+	"edited",
+	"created_at",
+	"updated_at",
+	"recipients",
+}
+
+// MessageRecipientState 接收者对消息的阅读状态和标记，导出和导入时使用
+type MessageRecipientState struct {
+	// RecipientId 接收者的ID
+	RecipientId string `json:"recipient_id" example:"fc64c1a807c2e69655f68d31e5caa35d"`
+	// Status 接收者的消息状态
+	Status uint8 `json:"status" example:"1"`
+	// Hidden 接收者是否删除了消息
+	Hidden bool `json:"hidden" example:"false"`
+	// Pinned 接收者是否置顶了消息
+	Pinned bool `json:"pinned" example:"false"`
+	// Starred 接收者是否标星了消息
+	Starred bool `json:"starred" example:"true"`
+	// UpdatedAt 接收者最后一次更新状态的时间
+	UpdatedAt time.Time `json:"updated_at" example:"2024-02-15T05:49:57Z"`
+}
+
+// ExportedMessage 导出的消息，包含已经记录的接收者状态
+type ExportedMessage struct {
+	Message
+	// Recipients 接收者的状态，没有记录状态的接收者为未读
+	Recipients []MessageRecipientState `json:"recipients"`
+}
+
+// CSVRecord 将导出的消息转换为 CSV 的一行，顺序与 MessageCSVColumns 相同
+//
+// 发送者和接收者使用逗号连接，结构化数据、操作按钮和接收者的状态使用 JSON 格式。
+func (m *ExportedMessage) CSVRecord() []string {
+	data, _ := m.Data.Value()
+	actions, _ := m.Actions.Value()
+	return []string{
+		m.MessageId,
+		strings.Join(m.SenderIds, ","),
+		strings.Join(m.IntroducerIds, ","),
+		m.Title,
+		m.Content,
+		m.Category,
+		m.BigContent,
+		m.ContentType,
+		data.(string),
+		actions.(string),
+		strconv.Itoa(int(m.Status)),
+		m.Priority.String(),
+		strconv.FormatUint(uint64(m.Version), 10),
+		strconv.FormatBool(m.Edited),
+		m.CreatedAt.Format(time.RFC3339Nano),
+		m.UpdatedAt.Format(time.RFC3339Nano),
+		recipientsCSV(m.Recipients),
+	}
+}
+
+// recipientsCSV 将接收者的状态转换为 JSON，没有记录状态时为空
+func recipientsCSV(recipients []MessageRecipientState) string {
+	if len(recipients) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(recipients)
+	return string(data)
+}
+
+// MessageImportError 导入时校验失败的一行
+type MessageImportError struct {
+	// Line 数据所在的行号，从 1 开始，CSV 包括表头
+	Line int `json:"line" example:"2"`
+	// MessageId 消息id，解析失败时为空
+	MessageId string `json:"message_id,omitempty" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
+	// Errors 校验失败的错误信息
+	Errors interface{} `json:"errors" swaggertype:"array,object"`
+}
+
+// MessageImportResponse 导入消息的结果
+type MessageImportResponse struct {
+	// DryRun 是否只校验数据，没有写入数据库
+	DryRun bool `json:"dry_run" example:"false"`
+	// Total 读取到的消息数量
+	Total int `json:"total" example:"100"`
+	// Imported 导入的消息数量，只校验时是可以导入的消息数量
+	Imported int `json:"imported" example:"97"`
+	// Skipped 消息id已经存在而跳过的消息数量
+	Skipped int `json:"skipped" example:"1"`
+	// Invalid 校验失败的消息数量
+	Invalid int `json:"invalid" example:"2"`
+	// Errors 校验失败的消息，最多返回 100 条
+	Errors []MessageImportError `json:"errors"`
+}
+
+// MaxMessageImportErrors 导入结果中最多返回的错误数量
+const MaxMessageImportErrors = 100
+
+// AddError 记录一条校验失败的消息
+func (r *MessageImportResponse) AddError(line int, messageId string, errors interface{}) {
+	r.Invalid++
+	if len(r.Errors) < MaxMessageImportErrors {
+		r.Errors = append(r.Errors, MessageImportError{
+			Line:      line,
+			MessageId: messageId,
+			Errors:    errors,
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package response
+
+import "time"
+
+// 定义健康检查状态的常量
+const (
+	HealthOK          = "ok"          // 正常
+	HealthDegraded    = "degraded"    // 可以处理请求，但是后台任务等非必需的功能异常
+	HealthUnavailable = "unavailable" // 不能处理请求
+)
+
+// Health 健康检查的结果
+type Health struct {
+	// Status 服务的状态（ok/degraded/unavailable）
+	Status string `json:"status" example:"ok"`
+
+	// Checks 每一项检查的结果，键为检查的名称
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck 一项健康检查的结果
+type HealthCheck struct {
+	// Status 检查的状态（ok/degraded/unavailable）
+	Status string `json:"status" example:"ok"`
+
+	// Error 检查失败的原因，只有固定的说明，具体的错误在日志中
+	Error string `json:"error,omitempty" example:"数据库不可用"`
+
+	// Details 检查的详细信息，例如迁移的版本和后台任务的状态
+	Details interface{} `json:"details,omitempty" swaggertype:"object"`
+}
+
+// WorkerHealth 后台任务的运行状态
+type WorkerHealth struct {
+	Name      string     `json:"name" example:"trash"`
+	Running   bool       `json:"running" example:"true"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty" example:"2024-02-15T05:49:57Z"`
+	LastError string     `json:"last_error,omitempty" example:"最近一次执行失败"`
+	Failures  int        `json:"failures" example:"0"`
+}
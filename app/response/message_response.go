@@ -1,23 +1,186 @@
 package response
 
 import (
+	"fmt"
 	"message/app/model"
+	"message/utils"
+	"strings"
 	"time"
 )
 
 type Message struct {
-	MessageId     string            `json:"message_id" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
-	SenderIds     model.StringArray `json:"sender_ids" example:"2f14ec370621a8be08c8f0ece459e7e0,22798c5dcd6e5b66c8660c447010d49d,..."`
-	Title         string            `json:"title" example:"标题"`
-	Content       string            `json:"content" example:"简单的内容"`
-	Category      string            `json:"category" example:"important"`
-	BigContent    string            `json:"big_content" example:"复杂的内容"`
-	IntroducerIds model.StringArray `json:"introducer_ids" example:"fc64c1a807c2e69655f68d31e5caa35d,70c021d35ce60436c115b20b5cf583d0,..."`
-	Status        uint8             `json:"status" example:"0"`
-	CreatedAt     time.Time         `json:"created_at" example:"2024-02-15T05:49:57Z"`
-	UpdatedAt     time.Time         `json:"updated_at" example:"2024-02-15T05:49:57Z"`
+	MessageId     string               `json:"message_id" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
+	SenderIds     model.StringArray    `json:"sender_ids" example:"2f14ec370621a8be08c8f0ece459e7e0,22798c5dcd6e5b66c8660c447010d49d,..."`
+	Title         string               `json:"title" example:"标题"`
+	Content       string               `json:"content" example:"简单的内容"`
+	Category      string               `json:"category" example:"important"`
+	BigContent    string               `json:"big_content" example:"复杂的内容"`
+	ContentType   string               `json:"content_type" example:"text"`
+	IntroducerIds model.StringArray    `json:"introducer_ids" example:"fc64c1a807c2e69655f68d31e5caa35d,70c021d35ce60436c115b20b5cf583d0,..."`
+	Data          model.MessageData    `json:"data" swaggertype:"object"`
+	Actions       model.MessageActions `json:"actions"`
+	Status        uint8                `json:"status" example:"0"`
+	Priority      model.Priority       `json:"priority" swaggertype:"string" enums:"low,normal,high,urgent" example:"normal"`
+	Version       uint                 `json:"version" example:"1"`
+	Edited        bool                 `json:"edited" example:"false"`
+	CreatedAt     time.Time            `json:"created_at" example:"2024-02-15T05:49:57Z"`
+	UpdatedAt     time.Time            `json:"updated_at" example:"2024-02-15T05:49:57Z"`
 }
 
+// NewMessage 根据消息模型创建返回给客户端的消息
+func NewMessage(message *model.Message) *Message {
+	return &Message{
+		MessageId:     message.MessageId,
+		SenderIds:     message.SenderIds,
+		Title:         message.Title,
+		Content:       message.Content,
+		Category:      message.Category,
+		BigContent:    message.BigContent,
+		ContentType:   message.ContentType,
+		IntroducerIds: message.IntroducerIds,
+		Data:          message.Data,
+		Actions:       message.Actions,
+		Status:        message.Status,
+		Priority:      message.Priority,
+		Version:       message.Version,
+		Edited:        message.Edited,
+		CreatedAt:     message.CreatedAt,
+		UpdatedAt:     message.UpdatedAt,
+	}
+}
+
+// Render 将详细内容渲染为 format 格式，format 为空时不修改
+func (m *Message) Render(format string) {
+	if format == "" || format == m.ContentType {
+		return
+	}
+	m.BigContent = utils.RenderContent(m.BigContent, m.ContentType, format)
+	m.ContentType = format
+}
+
+// MessageETag 根据消息的版本、更新时间和复杂消息的格式生成 ETag
+//
+// 同一个版本渲染为不同格式时 ETag 不同，避免 If-None-Match 时返回其他格式的缓存。
+func MessageETag(message *Message) string {
+	return fmt.Sprintf(`"%s-%s"`, messageVersionTag(message), message.ContentType)
+}
+
+// MatchMessageVersion 判断 ETag 是否属于消息的当前版本，不区分格式，用于更新消息时的 If-Match
+func MatchMessageVersion(etag string, message *Message) bool {
+	return strings.HasPrefix(strings.Trim(etag, `"`), messageVersionTag(message)+"-")
+}
+
+// MatchIfMatch 判断 If-Match 的 ETag 列表中是否有消息当前版本的 ETag，* 匹配任意版本
+//
+// 按照 RFC 9110 使用强比较，弱 ETag 不匹配。任意格式返回的 ETag 都可以用于更新。
+func MatchIfMatch(header string, message *Message) bool {
+	return matchETags(header, false, func(etag string) bool {
+		return MatchMessageVersion(etag, message)
+	})
+}
+
+// MatchIfNoneMatch 判断 If-None-Match 的 ETag 列表中是否有消息的 ETag，* 匹配任意 ETag
+//
+// 按照 RFC 9110 使用弱比较，忽略 W/ 前缀。
+func MatchIfNoneMatch(header string, message *Message) bool {
+	etag := MessageETag(message)
+	return matchETags(header, true, func(value string) bool {
+		return value == etag
+	})
+}
+
+// matchETags 判断逗号分隔的 ETag 列表中是否有满足 match 的 ETag，weak 为 false 时弱 ETag 不匹配
+func matchETags(header string, weak bool, match func(etag string) bool) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" {
+			return true
+		}
+		if strings.HasPrefix(value, "W/") {
+			if !weak {
+				continue
+			}
+			value = strings.TrimPrefix(value, "W/")
+		}
+		if value != "" && match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageVersionTag 消息版本的标识，由消息 id、版本和更新时间组成
+func messageVersionTag(message *Message) string {
+	return fmt.Sprintf("%s-%d-%d", message.MessageId, message.Version, message.UpdatedAt.UnixNano())
+}
+
+// MessageVersion 消息的一个版本
+type MessageVersion struct {
+	Version       uint                 `json:"version" example:"1"`
+	Title         string               `json:"title" example:"标题"`
+	Content       string               `json:"content" example:"简单的内容"`
+	Category      string               `json:"category" example:"important"`
+	BigContent    string               `json:"big_content" example:"复杂的内容"`
+	ContentType   string               `json:"content_type" example:"text"`
+	IntroducerIds model.StringArray    `json:"introducer_ids" example:"fc64c1a807c2e69655f68d31e5caa35d,70c021d35ce60436c115b20b5cf583d0,..."`
+	Data          model.MessageData    `json:"data" swaggertype:"object"`
+	Actions       model.MessageActions `json:"actions"`
+	Priority      model.Priority       `json:"priority" swaggertype:"string" enums:"low,normal,high,urgent" example:"normal"`
+	EditedAt      time.Time            `json:"edited_at" example:"2024-02-15T05:49:57Z"`
+}
+
+// InboxMessage 接收者查询到的消息，包括接收者自己的置顶、标星和可以看到的标签
+type InboxMessage struct {
+	Message
+	Pinned  bool     `json:"pinned" example:"false"`
+	Starred bool     `json:"starred" example:"false"`
+	Tags    []string `json:"tags" gorm:"-" example:"invoice,q3"`
+}
+
+// MessageSearchResult 搜索到的消息
+type MessageSearchResult struct {
+	Message
+	Score    float64           `json:"score" example:"1.5"`
+	Snippets map[string]string `json:"snippets" example:"title:<mark>标题</mark>"`
+}
+
+// TrashMessage 已软删除的消息
+type TrashMessage struct {
+	Message
+	DeletedAt time.Time `json:"deleted_at" example:"2024-02-15T05:49:57Z"`
+}
+
+// MessageActionResponse 接收者点击操作按钮的响应数据
+type MessageActionResponse struct {
+	MessageId string    `json:"message_id" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
+	ActionId  string    `json:"action_id" example:"approve"`
+	Callback  string    `json:"callback" example:"order.approve"`
+	ChosenAt  time.Time `json:"chosen_at" example:"2024-02-15T05:49:57Z"`
+}
+
+// MessageBatchResponse 批量创建中每条消息的结果
+type MessageBatchResponse struct {
+	// Index 消息在请求数组中的位置，从 0 开始
+	Index int `json:"index" example:"0"`
+
+	// MessageId 创建成功时消息的唯一标识符
+	MessageId string `json:"message_id,omitempty" example:"7e55cb38290f49ee2b0e9cfd2adf13e4"`
+
+	// Result 创建的结果（created/invalid/failed/rolledBack）
+	Result string `json:"result" example:"created"`
+
+	// Errors 校验失败时的错误信息
+	Errors interface{} `json:"errors,omitempty" swaggertype:"array,object"`
+}
+
+// 定义批量创建消息结果的常量
+const (
+	MessageBatchCreated    = "created"    // 创建成功
+	MessageBatchInvalid    = "invalid"    // 参数校验失败
+	MessageBatchFailed     = "failed"     // 数据库出现错误
+	MessageBatchRolledBack = "rolledBack" // 其他消息失败导致没有创建
+)
+
 // MessageStatusResponse 用于封装消息状态更新操作的响应数据
 type MessageStatusResponse struct {
 	// Id 表示消息的唯一标识符。
@@ -31,6 +194,14 @@ type MessageStatusResponse struct {
 	Result bool `json:"result"`
 }
 
+// MessageFlagResponse 接收者置顶和标星操作的响应数据
+type MessageFlagResponse struct {
+	Id      string `json:"id"`
+	Pinned  bool   `json:"pinned"`
+	Starred bool   `json:"starred"`
+	Result  bool   `json:"result"`
+}
+
 // MessageDeleteResponse 表示消息删除操作的响应数据结构
 type MessageDeleteResponse struct {
 	// Id 表示消息的唯一标识符。
@@ -41,4 +212,25 @@ type MessageDeleteResponse struct {
 
 	// Status 表示消息删除操作的状态，用于指示操作是否成功
 	Status bool `json:"status"`
+
+	// Result 表示消息删除操作的结果（deleted/notFound/forbidden/failed/rolledBack）。
+	Result string `json:"result" example:"deleted"`
+}
+
+// 定义消息删除结果的常量
+const (
+	MessageDeleteDeleted    = "deleted"    // 删除成功
+	MessageDeleteNotFound   = "notFound"   // 找不到消息
+	MessageDeleteForbidden  = "forbidden"  // 没有权限删除消息
+	MessageDeleteFailed     = "failed"     // 数据库出现错误
+	MessageDeleteRolledBack = "rolledBack" // 其他消息删除失败导致回滚
+)
+
+// MessageRestoreResponse 表示消息恢复操作的响应数据结构
+type MessageRestoreResponse struct {
+	// Id 表示消息的唯一标识符。
+	Id string `json:"id"`
+
+	// Status 表示消息恢复操作的状态，用于指示操作是否成功
+	Status bool `json:"status"`
 }
@@ -0,0 +1,44 @@
+package response_test
+
+import (
+	"message/app/response"
+	"testing"
+	"time"
+)
+
+func TestMatchETags(t *testing.T) {
+	message := &response.Message{
+		MessageId:   "7e55cb38290f49ee2b0e9cfd2adf13e4",
+		Version:     2,
+		ContentType: "markdown",
+		UpdatedAt:   time.Date(2024, 2, 15, 5, 49, 57, 0, time.UTC),
+	}
+	etag := response.MessageETag(message)
+	html := etag[:len(etag)-len(`markdown"`)] + `html"`
+	stale := `"7e55cb38290f49ee2b0e9cfd2adf13e4-1-0-markdown"`
+
+	tests := []struct {
+		header      string
+		ifMatch     bool
+		ifNoneMatch bool
+	}{
+		{etag, true, true},
+		{"*", true, true},
+		{stale + ", " + etag, true, true},
+		// If-Match 使用强比较，弱 ETag 不匹配；If-None-Match 使用弱比较
+		{"W/" + etag, false, true},
+		{stale + ", W/" + etag, false, true},
+		// 任意格式的 ETag 都可以用于更新，但只有相同格式的 ETag 命中缓存
+		{html, true, false},
+		{stale, false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		if got := response.MatchIfMatch(test.header, message); got != test.ifMatch {
+			t.Errorf("MatchIfMatch(%q) = %t, want %t", test.header, got, test.ifMatch)
+		}
+		if got := response.MatchIfNoneMatch(test.header, message); got != test.ifNoneMatch {
+			t.Errorf("MatchIfNoneMatch(%q) = %t, want %t", test.header, got, test.ifNoneMatch)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package response
+
+// MessageTagResponse 添加或移除标签操作的响应数据
+type MessageTagResponse struct {
+	Id     string   `json:"id"`
+	Tags   []string `json:"tags" example:"invoice,q3"`
+	Result bool     `json:"result"`
+}
+
+// TagCount 标签以及使用该标签的消息数量
+type TagCount struct {
+	Name     string `json:"name" example:"invoice"`
+	Personal bool   `json:"personal" example:"false"`
+	Count    int64  `json:"count" example:"3"`
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"message/app/model"
+	"time"
+)
+
+// Webhook 发送者注册的 Webhook，不返回签名密钥
+type Webhook struct {
+	Url       string    `json:"url" example:"https://example.com/webhook"`
+	HasSecret bool      `json:"has_secret" example:"true"`
+	CreatedAt time.Time `json:"created_at" example:"2024-02-15T05:49:57Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-02-15T05:49:57Z"`
+}
+
+// NewWebhook 将 Webhook 转换为返回给客户端的数据
+func NewWebhook(webhook *model.Webhook) *Webhook {
+	return &Webhook{
+		Url:       webhook.Url,
+		HasSecret: webhook.Secret != "",
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
@@ -0,0 +1,181 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/config"
+	"message/database"
+	"message/logs"
+	"message/proto/messagepb"
+	"net"
+)
+
+// tokenKey 上下文中保存消息凭证的键
+type tokenKey struct{}
+
+// Start 根据配置启动 gRPC 服务，地址为空时不启动并返回 nil
+//
+// 服务在单独的协程中运行，调用方通过返回的 grpc.Server 停止服务。
+func Start() (*grpc.Server, error) {
+	address := config.AppConfig.GRPC.Address
+	if address == "" {
+		return nil, nil
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	server := newServer()
+	go func() {
+		logs.LogInfo.Infof("RPC-启动 %s", address)
+		if err := server.Serve(listener); err != nil {
+			logs.LogError.Errorf("RPC-停止 %s", err)
+		}
+	}()
+	return server, nil
+}
+
+// newServer 创建注册了消息服务和凭证验证的 gRPC 服务
+func newServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authUnaryInterceptor),
+		grpc.StreamInterceptor(authStreamInterceptor),
+	)
+	messagepb.RegisterMessageServiceServer(server, &messageServer{})
+	return server
+}
+
+// authenticate 验证 metadata 中的 authorization，和 HTTP 接口的 AuthMiddleware 相同
+func authenticate(ctx context.Context) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	if !database.Connected() {
+		logs.LogError.Errorf("RPC-AuthMiddleware-数据库未连接 %s", clientIP(ctx))
+		return nil, apiError(codes.Unavailable, response.ErrServiceUnavailable)
+	}
+	if err := request.Validate.Var(token, "required,len=32"); err != nil {
+		logs.LogInfo.Infof("RPC-AuthMiddleware-失败 %s %s", err, clientIP(ctx))
+		return nil, apiError(codes.Unauthenticated, response.ErrUnauthorized)
+	}
+	if _, err := repository.GetMessageToken(token); errors.Is(err, gorm.ErrRecordNotFound) {
+		logs.LogInfo.Infof("RPC-AuthMiddleware-失败-找不到凭证 %s", clientIP(ctx))
+		return nil, apiError(codes.Unauthenticated, response.ErrUnauthorized)
+	} else if err != nil {
+		// 查询凭证失败不是凭证错误，客户端可以稍后重试
+		logs.LogError.Errorf("RPC-AuthMiddleware-查询凭证失败 %s %s", err, clientIP(ctx))
+		return nil, apiError(codes.Unavailable, response.ErrServiceUnavailable)
+	}
+	return context.WithValue(ctx, tokenKey{}, token), nil
+}
+
+// authUnaryInterceptor 验证普通调用的凭证
+func authUnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStreamInterceptor 验证流式调用的凭证
+func authStreamInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authServerStream{ServerStream: stream, ctx: ctx})
+}
+
+// authServerStream 携带消息凭证上下文的 grpc.ServerStream
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带消息凭证的上下文
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// contextToken 返回上下文中已经验证的消息凭证
+func contextToken(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	return token
+}
+
+// clientIP 返回调用方的 IP
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// auditor 返回当前调用的审计日志操作者
+func auditor(ctx context.Context) *repository.Auditor {
+	return &repository.Auditor{
+		Actor: contextToken(ctx),
+		Ip:    clientIP(ctx),
+	}
+}
+
+// apiError 返回 gRPC 错误，错误信息为 /v1 接口稳定的错误码
+func apiError(code codes.Code, apiError response.APIError) error {
+	return status.Error(code, apiError.Code)
+}
+
+// validationError 将参数校验错误转换为 InvalidArgument，校验失败的字段放在 BadRequest 详细信息中
+func validationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		logs.LogError.Errorf("RPC-HandlingValidateErrors %s", err)
+		return apiError(codes.Internal, response.ErrInternal)
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, validationError := range request.ValidationErrors(validationErrors) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       validationError.Field,
+			Description: validationError.Message,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, response.ErrValidationFailed.Code).WithDetails(badRequest)
+	if detailsErr != nil {
+		return apiError(codes.InvalidArgument, response.ErrValidationFailed)
+	}
+	return st.Err()
+}
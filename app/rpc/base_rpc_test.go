@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"message/app/repository"
+	"message/config"
+	"message/proto/messagepb"
+	"message/testutil"
+	"net"
+	"strings"
+	"testing"
+)
+
+// dialTestServer 在内存中启动 gRPC 服务并返回连接到服务的客户端
+func dialTestServer(t *testing.T) messagepb.MessageServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := newServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return messagepb.NewMessageServiceClient(conn)
+}
+
+func TestAuthenticateRejectsUnknownAndRevokedTokens(t *testing.T) {
+	testutil.Setup(t)
+	client := dialTestServer(t)
+	token := testutil.Token(t)
+
+	list := func(token string) codes.Code {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+		_, err := client.ListMessages(ctx, &messagepb.ListMessagesRequest{})
+		return status.Code(err)
+	}
+
+	if code := list(token); code != codes.OK {
+		t.Fatalf("ListMessages with a valid token = %s, want OK", code)
+	}
+	if code := list(strings.Repeat("0", 32)); code != codes.Unauthenticated {
+		t.Fatalf("ListMessages with an unknown token = %s, want Unauthenticated", code)
+	}
+
+	if revoked, err := repository.RevokeMessageToken(token); err != nil || revoked != 1 {
+		t.Fatalf("RevokeMessageToken = %d, %v", revoked, err)
+	}
+	if code := list(token); code != codes.Unauthenticated {
+		t.Fatalf("ListMessages with a revoked token = %s, want Unauthenticated", code)
+	}
+}
+
+func TestAuthenticateReturnsUnavailableWhenTokenLookupFails(t *testing.T) {
+	db := testutil.Setup(t)
+	client := dialTestServer(t)
+	token := testutil.Token(t)
+
+	// 查询凭证失败不是凭证错误，客户端可以重试
+	if err := db.Migrator().DropTable(config.AppConfig.App.Verify.Table); err != nil {
+		t.Fatalf("drop verify table: %s", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+	_, err := client.ListMessages(ctx, &messagepb.ListMessagesRequest{})
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Fatalf("ListMessages when the token lookup fails = %s, want Unavailable", code)
+	}
+}
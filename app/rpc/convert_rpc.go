@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"math"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/proto/messagepb"
+	"strconv"
+)
+
+// toUint8 将 proto 中的 uint32 转换为 uint8，超出范围时返回最大值，由参数校验拒绝
+func toUint8(value uint32) uint8 {
+	return uint8(min(value, math.MaxUint8))
+}
+
+// priorityName 将 proto 中的优先级数值转换为 HTTP 接口使用的名称，0 表示没有设置，超出范围时返回数值，由参数校验拒绝
+func priorityName(value uint32) string {
+	if value == 0 {
+		return ""
+	}
+	if name := model.Priority(toUint8(value)).String(); name != "" {
+		return name
+	}
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+// newMessageRequest 将 gRPC 的消息数据转换为 HTTP 接口的创建或更新请求，和 HTTP 接口使用相同的校验
+func newMessageRequest(input *messagepb.MessageInput) *request.MessageCreateUpdateRequest {
+	if input == nil {
+		input = &messagepb.MessageInput{}
+	}
+
+	messageRequest := &request.MessageCreateUpdateRequest{
+		Title:         input.GetTitle(),
+		Content:       input.GetContent(),
+		Category:      input.GetCategory(),
+		BigContent:    input.GetBigContent(),
+		IntroducerIds: input.GetIntroducerIds(),
+		Priority:      priorityName(input.GetPriority()),
+		ContentType:   input.GetContentType(),
+	}
+	if input.GetData() != nil {
+		messageRequest.Data = input.GetData().AsMap()
+	}
+	if input.GetActions() != nil {
+		messageRequest.Actions = make([]request.MessageActionRequest, 0, len(input.GetActions()))
+		for _, action := range input.GetActions() {
+			messageRequest.Actions = append(messageRequest.Actions, request.MessageActionRequest{
+				Id:       action.GetId(),
+				Label:    action.GetLabel(),
+				Url:      action.GetUrl(),
+				Callback: action.GetCallback(),
+				Style:    action.GetStyle(),
+			})
+		}
+	}
+	return messageRequest
+}
+
+// newMessage 将返回给客户端的消息转换为 gRPC 的消息
+func newMessage(message *response.Message) *messagepb.Message {
+	pbMessage := &messagepb.Message{
+		MessageId:     message.MessageId,
+		SenderIds:     message.SenderIds,
+		Title:         message.Title,
+		Content:       message.Content,
+		Category:      message.Category,
+		BigContent:    message.BigContent,
+		ContentType:   message.ContentType,
+		IntroducerIds: message.IntroducerIds,
+		Status:        uint32(message.Status),
+		Priority:      uint32(message.Priority),
+		Version:       uint32(message.Version),
+		Edited:        message.Edited,
+		CreatedAt:     timestamppb.New(message.CreatedAt),
+		UpdatedAt:     timestamppb.New(message.UpdatedAt),
+		Etag:          response.MessageETag(message),
+	}
+	if len(message.Data) > 0 {
+		// 结构化数据来自 JSON，转换失败时不返回
+		pbMessage.Data, _ = structpb.NewStruct(message.Data)
+	}
+	for _, action := range message.Actions {
+		pbMessage.Actions = append(pbMessage.Actions, &messagepb.MessageAction{
+			Id:       action.Id,
+			Label:    action.Label,
+			Url:      action.Url,
+			Callback: action.Callback,
+			Style:    action.Style,
+		})
+	}
+	return pbMessage
+}
+
+// newInboxMessage 将接收者查询到的消息转换为 gRPC 的消息
+func newInboxMessage(message *response.InboxMessage) *messagepb.InboxMessage {
+	return &messagepb.InboxMessage{
+		Message: newMessage(&message.Message),
+		Pinned:  message.Pinned,
+		Starred: message.Starred,
+		Tags:    message.Tags,
+	}
+}
@@ -0,0 +1,307 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"message/app/event"
+	"message/app/repository"
+	"message/app/request"
+	"message/app/response"
+	"message/config"
+	"message/logs"
+	"message/proto/messagepb"
+	"slices"
+)
+
+// subscribeBufferSize 每个订阅缓存的消息数量，缓存满时等待客户端接收
+const subscribeBufferSize = 64
+
+// messageServer 实现 gRPC 的 MessageService，和 HTTP 接口使用相同的参数校验和数据库操作
+type messageServer struct {
+	messagepb.UnimplementedMessageServiceServer
+}
+
+// CreateMessage 创建消息
+func (s *messageServer) CreateMessage(ctx context.Context, req *messagepb.CreateMessageRequest) (*messagepb.Message, error) {
+	messageToken := contextToken(ctx)
+
+	messageCreateRequest := newMessageRequest(req.GetMessage())
+	if err := request.Validate.Struct(messageCreateRequest); err != nil {
+		logs.LogInfo.Infof("RPC-CreateMessage-失败-参数错误 %s", messageToken)
+		return nil, validationError(err)
+	}
+
+	// 创建消息，携带幂等键时重试返回之前创建的消息
+	var message *response.Message
+	var replayed bool
+	var err error
+	if idempotencyKey := req.GetIdempotencyKey(); idempotencyKey != "" {
+		if err := request.Validate.Var(idempotencyKey, "max=255,printascii"); err != nil {
+			return nil, validationError(err)
+		}
+		message, replayed, err = repository.CreateIdempotentMessage(
+			messageToken,
+			idempotencyKey,
+			messageCreateRequest,
+			auditor(ctx),
+		)
+	} else {
+		message, err = repository.CreateMessage(
+			messageToken,
+			messageCreateRequest,
+			auditor(ctx),
+		)
+	}
+
+	if errors.Is(err, repository.ErrIdempotencyKeyReused) {
+		logs.LogInfo.Infof("RPC-CreateMessage-失败-幂等键冲突 %s", messageToken)
+		return nil, apiError(codes.AlreadyExists, response.ErrIdempotencyKeyReused)
+	}
+	if errors.Is(err, repository.ErrIdempotentMessageGone) {
+		logs.LogInfo.Infof("RPC-CreateMessage-失败-消息已删除 %s", messageToken)
+		return nil, apiError(codes.NotFound, response.ErrIdempotentMessageGone)
+	}
+	if err != nil {
+		logs.LogInfo.Infof("RPC-CreateMessage-失败 %s %s", err, messageToken)
+		return nil, apiError(codes.Internal, response.ErrCreateMessageFailed)
+	}
+
+	if !replayed {
+		logs.LogInfo.Infof("RPC-CreateMessage-成功 %s", messageToken)
+	}
+	return newMessage(message), nil
+}
+
+// ListMessages 分页查询接收者的消息
+func (s *messageServer) ListMessages(ctx context.Context, req *messagepb.ListMessagesRequest) (*messagepb.ListMessagesResponse, error) {
+	messageToken := contextToken(ctx)
+
+	messageRequest := &request.MessageRequest{
+		Filter:      req.GetFilter(),
+		SortColumn:  req.GetSortColumn(),
+		SortType:    req.GetSortType(),
+		Page:        int(min(req.GetPage(), 99999999)),
+		PinnedFirst: req.GetPinnedFirst(),
+		Format:      req.GetFormat(),
+	}
+	if err := request.Validate.Struct(messageRequest); err != nil {
+		return nil, validationError(err)
+	}
+
+	var messageFilters []request.MessageFilterRequest
+	if messageRequest.Filter != "" {
+		filters, err := request.ParseMessageFilters(messageRequest.Filter)
+		if err != nil {
+			logs.LogInfo.Infof("RPC-ListMessages-失败-查询过滤语法 %s", messageToken)
+			return nil, validationError(err)
+		}
+		messageFilters = filters
+	}
+
+	logs.LogInfo.Infof("RPC-ListMessages %v %s", messageRequest, messageToken)
+
+	messages := repository.QueryMessagesByMessageTokenMessageRequest(
+		messageToken,
+		messageRequest,
+		messageFilters,
+	)
+
+	limit := config.AppConfig.API.MaxLimit
+	listResponse := &messagepb.ListMessagesResponse{
+		Page:    uint32(messageRequest.Page),
+		Limit:   uint32(limit),
+		HasMore: len(messages) >= limit,
+	}
+	for i := range messages {
+		listResponse.Messages = append(listResponse.Messages, newInboxMessage(&messages[i]))
+	}
+	return listResponse, nil
+}
+
+// GetMessage 查询发送者或接收者可以看到的一条消息
+func (s *messageServer) GetMessage(ctx context.Context, req *messagepb.GetMessageRequest) (*messagepb.Message, error) {
+	messageToken := contextToken(ctx)
+
+	if err := request.Validate.Var(req.GetMessageId(), "required,len=32"); err != nil {
+		return nil, validationError(err)
+	}
+	messageVersionRequest := &request.MessageVersionRequest{
+		Version: uint(req.GetVersion()),
+		Format:  req.GetFormat(),
+	}
+	if err := request.Validate.Struct(messageVersionRequest); err != nil {
+		return nil, validationError(err)
+	}
+
+	logs.LogInfo.Infof("RPC-GetMessage %s %v %s", req.GetMessageId(), messageVersionRequest, messageToken)
+
+	// 根据id查询消息的指定版本
+	var messageResponse *response.Message
+	if message := repository.QueryVisibleMessageById(messageToken, req.GetMessageId()); message != nil {
+		messageResponse = repository.QueryMessageVersion(message, messageVersionRequest.Version)
+	}
+	if messageResponse == nil {
+		return nil, apiError(codes.NotFound, response.ErrNotFound)
+	}
+
+	messageResponse.Render(messageVersionRequest.Format)
+	return newMessage(messageResponse), nil
+}
+
+// UpdateMessage 更新发送者的消息
+func (s *messageServer) UpdateMessage(ctx context.Context, req *messagepb.UpdateMessageRequest) (*messagepb.Message, error) {
+	messageToken := contextToken(ctx)
+
+	if err := request.Validate.Var(req.GetMessageId(), "required,len=32"); err != nil {
+		return nil, validationError(err)
+	}
+	messageUpdateRequest := newMessageRequest(req.GetMessage())
+	if err := request.Validate.Struct(messageUpdateRequest); err != nil {
+		logs.LogInfo.Infof("RPC-UpdateMessage-失败-参数错误 %s", messageToken)
+		return nil, validationError(err)
+	}
+
+	oldMessage := repository.QueryMessageById(messageToken, req.GetMessageId())
+	if oldMessage == nil {
+		return nil, apiError(codes.NotFound, response.ErrNotFound)
+	}
+
+	// 检查 if_match，和 HTTP 接口的 If-Match 请求头相同
+	ifMatch := req.GetIfMatch()
+	if ifMatch == "" && config.AppConfig.API.RequireIfMatch {
+		return nil, apiError(codes.FailedPrecondition, response.ErrPreconditionRequired)
+	}
+	if ifMatch != "" && !response.MatchIfMatch(ifMatch, response.NewMessage(oldMessage)) {
+		logs.LogInfo.Infof("RPC-UpdateMessage-失败-If-Match 条件不满足 %s", messageToken)
+		return nil, apiError(codes.FailedPrecondition, response.ErrPreconditionFailed)
+	}
+
+	messageNew, err := repository.UpdateMessage(oldMessage, messageUpdateRequest, auditor(ctx))
+	if errors.Is(err, repository.ErrMessageNoIntroducer) {
+		return nil, apiError(codes.InvalidArgument, response.ErrIntroducerRequired)
+	}
+	if errors.Is(err, repository.ErrMessageConflict) {
+		logs.LogInfo.Infof("RPC-UpdateMessage-失败-消息已经被修改 %s", messageToken)
+		return nil, apiError(codes.FailedPrecondition, response.ErrPreconditionFailed)
+	}
+	if err != nil {
+		logs.LogInfo.Infof("RPC-UpdateMessage-失败 %s %s", err, messageToken)
+		return nil, apiError(codes.Internal, response.ErrUpdateMessageFailed)
+	}
+
+	logs.LogInfo.Infof("RPC-UpdateMessage-成功 %s", messageToken)
+	return newMessage(messageNew), nil
+}
+
+// UpdateMessageStatus 批量更新消息状态
+func (s *messageServer) UpdateMessageStatus(ctx context.Context, req *messagepb.UpdateMessageStatusRequest) (*messagepb.UpdateMessageStatusResponse, error) {
+	messageToken := contextToken(ctx)
+
+	messageStatusRequests := make([]request.MessageStatusRequest, 0, len(req.GetStatuses()))
+	for _, messageStatus := range req.GetStatuses() {
+		messageStatusRequests = append(messageStatusRequests, request.MessageStatusRequest{
+			Id:     messageStatus.GetMessageId(),
+			Status: toUint8(messageStatus.GetStatus()),
+		})
+	}
+	if err := request.Validate.Var(&messageStatusRequests, "required,gt=0,dive,required"); err != nil {
+		return nil, validationError(err)
+	}
+
+	logs.LogInfo.Infof("RPC-UpdateMessageStatus %v %s", messageStatusRequests, messageToken)
+
+	results := repository.UpdateMessageStatus(messageToken, &messageStatusRequests, auditor(ctx))
+
+	statusResponse := &messagepb.UpdateMessageStatusResponse{}
+	for _, result := range results {
+		statusResponse.Results = append(statusResponse.Results, &messagepb.MessageStatusResult{
+			MessageId: result.Id,
+			Status:    uint32(result.Status),
+			Result:    result.Result,
+		})
+	}
+	return statusResponse, nil
+}
+
+// DeleteMessages 批量删除发送者的消息
+func (s *messageServer) DeleteMessages(ctx context.Context, req *messagepb.DeleteMessagesRequest) (*messagepb.DeleteMessagesResponse, error) {
+	messageToken := contextToken(ctx)
+
+	messageDeleteRequests := make([]request.MessageDeleteRequest, 0, len(req.GetMessageIds()))
+	for _, messageId := range req.GetMessageIds() {
+		messageDeleteRequests = append(messageDeleteRequests, request.MessageDeleteRequest{
+			MessageId: messageId,
+			Delete:    req.GetDelete(),
+		})
+	}
+	if err := request.Validate.Var(&messageDeleteRequests, "required,gt=0,dive,required"); err != nil {
+		return nil, validationError(err)
+	}
+
+	logs.LogInfo.Infof("RPC-DeleteMessages %v %v %s", messageDeleteRequests, req.GetAtomic(), messageToken)
+
+	results := repository.DeleteMessagesById(messageToken, &messageDeleteRequests, req.GetAtomic(), auditor(ctx))
+
+	deleteResponse := &messagepb.DeleteMessagesResponse{}
+	for _, result := range results {
+		deleteResponse.Results = append(deleteResponse.Results, &messagepb.MessageDeleteResult{
+			MessageId: result.Id,
+			Delete:    result.Delete,
+			Status:    result.Status,
+			Result:    result.Result,
+		})
+	}
+	return deleteResponse, nil
+}
+
+// Subscribe 订阅发给自己的新消息，直到客户端断开连接
+func (s *messageServer) Subscribe(req *messagepb.SubscribeRequest, stream messagepb.MessageService_SubscribeServer) error {
+	ctx := stream.Context()
+	messageToken := contextToken(ctx)
+	categories := req.GetCategories()
+
+	// 紧急消息使用单独的通道，总是先发送
+	messages := make(chan *response.Message, subscribeBufferSize)
+	urgentMessages := make(chan *response.Message, subscribeBufferSize)
+	unsubscribe := event.Subscribe(event.MessageCreated, func(e event.Event) error {
+		message, ok := e.Payload.(*response.Message)
+		if !ok || !repository.IsRecipient(message.IntroducerIds, messageToken) {
+			return nil
+		}
+		if len(categories) > 0 && !slices.Contains(categories, message.Category) {
+			return nil
+		}
+
+		// 订阅有自己的协程，等待客户端接收不会影响其他订阅；事件队列满时由 event 包丢弃普通消息
+		target := messages
+		if e.Urgent {
+			target = urgentMessages
+		}
+		select {
+		case target <- message:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	defer unsubscribe()
+
+	logs.LogInfo.Infof("RPC-Subscribe-开始 %v %s", categories, messageToken)
+	for {
+		var message *response.Message
+		select {
+		case message = <-urgentMessages:
+		default:
+			select {
+			case <-ctx.Done():
+				logs.LogInfo.Infof("RPC-Subscribe-结束 %s", messageToken)
+				return nil
+			case message = <-urgentMessages:
+			case message = <-messages:
+			}
+		}
+		if err := stream.Send(newMessage(message)); err != nil {
+			return err
+		}
+	}
+}
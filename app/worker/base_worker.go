@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"message/logs"
+	"sync"
+	"time"
+)
+
+// Worker 定时执行的后台任务
+type Worker struct {
+	// Name 任务名称
+	Name string
+	// Interval 任务执行间隔
+	Interval time.Duration
+	// Run 任务的执行函数
+	Run func() error
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Status 后台任务的运行状态，用于健康检查
+type Status struct {
+	// Name 任务名称
+	Name string
+	// Running 任务是否在运行，Start 之前和停止之后为 false
+	Running bool
+	// LastRunAt 最近一次执行的时间，没有执行过时为零值
+	LastRunAt time.Time
+	// LastError 最近一次执行的错误，成功时为 nil
+	LastError error
+	// Failures 连续失败的次数
+	Failures int
+}
+
+// workers 已注册的后台任务，后台重新连接数据库后才注册时和健康检查并发访问，使用 workersMu 保护
+var workers []*Worker
+var workersMu sync.Mutex
+
+// waitGroup 用于等待所有后台任务停止
+var waitGroup sync.WaitGroup
+
+// Register 注册一个后台任务，需要在 Start 之前调用
+func Register(worker *Worker) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	workers = append(workers, worker)
+}
+
+// Start 启动所有已注册的后台任务，ctx 取消后任务停止
+func Start(ctx context.Context) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	for _, worker := range workers {
+		waitGroup.Add(1)
+		go worker.loop(ctx)
+	}
+}
+
+// Wait 等待所有后台任务停止
+func Wait() {
+	waitGroup.Wait()
+}
+
+// Statuses 返回所有已注册的后台任务的运行状态
+func Statuses() []Status {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	statuses := make([]Status, 0, len(workers))
+	for _, worker := range workers {
+		worker.mu.Lock()
+		status := worker.status
+		worker.mu.Unlock()
+
+		status.Name = worker.Name
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// record 记录任务的运行状态
+func (w *Worker) record(update func(status *Status)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	update(&w.status)
+}
+
+// loop 按照间隔执行任务，直到 ctx 被取消
+func (w *Worker) loop(ctx context.Context) {
+	defer waitGroup.Done()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	logs.LogInfo.Infof("Worker-启动 %s", w.Name)
+	w.record(func(status *Status) { status.Running = true })
+	for {
+		err := w.Run()
+		if err != nil {
+			logs.LogError.Errorf("Worker-失败 %s %s", w.Name, err)
+		}
+		w.record(func(status *Status) {
+			status.LastRunAt = time.Now()
+			status.LastError = err
+			if err != nil {
+				status.Failures++
+			} else {
+				status.Failures = 0
+			}
+		})
+
+		select {
+		case <-ctx.Done():
+			logs.LogInfo.Infof("Worker-停止 %s", w.Name)
+			w.record(func(status *Status) { status.Running = false })
+			return
+		case <-ticker.C:
+		}
+	}
+}
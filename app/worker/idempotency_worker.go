@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"message/app/repository"
+	"message/logs"
+	"time"
+)
+
+// InitIdempotencyWorker 注册清理过期幂等键的后台任务
+func InitIdempotencyWorker() {
+	Register(&Worker{
+		Name:     "idempotency",
+		Interval: time.Hour,
+		Run: func() error {
+			purged, err := repository.PurgeExpiredIdempotencies()
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				logs.LogInfo.Infof("IdempotencyWorker-清理过期的幂等键 %d", purged)
+			}
+			return nil
+		},
+	})
+}
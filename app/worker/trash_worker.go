@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"message/app/repository"
+	"message/config"
+	"message/logs"
+	"time"
+)
+
+// InitTrashWorker 注册自动清理已删除消息的后台任务，purgeDays 为 0 时不清理
+func InitTrashWorker() {
+	purgeDays := config.AppConfig.App.Trash.PurgeDays
+	if purgeDays <= 0 {
+		return
+	}
+
+	Register(&Worker{
+		Name:     "trash",
+		Interval: time.Hour,
+		Run: func() error {
+			// 物理删除软删除超过 purgeDays 天的消息
+			before := time.Now().AddDate(0, 0, -purgeDays)
+			purged, err := repository.PurgeTrashMessages(before)
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				logs.LogInfo.Infof("TrashWorker-清理已删除的消息 %d", purged)
+			}
+			return nil
+		},
+	})
+}
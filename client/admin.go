@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"io"
+	"message/app/request"
+	"message/app/response"
+	"net/http"
+	"net/url"
+)
+
+// AdminMessages 管理员分页查询所有消息，需要使用管理员凭证创建客户端
+func (c *Client) AdminMessages(ctx context.Context, req *request.AdminMessageRequest) ([]response.AdminMessage, *response.PageMeta, error) {
+	var messages []response.AdminMessage
+	meta := &response.PageMeta{}
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/admin/message", query: encodeQuery(req)}, &messages, meta)
+	return messages, meta, err
+}
+
+// AllAdminMessages 管理员从 req.Page 开始遍历所有消息
+func (c *Client) AllAdminMessages(req *request.AdminMessageRequest) *Iterator[response.AdminMessage] {
+	var adminRequest request.AdminMessageRequest
+	if req != nil {
+		adminRequest = *req
+	}
+	return newIterator(adminRequest.Page, func(ctx context.Context, page int) ([]response.AdminMessage, *response.PageMeta, error) {
+		adminRequest.Page = page
+		return c.AdminMessages(ctx, &adminRequest)
+	})
+}
+
+// AdminExport 管理员导出所有消息，按照 req.Format 的格式写入 w
+func (c *Client) AdminExport(ctx context.Context, req *request.MessageExportRequest, w io.Writer) error {
+	return c.stream(ctx, &call{method: http.MethodGet, path: "/admin/message/export", query: encodeQuery(req)}, w)
+}
+
+// AdminImport 管理员从 r 导入消息，导入的数据只能读取一次，所以不会重试
+func (c *Client) AdminImport(ctx context.Context, req *request.MessageImportRequest, r io.Reader) (*response.MessageImportResponse, error) {
+	header := http.Header{}
+	if req != nil && req.Format == "csv" {
+		header.Set("Content-Type", "text/csv")
+	} else {
+		header.Set("Content-Type", "application/x-ndjson")
+	}
+	result := &response.MessageImportResponse{}
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/admin/message/import",
+		query:  encodeQuery(req),
+		header: header,
+		reader: r,
+	}, result, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AdminDeleteMessages 管理员批量撤回消息，atomic 为 true 时任意一条消息撤回失败则全部回滚
+func (c *Client) AdminDeleteMessages(ctx context.Context, reqs []request.MessageDeleteRequest, atomic bool) ([]response.MessageDeleteResponse, error) {
+	var results []response.MessageDeleteResponse
+	err := c.do(ctx, &call{
+		method: http.MethodDelete,
+		path:   "/admin/message",
+		query:  encodeQuery(&request.MessageDeleteQueryRequest{Atomic: atomic}),
+		body:   reqs,
+	}, &results, nil)
+	return results, err
+}
+
+// AdminRestoreMessages 管理员批量恢复软删除的消息
+func (c *Client) AdminRestoreMessages(ctx context.Context, reqs []request.MessageRestoreRequest) ([]response.MessageRestoreResponse, error) {
+	var results []response.MessageRestoreResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/admin/message/restore", body: reqs}, &results, nil)
+	return results, err
+}
+
+// AdminRecipientStatus 管理员查询消息每个接收者的状态
+func (c *Client) AdminRecipientStatus(ctx context.Context, id string) ([]response.MessageRecipientStatusResponse, error) {
+	var recipients []response.MessageRecipientStatusResponse
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/admin/message/" + url.PathEscape(id) + "/status"}, &recipients, nil)
+	return recipients, err
+}
+
+// Audits 管理员分页查询审计日志
+func (c *Client) Audits(ctx context.Context, req *request.AuditRequest) ([]response.Audit, *response.PageMeta, error) {
+	var audits []response.Audit
+	meta := &response.PageMeta{}
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/admin/audit", query: encodeQuery(req)}, &audits, meta)
+	return audits, meta, err
+}
+
+// AllAudits 管理员从 req.Page 开始遍历所有审计日志
+func (c *Client) AllAudits(req *request.AuditRequest) *Iterator[response.Audit] {
+	var auditRequest request.AuditRequest
+	if req != nil {
+		auditRequest = *req
+	}
+	return newIterator(auditRequest.Page, func(ctx context.Context, page int) ([]response.Audit, *response.PageMeta, error) {
+		auditRequest.Page = page
+		return c.Audits(ctx, &auditRequest)
+	})
+}
+
+// AuditExport 管理员导出审计日志，每行一条 JSON 写入 w
+func (c *Client) AuditExport(ctx context.Context, req *request.AuditRequest, w io.Writer) error {
+	return c.stream(ctx, &call{method: http.MethodGet, path: "/admin/audit/export", query: encodeQuery(req)}, w)
+}
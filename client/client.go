@@ -0,0 +1,250 @@
+// Package client 消息服务的 Go 客户端，通过 /v1 接口访问消息服务
+//
+// 请求和返回的数据直接使用服务端的 request 和 response 类型，接口返回的错误转换为 *Error。
+// 查询、更新和删除等幂等的请求以及携带 Idempotency-Key 的创建请求在网络错误或服务端错误时自动重试。
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"message/app/response"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 定义客户端默认设置的常量
+const (
+	DefaultMaxRetries   = 3                      // 默认最多重试的次数
+	DefaultRetryBackoff = 200 * time.Millisecond // 默认第一次重试前等待的时间，之后每次翻倍
+	maxRetryBackoff     = 5 * time.Second        // 重试前最多等待的时间
+)
+
+// Client 消息服务的客户端，可以在多个协程中同时使用
+type Client struct {
+	baseURL      string
+	token        string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// Option 创建客户端时的设置
+type Option func(c *Client)
+
+// WithHTTPClient 使用指定的 http.Client 发送请求
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithMaxRetries 设置最多重试的次数，为 0 时不重试
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// WithRetryBackoff 设置第一次重试前等待的时间，之后每次翻倍
+func WithRetryBackoff(backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retryBackoff = backoff
+	}
+}
+
+// New 创建客户端，baseURL 为消息服务的地址，token 为消息凭证或管理员凭证
+func New(baseURL string, token string, options ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		token:        token,
+		httpClient:   http.DefaultClient,
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// call 一次接口调用的参数
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	// body 请求体，为 nil 时没有请求体
+	body interface{}
+	// reader 不能重试的原始请求体，例如导入的数据
+	reader io.Reader
+}
+
+// envelope /v1 接口统一的响应格式
+type envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *struct {
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+	} `json:"error"`
+	Meta json.RawMessage `json:"meta"`
+}
+
+// newIdempotencyKey 生成随机的幂等键
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	return hex.EncodeToString(key)
+}
+
+// retryable 判断请求是否可以安全地重试
+func (r *call) retryable() bool {
+	if r.reader != nil {
+		return false
+	}
+	switch r.method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.header.Get("Idempotency-Key") != ""
+}
+
+// send 发送请求并返回响应，网络错误和服务端错误时按照设置重试，调用方负责关闭响应体
+func (c *Client) send(ctx context.Context, r *call) (*http.Response, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := c.baseURL + "/v1" + r.path
+	if len(r.query) > 0 {
+		endpoint += "?" + r.query.Encode()
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader = r.reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.method, endpoint, reader)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range r.header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Authorization", c.token)
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		retry := attempt < c.maxRetries && r.retryable()
+		if err == nil && (!retry || !retryStatus(resp.StatusCode)) {
+			return resp, nil
+		}
+		if err != nil && (!retry || ctx.Err() != nil) {
+			return nil, err
+		}
+
+		// 服务端要求等待的时间优先于默认的等待时间
+		wait := backoff
+		if resp != nil {
+			if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// retryStatus 判断响应的状态码是否需要重试
+func retryStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// do 发送请求并将响应的 data 解析到 out 中，meta 不为 nil 时解析分页信息
+func (c *Client) do(ctx context.Context, r *call, out interface{}, meta interface{}) error {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var result envelope
+	if err := json.Unmarshal(data, &result); err != nil {
+		// 不是 /v1 接口的响应，例如代理返回的错误页面
+		return &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+	if resp.StatusCode >= http.StatusBadRequest || result.Error != nil {
+		return newError(resp.StatusCode, &result)
+	}
+
+	if out != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return err
+		}
+	}
+	if meta != nil && len(result.Meta) > 0 {
+		if err := json.Unmarshal(result.Meta, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stream 发送请求并将成功的响应体原样写入 w，用于导出接口
+func (c *Client) stream(ctx context.Context, r *call, w io.Writer) error {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		var result envelope
+		if err := json.Unmarshal(data, &result); err != nil {
+			return &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		}
+		return newError(resp.StatusCode, &result)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return err
+	}
+	// 响应头发送后服务端出错时数据不完整，错误码在 trailer 中
+	if code := resp.Trailer.Get(response.ExportErrorTrailer); code != "" {
+		return &Error{StatusCode: resp.StatusCode, Code: code, Message: "export truncated"}
+	}
+	return nil
+}
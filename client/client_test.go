@@ -0,0 +1,121 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"message/app/request"
+	"message/client"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer 前 failures 次请求返回 status，之后返回成功的 /v1 响应，并记录每次请求的幂等键
+type flakyServer struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests int
+	keys     []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+	failed := s.requests <= s.failures
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if failed {
+		w.WriteHeader(s.status)
+		_, _ = w.Write([]byte(`{"data":null,"error":{"code":"service_unavailable","message":"unavailable"}}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"data":{"message_id":"7e55cb38290f49ee2b0e9cfd2adf13e4"},"error":null}`))
+}
+
+// newFlakyClient 启动 flakyServer 并创建重试等待时间很短的客户端
+func newFlakyClient(t *testing.T, failures int, status int, options ...client.Option) (*client.Client, *flakyServer) {
+	t.Helper()
+	flaky := &flakyServer{failures: failures, status: status}
+	server := httptest.NewServer(flaky)
+	t.Cleanup(server.Close)
+	options = append([]client.Option{client.WithRetryBackoff(time.Millisecond)}, options...)
+	return client.New(server.URL, "token", options...), flaky
+}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	c, flaky := newFlakyClient(t, 2, http.StatusServiceUnavailable)
+
+	message, err := c.Message(context.Background(), "7e55cb38290f49ee2b0e9cfd2adf13e4", nil)
+	if err != nil {
+		t.Fatalf("Message: %s", err)
+	}
+	if message.MessageId != "7e55cb38290f49ee2b0e9cfd2adf13e4" || flaky.requests != 3 {
+		t.Fatalf("Message = %+v after %d requests, want success after 3", message, flaky.requests)
+	}
+}
+
+func TestRetryCreateMessageWithSameIdempotencyKey(t *testing.T) {
+	c, flaky := newFlakyClient(t, 2, http.StatusBadGateway)
+
+	if _, err := c.CreateMessage(context.Background(), &request.MessageCreateUpdateRequest{}, ""); err != nil {
+		t.Fatalf("CreateMessage: %s", err)
+	}
+	if flaky.requests != 3 {
+		t.Fatalf("CreateMessage sent %d requests, want 3", flaky.requests)
+	}
+	if flaky.keys[0] == "" || flaky.keys[1] != flaky.keys[0] || flaky.keys[2] != flaky.keys[0] {
+		t.Fatalf("Idempotency-Key = %q, want the same generated key on every attempt", flaky.keys)
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	c, flaky := newFlakyClient(t, 10, http.StatusServiceUnavailable, client.WithMaxRetries(2))
+
+	_, err := c.Message(context.Background(), "7e55cb38290f49ee2b0e9cfd2adf13e4", nil)
+	var apiErr *client.Error
+	if !errors.Is(err, client.ErrServiceUnavailable) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Message = %v, want ErrServiceUnavailable", err)
+	}
+	if flaky.requests != 3 {
+		t.Fatalf("Message sent %d requests, want 3", flaky.requests)
+	}
+}
+
+func TestNoRetryForUnsafeOrClientErrors(t *testing.T) {
+	// 批量创建没有幂等键，不能重试
+	c, flaky := newFlakyClient(t, 1, http.StatusServiceUnavailable)
+	if _, err := c.CreateMessages(context.Background(), nil, false); !errors.Is(err, client.ErrServiceUnavailable) {
+		t.Fatalf("CreateMessages = %v, want ErrServiceUnavailable", err)
+	}
+	if flaky.requests != 1 {
+		t.Fatalf("CreateMessages sent %d requests, want 1", flaky.requests)
+	}
+
+	// 4xx 不是临时的错误
+	c, flaky = newFlakyClient(t, 1, http.StatusNotFound)
+	if _, err := c.Message(context.Background(), "7e55cb38290f49ee2b0e9cfd2adf13e4", nil); err == nil {
+		t.Fatal("Message succeeded, want the 404 error")
+	}
+	if flaky.requests != 1 {
+		t.Fatalf("Message sent %d requests, want 1", flaky.requests)
+	}
+}
+
+func TestRetryStopsWhenContextIsCanceled(t *testing.T) {
+	c, flaky := newFlakyClient(t, 10, http.StatusServiceUnavailable, client.WithRetryBackoff(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.Message(ctx, "7e55cb38290f49ee2b0e9cfd2adf13e4", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Message = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || flaky.requests != 1 {
+		t.Fatalf("Message returned after %s and %d requests, want 1 request and an early return", elapsed, flaky.requests)
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"message/app/request"
+	"message/app/response"
+)
+
+// Error 接口返回的错误
+//
+// 可以使用 errors.Is 和 ErrNotFound 等错误比较，比较时只判断错误码。
+type Error struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+	// Code 稳定的错误码，不是 /v1 接口的响应时为空
+	Code string
+	// Message 服务端翻译后的错误信息
+	Message string
+	// ValidationErrors 参数校验失败的字段，只在 Code 为 validation_failed 时存在
+	ValidationErrors []request.ValidationError
+	// Current 更新时消息已经被修改，服务端返回的当前消息，只在 Code 为 precondition_failed 时存在
+	Current *response.Message
+	// Details 原始的错误详细信息
+	Details json.RawMessage
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("message: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("message: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is 错误码相同时认为是同一个错误
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// 定义可以使用 errors.Is 比较的错误，错误码和服务端的 response.APIError 相同
+var (
+	ErrInvalidRequest        = &Error{Code: response.ErrInvalidRequest.Code}
+	ErrValidationFailed      = &Error{Code: response.ErrValidationFailed.Code}
+	ErrIntroducerRequired    = &Error{Code: response.ErrIntroducerRequired.Code}
+	ErrUnauthorized          = &Error{Code: response.ErrUnauthorized.Code}
+	ErrNotFound              = &Error{Code: response.ErrNotFound.Code}
+	ErrMessageActionChosen   = &Error{Code: response.ErrMessageActionChosen.Code}
+	ErrIdempotencyKeyReused  = &Error{Code: response.ErrIdempotencyKeyReused.Code}
+	ErrIdempotentMessageGone = &Error{Code: response.ErrIdempotentMessageGone.Code}
+	ErrPreconditionFailed    = &Error{Code: response.ErrPreconditionFailed.Code}
+	ErrPreconditionRequired  = &Error{Code: response.ErrPreconditionRequired.Code}
+	ErrCreateMessageFailed   = &Error{Code: response.ErrCreateMessageFailed.Code}
+	ErrUpdateMessageFailed   = &Error{Code: response.ErrUpdateMessageFailed.Code}
+	ErrInternal              = &Error{Code: response.ErrInternal.Code}
+	ErrServiceUnavailable    = &Error{Code: response.ErrServiceUnavailable.Code}
+)
+
+// newError 根据 /v1 接口的响应创建错误，并解析校验错误和当前消息等详细信息
+func newError(status int, result *envelope) *Error {
+	apiErr := &Error{StatusCode: status}
+	if result.Error == nil {
+		return apiErr
+	}
+
+	apiErr.Code = result.Error.Code
+	apiErr.Message = result.Error.Message
+	apiErr.Details = result.Error.Details
+	if len(apiErr.Details) == 0 {
+		return apiErr
+	}
+
+	switch apiErr.Code {
+	case response.ErrValidationFailed.Code:
+		_ = json.Unmarshal(apiErr.Details, &apiErr.ValidationErrors)
+	case response.ErrPreconditionFailed.Code:
+		apiErr.Current = &response.Message{}
+		if json.Unmarshal(apiErr.Details, apiErr.Current) != nil {
+			apiErr.Current = nil
+		}
+	}
+	return apiErr
+}
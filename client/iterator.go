@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"message/app/response"
+)
+
+// pageFunc 查询第 page 页数据的函数
+type pageFunc[T any] func(ctx context.Context, page int) ([]T, *response.PageMeta, error)
+
+// Iterator 逐条遍历分页查询的结果，当前页遍历完后自动查询下一页
+//
+//	it := c.AllMessages(&request.MessageRequest{Filter: "status = 0"})
+//	for it.Next(ctx) {
+//		message := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	fetch   pageFunc[T]
+	page    int
+	items   []T
+	index   int
+	current T
+	more    bool
+	err     error
+}
+
+// newIterator 创建从第 page 页开始遍历的迭代器，page 为 0 时从第一页开始
+func newIterator[T any](page int, fetch pageFunc[T]) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, page: max(page, 1), more: true}
+}
+
+// Next 移动到下一条数据，没有更多数据或者出现错误时返回 false
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	for it.index >= len(it.items) {
+		if !it.more {
+			return false
+		}
+		items, meta, err := it.fetch(ctx, it.page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.items = items
+		it.index = 0
+		it.page++
+		// 没有分页信息时根据是否返回了数据判断
+		it.more = len(items) > 0
+		if meta != nil {
+			it.more = meta.HasMore
+		}
+	}
+	it.current = it.items[it.index]
+	it.index++
+	return true
+}
+
+// Value 返回当前的数据
+func (it *Iterator[T]) Value() T {
+	return it.current
+}
+
+// Err 返回遍历时出现的错误
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
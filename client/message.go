@@ -0,0 +1,258 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"message/app/request"
+	"message/app/response"
+	"net/http"
+	"net/url"
+)
+
+// Messages 分页查询接收者的消息，req.Page 为查询的页数
+func (c *Client) Messages(ctx context.Context, req *request.MessageRequest) ([]response.InboxMessage, *response.PageMeta, error) {
+	var messages []response.InboxMessage
+	meta := &response.PageMeta{}
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/message", query: encodeQuery(req)}, &messages, meta)
+	return messages, meta, err
+}
+
+// AllMessages 从 req.Page 开始遍历接收者的所有消息
+func (c *Client) AllMessages(req *request.MessageRequest) *Iterator[response.InboxMessage] {
+	var messageRequest request.MessageRequest
+	if req != nil {
+		messageRequest = *req
+	}
+	return newIterator(messageRequest.Page, func(ctx context.Context, page int) ([]response.InboxMessage, *response.PageMeta, error) {
+		messageRequest.Page = page
+		return c.Messages(ctx, &messageRequest)
+	})
+}
+
+// Trash 分页查询接收者已删除的消息
+func (c *Client) Trash(ctx context.Context, req *request.MessageRequest) ([]response.TrashMessage, *response.PageMeta, error) {
+	var messages []response.TrashMessage
+	meta := &response.PageMeta{}
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/message/trash", query: encodeQuery(req)}, &messages, meta)
+	return messages, meta, err
+}
+
+// AllTrash 从 req.Page 开始遍历接收者所有已删除的消息
+func (c *Client) AllTrash(req *request.MessageRequest) *Iterator[response.TrashMessage] {
+	var messageRequest request.MessageRequest
+	if req != nil {
+		messageRequest = *req
+	}
+	return newIterator(messageRequest.Page, func(ctx context.Context, page int) ([]response.TrashMessage, *response.PageMeta, error) {
+		messageRequest.Page = page
+		return c.Trash(ctx, &messageRequest)
+	})
+}
+
+// Search 分页搜索接收者的消息
+func (c *Client) Search(ctx context.Context, req *request.MessageSearchRequest) ([]response.MessageSearchResult, *response.PageMeta, error) {
+	var messages []response.MessageSearchResult
+	meta := &response.PageMeta{}
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/message/search", query: encodeQuery(req)}, &messages, meta)
+	return messages, meta, err
+}
+
+// AllSearchResults 从 req.Page 开始遍历所有搜索到的消息
+func (c *Client) AllSearchResults(req *request.MessageSearchRequest) *Iterator[response.MessageSearchResult] {
+	var searchRequest request.MessageSearchRequest
+	if req != nil {
+		searchRequest = *req
+	}
+	return newIterator(searchRequest.Page, func(ctx context.Context, page int) ([]response.MessageSearchResult, *response.PageMeta, error) {
+		searchRequest.Page = page
+		return c.Search(ctx, &searchRequest)
+	})
+}
+
+// Message 查询发送者或接收者可以看到的一条消息，req 为 nil 时返回当前版本
+func (c *Client) Message(ctx context.Context, id string, req *request.MessageVersionRequest) (*response.Message, error) {
+	message := &response.Message{}
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/message/" + url.PathEscape(id), query: encodeQuery(req)}, message, nil)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// History 查询消息的所有版本
+func (c *Client) History(ctx context.Context, id string) ([]response.MessageVersion, error) {
+	var versions []response.MessageVersion
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/message/" + url.PathEscape(id) + "/history"}, &versions, nil)
+	return versions, err
+}
+
+// ChooseAction 接收者点击消息的操作按钮
+func (c *Client) ChooseAction(ctx context.Context, id string, actionId string) (*response.MessageActionResponse, error) {
+	chosen := &response.MessageActionResponse{}
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/message/" + url.PathEscape(id) + "/actions/" + url.PathEscape(actionId),
+	}, chosen, nil)
+	if err != nil {
+		return nil, err
+	}
+	return chosen, nil
+}
+
+// CreateMessage 创建消息
+//
+// idempotencyKey 为空时自动生成，保证重试时只创建一条消息。
+func (c *Client) CreateMessage(ctx context.Context, req *request.MessageCreateUpdateRequest, idempotencyKey string) (*response.Message, error) {
+	if idempotencyKey == "" {
+		idempotencyKey = newIdempotencyKey()
+	}
+	message := &response.Message{}
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/message",
+		header: http.Header{"Idempotency-Key": {idempotencyKey}},
+		body:   req,
+	}, message, nil)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// CreateMessages 批量创建消息，atomic 为 true 时任意一条消息失败则全部不创建
+//
+// 批量创建不支持幂等键，所以不会重试。
+func (c *Client) CreateMessages(ctx context.Context, reqs []request.MessageCreateUpdateRequest, atomic bool) ([]response.MessageBatchResponse, error) {
+	var results []response.MessageBatchResponse
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/message/batch",
+		query:  encodeQuery(&request.MessageBatchQueryRequest{Atomic: atomic}),
+		body:   reqs,
+	}, &results, nil)
+	return results, err
+}
+
+// UpdateMessage 更新发送者的消息，ifMatch 为消息的 ETag，消息已经被修改时返回 ErrPreconditionFailed
+//
+// ifMatch 可以使用 response.MessageETag 生成，为空时不检查。
+func (c *Client) UpdateMessage(ctx context.Context, id string, req *request.MessageCreateUpdateRequest, ifMatch string) (*response.Message, error) {
+	return c.updateMessage(ctx, http.MethodPut, id, req, ifMatch)
+}
+
+// PatchMessage 部分更新发送者的消息，只更新不为 nil 的字段
+//
+// 结构化数据按照 JSON Merge Patch 合并，值为 nil 的键被删除；设置 ClearData 时清空结构化数据，清空操作按钮时使用空的切片。
+func (c *Client) PatchMessage(ctx context.Context, id string, req *request.MessagePatchRequest, ifMatch string) (*response.Message, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	// 服务端按照 JSON Merge Patch 的语义处理 null，所以去掉值为 null 的字段
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		if string(value) == "null" {
+			delete(fields, key)
+		}
+	}
+	if req.ClearData {
+		fields["data"] = json.RawMessage("null")
+	}
+	return c.updateMessage(ctx, http.MethodPatch, id, fields, ifMatch)
+}
+
+// updateMessage 发送更新消息的请求，ifMatch 不为空时携带 If-Match 请求头
+func (c *Client) updateMessage(ctx context.Context, method string, id string, body interface{}, ifMatch string) (*response.Message, error) {
+	header := http.Header{}
+	if ifMatch != "" {
+		header.Set("If-Match", ifMatch)
+	}
+	message := &response.Message{}
+	err := c.do(ctx, &call{
+		method: method,
+		path:   "/message/" + url.PathEscape(id),
+		header: header,
+		body:   body,
+	}, message, nil)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// UpdateStatus 批量更新消息状态
+func (c *Client) UpdateStatus(ctx context.Context, reqs []request.MessageStatusRequest) ([]response.MessageStatusResponse, error) {
+	var results []response.MessageStatusResponse
+	err := c.do(ctx, &call{method: http.MethodPut, path: "/message/status", body: reqs}, &results, nil)
+	return results, err
+}
+
+// Flag 批量设置接收者的置顶和标星
+func (c *Client) Flag(ctx context.Context, reqs []request.MessageFlagRequest) ([]response.MessageFlagResponse, error) {
+	var results []response.MessageFlagResponse
+	err := c.do(ctx, &call{method: http.MethodPut, path: "/message/flags", body: reqs}, &results, nil)
+	return results, err
+}
+
+// Tags 查询接收者的所有标签和每个标签的消息数量
+func (c *Client) Tags(ctx context.Context) ([]response.TagCount, error) {
+	var tags []response.TagCount
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/message/tags"}, &tags, nil)
+	return tags, err
+}
+
+// AddTags 批量给消息添加标签
+func (c *Client) AddTags(ctx context.Context, reqs []request.MessageTagRequest) ([]response.MessageTagResponse, error) {
+	var results []response.MessageTagResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/message/tags", body: reqs}, &results, nil)
+	return results, err
+}
+
+// RemoveTags 批量移除消息的标签
+func (c *Client) RemoveTags(ctx context.Context, reqs []request.MessageTagRequest) ([]response.MessageTagResponse, error) {
+	var results []response.MessageTagResponse
+	err := c.do(ctx, &call{method: http.MethodDelete, path: "/message/tags", body: reqs}, &results, nil)
+	return results, err
+}
+
+// DeleteMessages 批量删除发送者的消息，atomic 为 true 时任意一条消息删除失败则全部回滚
+func (c *Client) DeleteMessages(ctx context.Context, reqs []request.MessageDeleteRequest, atomic bool) ([]response.MessageDeleteResponse, error) {
+	var results []response.MessageDeleteResponse
+	err := c.do(ctx, &call{
+		method: http.MethodDelete,
+		path:   "/message",
+		query:  encodeQuery(&request.MessageDeleteQueryRequest{Atomic: atomic}),
+		body:   reqs,
+	}, &results, nil)
+	return results, err
+}
+
+// RestoreMessages 批量恢复发送者软删除的消息
+func (c *Client) RestoreMessages(ctx context.Context, reqs []request.MessageRestoreRequest) ([]response.MessageRestoreResponse, error) {
+	var results []response.MessageRestoreResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/message/restore", body: reqs}, &results, nil)
+	return results, err
+}
+
+// HideMessages 接收者批量删除收件箱中的消息，不影响其他接收者
+func (c *Client) HideMessages(ctx context.Context, reqs []request.MessageHideRequest) ([]response.MessageDeleteResponse, error) {
+	var results []response.MessageDeleteResponse
+	err := c.do(ctx, &call{method: http.MethodDelete, path: "/message/inbox", body: reqs}, &results, nil)
+	return results, err
+}
+
+// RetractMessages 发送者批量撤回消息
+func (c *Client) RetractMessages(ctx context.Context, reqs []request.MessageRetractRequest) ([]response.MessageDeleteResponse, error) {
+	var results []response.MessageDeleteResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/message/retract", body: reqs}, &results, nil)
+	return results, err
+}
+
+// Export 导出接收者的消息，按照 req.Format 的格式写入 w
+func (c *Client) Export(ctx context.Context, req *request.MessageExportRequest, w io.Writer) error {
+	return c.stream(ctx, &call{method: http.MethodGet, path: "/message/export", query: encodeQuery(req)}, w)
+}
@@ -0,0 +1,253 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"message/app/model"
+	"message/app/request"
+	"message/app/response"
+	"message/client"
+	"message/database"
+	"message/testutil"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// testService 使用测试数据库的消息服务和访问服务的客户端
+type testService struct {
+	url            string
+	sender         *client.Client
+	recipient      *client.Client
+	recipientToken string
+}
+
+// newTestService 启动使用测试数据库的消息服务，并创建发送者和接收者的客户端
+func newTestService(t *testing.T) *testService {
+	t.Helper()
+	testutil.Setup(t)
+	server := httptest.NewServer(testutil.NewRouter())
+	t.Cleanup(server.Close)
+
+	recipientToken := testutil.Token(t)
+	return &testService{
+		url:            server.URL,
+		sender:         client.New(server.URL, testutil.Token(t)),
+		recipient:      client.New(server.URL, recipientToken),
+		recipientToken: recipientToken,
+	}
+}
+
+// newMessageRequest 创建发给 recipient 的消息的请求
+func newMessageRequest(title string, recipient string) *request.MessageCreateUpdateRequest {
+	return &request.MessageCreateUpdateRequest{
+		Title:         title,
+		Content:       "内容",
+		Category:      "notice",
+		BigContent:    "# 详细内容",
+		ContentType:   "markdown",
+		IntroducerIds: []string{recipient},
+		Data:          map[string]interface{}{"order": "A1", "link": "/orders/A1"},
+	}
+}
+
+func TestMessageLifecycle(t *testing.T) {
+	service := newTestService(t)
+	sender, recipient, recipientToken := service.sender, service.recipient, service.recipientToken
+	ctx := context.Background()
+
+	created, err := sender.CreateMessage(ctx, newMessageRequest("标题", recipientToken), "")
+	if err != nil {
+		t.Fatalf("CreateMessage: %s", err)
+	}
+	if created.Version != 1 || created.Priority != model.PriorityNormal || created.Data["order"] != "A1" {
+		t.Fatalf("CreateMessage = %+v", created)
+	}
+
+	messages, meta, err := recipient.Messages(ctx, nil)
+	if err != nil {
+		t.Fatalf("Messages: %s", err)
+	}
+	if len(messages) != 1 || messages[0].MessageId != created.MessageId || meta.Count != 1 || meta.HasMore {
+		t.Fatalf("Messages = %+v, %+v", messages, meta)
+	}
+
+	shown, err := recipient.Message(ctx, created.MessageId, nil)
+	if err != nil {
+		t.Fatalf("Message: %s", err)
+	}
+	if shown.Title != "标题" || shown.BigContent != "# 详细内容" {
+		t.Fatalf("Message = %+v", shown)
+	}
+
+	update := newMessageRequest("新标题", recipientToken)
+	update.Priority = "high"
+	updated, err := sender.UpdateMessage(ctx, created.MessageId, update, response.MessageETag(created))
+	if err != nil {
+		t.Fatalf("UpdateMessage: %s", err)
+	}
+	if updated.Title != "新标题" || updated.Version != 2 || updated.Priority != model.PriorityHigh {
+		t.Fatalf("UpdateMessage = %+v", updated)
+	}
+
+	title := "部分更新"
+	patched, err := sender.PatchMessage(ctx, created.MessageId, &request.MessagePatchRequest{
+		Title: &title,
+		Data:  map[string]interface{}{"link": nil, "status": "paid"},
+	}, response.MessageETag(updated))
+	if err != nil {
+		t.Fatalf("PatchMessage: %s", err)
+	}
+	if patched.Title != title || patched.Version != 3 || patched.Content != "内容" {
+		t.Fatalf("PatchMessage = %+v", patched)
+	}
+	if _, ok := patched.Data["link"]; ok || patched.Data["order"] != "A1" || patched.Data["status"] != "paid" {
+		t.Fatalf("PatchMessage data = %v, want link removed and status merged", patched.Data)
+	}
+
+	versions, err := sender.History(ctx, created.MessageId)
+	if err != nil {
+		t.Fatalf("History: %s", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("History returned %d versions, want 3", len(versions))
+	}
+}
+
+func TestUpdateMessageIfMatch(t *testing.T) {
+	service := newTestService(t)
+	sender, recipientToken := service.sender, service.recipientToken
+	ctx := context.Background()
+
+	created, err := sender.CreateMessage(ctx, newMessageRequest("标题", recipientToken), "")
+	if err != nil {
+		t.Fatalf("CreateMessage: %s", err)
+	}
+	etag := response.MessageETag(created)
+	if _, err := sender.UpdateMessage(ctx, created.MessageId, newMessageRequest("第一次", recipientToken), etag); err != nil {
+		t.Fatalf("UpdateMessage: %s", err)
+	}
+
+	// 使用旧的 ETag 再次更新时返回当前的消息
+	_, err = sender.UpdateMessage(ctx, created.MessageId, newMessageRequest("第二次", recipientToken), etag)
+	if !errors.Is(err, client.ErrPreconditionFailed) {
+		t.Fatalf("UpdateMessage with a stale ETag = %v, want ErrPreconditionFailed", err)
+	}
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 412 || apiErr.Current == nil {
+		t.Fatalf("UpdateMessage error = %#v, want 412 with the current message", err)
+	}
+	if apiErr.Current.Title != "第一次" || apiErr.Current.Version != 2 {
+		t.Fatalf("current message = %+v", apiErr.Current)
+	}
+
+	// 使用当前消息的 ETag 重试成功
+	title := "第二次"
+	patched, err := sender.PatchMessage(ctx, created.MessageId, &request.MessagePatchRequest{Title: &title}, response.MessageETag(apiErr.Current))
+	if err != nil {
+		t.Fatalf("PatchMessage with the current ETag: %s", err)
+	}
+	if patched.Title != title || patched.Version != 3 {
+		t.Fatalf("PatchMessage = %+v", patched)
+	}
+}
+
+func TestMessagesPagination(t *testing.T) {
+	service := newTestService(t)
+	sender, recipient, recipientToken := service.sender, service.recipient, service.recipientToken
+	ctx := context.Background()
+
+	const total = 20
+	reqs := make([]request.MessageCreateUpdateRequest, 0, total)
+	for i := 0; i < total; i++ {
+		reqs = append(reqs, *newMessageRequest("标题"+strconv.Itoa(i), recipientToken))
+	}
+	results, err := sender.CreateMessages(ctx, reqs, true)
+	if err != nil {
+		t.Fatalf("CreateMessages: %s", err)
+	}
+	if len(results) != total {
+		t.Fatalf("CreateMessages returned %d results, want %d", len(results), total)
+	}
+
+	first, meta, err := recipient.Messages(ctx, &request.MessageRequest{Page: 1})
+	if err != nil {
+		t.Fatalf("Messages: %s", err)
+	}
+	if len(first) != meta.Limit || meta.Count != len(first) || !meta.HasMore {
+		t.Fatalf("first page has %d messages, meta = %+v", len(first), meta)
+	}
+
+	seen := make(map[string]bool)
+	it := recipient.AllMessages(&request.MessageRequest{SortColumn: "title", SortType: "asc"})
+	for it.Next(ctx) {
+		id := it.Value().MessageId
+		if seen[id] {
+			t.Fatalf("AllMessages returned %s twice", id)
+		}
+		seen[id] = true
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("AllMessages: %s", err)
+	}
+	if len(seen) != total {
+		t.Fatalf("AllMessages returned %d messages, want %d", len(seen), total)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	service := newTestService(t)
+	sender, recipient, recipientToken := service.sender, service.recipient, service.recipientToken
+	ctx := context.Background()
+
+	if _, err := sender.Message(ctx, "00000000000000000000000000000000", nil); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("Message with an unknown id = %v, want ErrNotFound", err)
+	}
+
+	invalid := newMessageRequest("", recipientToken)
+	invalid.Priority = "highest"
+	_, err := sender.CreateMessage(ctx, invalid, "")
+	var apiErr *client.Error
+	if !errors.Is(err, client.ErrValidationFailed) || !errors.As(err, &apiErr) {
+		t.Fatalf("CreateMessage with invalid fields = %v, want ErrValidationFailed", err)
+	}
+	fields := make(map[string]bool)
+	for _, validationError := range apiErr.ValidationErrors {
+		fields[validationError.Field] = true
+	}
+	if !fields["Title"] || !fields["Priority"] {
+		t.Fatalf("ValidationErrors = %+v, want title and priority", apiErr.ValidationErrors)
+	}
+
+	created, err := sender.CreateMessage(ctx, newMessageRequest("标题", recipientToken), "key-1")
+	if err != nil {
+		t.Fatalf("CreateMessage: %s", err)
+	}
+	replayed, err := sender.CreateMessage(ctx, newMessageRequest("标题", recipientToken), "key-1")
+	if err != nil || replayed.MessageId != created.MessageId {
+		t.Fatalf("CreateMessage replay = %v, %v, want %s", replayed, err, created.MessageId)
+	}
+	if _, err := sender.CreateMessage(ctx, newMessageRequest("其他", recipientToken), "key-1"); !errors.Is(err, client.ErrIdempotencyKeyReused) {
+		t.Fatalf("CreateMessage reusing a key = %v, want ErrIdempotencyKeyReused", err)
+	}
+
+	// 消息被回收站彻底删除后，同一个幂等键不能再返回消息
+	database.DB.Unscoped().Where("message_id = ?", created.MessageId).Delete(&model.Message{})
+	if _, err := sender.CreateMessage(ctx, newMessageRequest("标题", recipientToken), "key-1"); !errors.Is(err, client.ErrIdempotentMessageGone) {
+		t.Fatalf("CreateMessage replaying a purged message = %v, want ErrIdempotentMessageGone", err)
+	}
+
+	// 接收者不能修改消息
+	other, err := sender.CreateMessage(ctx, newMessageRequest("标题", recipientToken), "")
+	if err != nil {
+		t.Fatalf("CreateMessage: %s", err)
+	}
+	if _, err := recipient.UpdateMessage(ctx, other.MessageId, newMessageRequest("修改", recipientToken), ""); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("UpdateMessage by a recipient = %v, want ErrNotFound", err)
+	}
+
+	unknown := client.New(service.url, "ffffffffffffffffffffffffffffffff")
+	if _, _, err := unknown.Messages(ctx, nil); !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("Messages with an unknown token = %v, want ErrUnauthorized", err)
+	}
+}
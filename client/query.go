@@ -0,0 +1,48 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// encodeQuery 根据结构体的 form 标签生成查询参数，和服务端绑定查询参数的规则相同，忽略零值
+func encodeQuery(object interface{}) url.Values {
+	values := url.Values{}
+	if object == nil {
+		return values
+	}
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return values
+		}
+		value = value.Elem()
+	}
+	encodeStruct(values, value)
+	return values
+}
+
+// encodeStruct 将结构体的字段添加到查询参数中，包括匿名嵌入的结构体
+func encodeStruct(values url.Values, value reflect.Value) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		fieldValue := value.Field(i)
+		if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+			encodeStruct(values, fieldValue)
+			continue
+		}
+
+		name := field.Tag.Get("form")
+		if name == "" || name == "-" || fieldValue.IsZero() {
+			continue
+		}
+		if t, ok := fieldValue.Interface().(time.Time); ok {
+			values.Set(name, t.Format(time.RFC3339))
+			continue
+		}
+		values.Set(name, fmt.Sprint(fieldValue.Interface()))
+	}
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"message/app/request"
+	"message/app/response"
+	"message/client"
+	"os"
+	"strings"
+	"time"
+)
+
+// stringList 可以重复指定或者用逗号分隔的参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*s = append(*s, item)
+		}
+	}
+	return nil
+}
+
+// requireArgs 检查至少有一个位置参数
+func requireArgs(flags *flag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// runSend 发送消息
+func runSend(ctx context.Context, args []string) error {
+	flags, opts := newFlagSet("send", "[flags]")
+	file := flags.String("file", "", "从 JSON 文件读取消息，- 表示标准输入，其他参数会覆盖文件中的字段")
+	title := flags.String("title", "", "标题")
+	content := flags.String("content", "", "简单的内容")
+	category := flags.String("category", "", "消息类型")
+	bigContent := flags.String("big-content", "", "复杂消息，为空时和 --content 相同")
+	contentType := flags.String("content-type", "", "复杂消息的格式（text/markdown/html）")
+	priority := flags.String("priority", "", "优先级（low/normal/high/urgent），为空时根据消息类型设置")
+	data := flags.String("data", "", "附带的结构化数据（JSON 对象）")
+	idempotencyKey := flags.String("idempotency-key", "", "幂等键，为空时自动生成")
+	var introducerIds stringList
+	flags.Var(&introducerIds, "to", "接收者的凭证，可以重复指定或者用逗号分隔")
+	if _, err := parse(flags, opts, args); err != nil {
+		return err
+	}
+
+	message := &request.MessageCreateUpdateRequest{}
+	if *file != "" {
+		if err := readJSON(*file, message); err != nil {
+			return err
+		}
+	}
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			message.Title = *title
+		case "content":
+			message.Content = *content
+		case "category":
+			message.Category = *category
+		case "big-content":
+			message.BigContent = *bigContent
+		case "content-type":
+			message.ContentType = *contentType
+		case "priority":
+			message.Priority = *priority
+		case "to":
+			message.IntroducerIds = introducerIds
+		case "data":
+			if jsonErr := json.Unmarshal([]byte(*data), &message.Data); jsonErr != nil {
+				err = fmt.Errorf("--data 不是 JSON 对象: %w", jsonErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if message.BigContent == "" {
+		message.BigContent = message.Content
+	}
+
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	created, err := c.CreateMessage(ctx, message, *idempotencyKey)
+	if err != nil {
+		return describeError(err)
+	}
+	return opts.printer().message(created)
+}
+
+// readJSON 从文件读取 JSON，path 为 - 时从标准输入读取
+func readJSON(path string, value interface{}) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	if err := json.NewDecoder(r).Decode(value); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// runList 查询收到的消息
+func runList(ctx context.Context, args []string) error {
+	flags, opts := newFlagSet("list", "[flags]")
+	messageRequest := &request.MessageRequest{}
+	flags.StringVar(&messageRequest.Filter, "filter", "", "过滤语句，语法和接口的 filter 参数相同，例如 \"status = 0,category = notice\"")
+	flags.StringVar(&messageRequest.SortColumn, "sort", "", "排序列")
+	flags.StringVar(&messageRequest.SortType, "order", "", "排序类型（asc/desc）")
+	flags.IntVar(&messageRequest.Page, "page", 1, "查询第几页")
+	flags.BoolVar(&messageRequest.PinnedFirst, "pinned-first", false, "置顶的消息排在最前面")
+	flags.StringVar(&messageRequest.Format, "format", "", "复杂消息的返回格式（html/text）")
+	all := flags.Bool("all", false, "从 --page 开始查询所有页")
+	trash := flags.Bool("trash", false, "查询回收站中的消息")
+	if _, err := parse(flags, opts, args); err != nil {
+		return err
+	}
+
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	if *trash {
+		var messages []response.TrashMessage
+		if *all {
+			it := c.AllTrash(messageRequest)
+			for it.Next(ctx) {
+				messages = append(messages, it.Value())
+			}
+			err = it.Err()
+		} else {
+			messages, _, err = c.Trash(ctx, messageRequest)
+		}
+		if err != nil {
+			return describeError(err)
+		}
+		rows := make([]response.Message, 0, len(messages))
+		for _, message := range messages {
+			rows = append(rows, message.Message)
+		}
+		return opts.printer().messages(rows, nonNil(messages))
+	}
+
+	var messages []response.InboxMessage
+	if *all {
+		it := c.AllMessages(messageRequest)
+		for it.Next(ctx) {
+			messages = append(messages, it.Value())
+		}
+		err = it.Err()
+	} else {
+		messages, _, err = c.Messages(ctx, messageRequest)
+	}
+	if err != nil {
+		return describeError(err)
+	}
+	rows := make([]response.Message, 0, len(messages))
+	for _, message := range messages {
+		rows = append(rows, message.Message)
+	}
+	return opts.printer().messages(rows, nonNil(messages))
+}
+
+// nonNil 没有数据时输出 [] 而不是 null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// runShow 查看一条消息
+func runShow(ctx context.Context, args []string) error {
+	flags, opts := newFlagSet("show", "[flags] <id>")
+	versionRequest := &request.MessageVersionRequest{}
+	flags.UintVar(&versionRequest.Version, "version", 0, "消息版本，为 0 时查看当前版本")
+	flags.StringVar(&versionRequest.Format, "format", "", "复杂消息的返回格式（html/text）")
+	ids, err := parse(flags, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := requireArgs(flags, ids); err != nil {
+		return err
+	}
+
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	message, err := c.Message(ctx, ids[0], versionRequest)
+	if err != nil {
+		return describeError(err)
+	}
+	return opts.printer().message(message)
+}
+
+// statusCommand 返回将消息修改为 status 状态的 name 命令
+func statusCommand(name string, status uint8) func(ctx context.Context, args []string) error {
+	return func(ctx context.Context, args []string) error {
+		flags, opts := newFlagSet(name, "[flags] <id>...")
+		ids, err := parse(flags, opts, args)
+		if err != nil {
+			return err
+		}
+		if err := requireArgs(flags, ids); err != nil {
+			return err
+		}
+
+		statusRequests := make([]request.MessageStatusRequest, 0, len(ids))
+		for _, id := range ids {
+			statusRequests = append(statusRequests, request.MessageStatusRequest{Id: id, Status: status})
+		}
+
+		c, err := opts.client()
+		if err != nil {
+			return err
+		}
+		results, err := c.UpdateStatus(ctx, statusRequests)
+		if err != nil {
+			return describeError(err)
+		}
+
+		rows := make([][]string, 0, len(results))
+		for _, result := range results {
+			rows = append(rows, []string{result.Id, resultName(result.Result)})
+		}
+		return opts.printer().results(results, rows)
+	}
+}
+
+// runDelete 删除发送的消息，或者从收件箱中删除收到的消息
+func runDelete(ctx context.Context, args []string) error {
+	flags, opts := newFlagSet("delete", "[flags] <id>...")
+	hard := flags.Bool("hard", false, "永久删除，否则放入回收站")
+	atomic := flags.Bool("atomic", false, "任意一条消息删除失败则全部回滚")
+	inbox := flags.Bool("inbox", false, "从自己的收件箱中删除收到的消息，不影响其他接收者")
+	ids, err := parse(flags, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := requireArgs(flags, ids); err != nil {
+		return err
+	}
+
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	var results []response.MessageDeleteResponse
+	if *inbox {
+		hideRequests := make([]request.MessageHideRequest, 0, len(ids))
+		for _, id := range ids {
+			hideRequests = append(hideRequests, request.MessageHideRequest{MessageId: id})
+		}
+		results, err = c.HideMessages(ctx, hideRequests)
+	} else {
+		deleteRequests := make([]request.MessageDeleteRequest, 0, len(ids))
+		for _, id := range ids {
+			deleteRequests = append(deleteRequests, request.MessageDeleteRequest{MessageId: id, Delete: *hard})
+		}
+		results, err = c.DeleteMessages(ctx, deleteRequests, *atomic)
+	}
+	if err != nil {
+		return describeError(err)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		name := result.Result
+		if name == "" {
+			name = resultName(result.Status)
+		}
+		rows = append(rows, []string{result.Id, name})
+	}
+	return opts.printer().results(results, rows)
+}
+
+// resultName 返回批量操作结果的名称
+func resultName(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "failed"
+}
+
+// runTail 定时查询并输出新收到的消息，直到收到中断信号
+//
+// 按照创建时间倒序查询，记录已经输出的最新创建时间和该时间的消息，只输出更新的消息。
+func runTail(ctx context.Context, args []string) error {
+	flags, opts := newFlagSet("tail", "[flags]")
+	filter := flags.String("filter", "", "过滤语句，只输出满足条件的消息")
+	interval := flags.Duration("interval", 5*time.Second, "查询新消息的间隔")
+	if _, err := parse(flags, opts, args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(flags.Output(), "--interval 必须大于 0")
+		return errUsage
+	}
+
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	var latest time.Time
+	latestIds := map[string]bool{}
+	first := true
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	for {
+		var messages []response.InboxMessage
+		it := c.AllMessages(&request.MessageRequest{Filter: *filter, SortColumn: "created_at", SortType: "desc"})
+		for it.Next(ctx) {
+			message := it.Value()
+			if message.CreatedAt.Before(latest) {
+				break
+			}
+			if latestIds[message.MessageId] {
+				continue
+			}
+			messages = append(messages, message)
+			// 第一次只需要知道最新的消息，不输出之前的消息
+			if first {
+				break
+			}
+		}
+		if err := it.Err(); err != nil {
+			return describeError(err)
+		}
+
+		// 按照创建时间从旧到新输出
+		for i := len(messages) - 1; i >= 0 && !first; i-- {
+			if opts.output == outputJSON {
+				err = encoder.Encode(messages[i])
+			} else {
+				_, err = fmt.Println(strings.Join(messageRow(&messages[i].Message), "  "))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(messages) > 0 {
+			if newest := messages[0].CreatedAt; newest.After(latest) {
+				latest = newest
+				latestIds = map[string]bool{}
+			}
+			for _, message := range messages {
+				if message.CreatedAt.Equal(latest) {
+					latestIds[message.MessageId] = true
+				}
+			}
+		}
+		first = false
+
+		timer := time.NewTimer(*interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// runExport 导出收到的消息
+func runExport(ctx context.Context, args []string) error {
+	flags, opts := newFlagSet("export", "[flags]")
+	exportRequest := &request.MessageExportRequest{}
+	flags.StringVar(&exportRequest.Format, "format", "", "导出格式（jsonl/csv），为空时为 jsonl")
+	flags.StringVar(&exportRequest.Filter, "filter", "", "过滤语句")
+	out := flags.String("out", "", "导出的文件，为空时输出到标准输出")
+	admin := flags.Bool("admin", false, "使用管理员凭证导出所有消息")
+	if _, err := parse(flags, opts, args); err != nil {
+		return err
+	}
+
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *admin {
+		err = c.AdminExport(ctx, exportRequest, w)
+	} else {
+		err = c.Export(ctx, exportRequest, w)
+	}
+	return describeError(err)
+}
+
+// runProfile 管理配置文件中的地址和凭证
+func runProfile(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "用法: message-cli profile <list|set|use|delete> [flags] [name]")
+		return errUsage
+	}
+
+	flags, opts := newFlagSet("profile "+args[0], "[flags] <name>")
+	url := flags.String("url", "", "消息服务的地址")
+	token := flags.String("token", "", "消息凭证或管理员凭证")
+	use := flags.Bool("use", false, "设置为当前配置")
+	names, err := parse(flags, opts, args[1:])
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(opts.config)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		return printProfiles(opts.printer(), cfg)
+	case "set":
+		if err := requireArgs(flags, names); err != nil {
+			return err
+		}
+		name := names[0]
+		profile := cfg.Profiles[name]
+		if *url != "" {
+			profile.URL = strings.TrimRight(*url, "/")
+		}
+		if *token != "" {
+			profile.Token = *token
+		}
+		cfg.Profiles[name] = profile
+		if *use || cfg.Current == "" {
+			cfg.Current = name
+		}
+	case "use":
+		if err := requireArgs(flags, names); err != nil {
+			return err
+		}
+		if _, ok := cfg.Profiles[names[0]]; !ok {
+			return fmt.Errorf("配置 %s 不存在", names[0])
+		}
+		cfg.Current = names[0]
+	case "delete":
+		if err := requireArgs(flags, names); err != nil {
+			return err
+		}
+		delete(cfg.Profiles, names[0])
+		if cfg.Current == names[0] {
+			cfg.Current = ""
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "message-cli profile: 未知的命令 %s\n", args[0])
+		return errUsage
+	}
+	return cfg.save()
+}
+
+// printProfiles 打印所有配置，不输出完整的凭证
+func printProfiles(p *printer, cfg *Config) error {
+	type profileOutput struct {
+		Name    string `json:"name"`
+		URL     string `json:"url"`
+		Token   string `json:"token"`
+		Current bool   `json:"current"`
+	}
+
+	profiles := make([]profileOutput, 0, len(cfg.Profiles))
+	rows := make([][]string, 0, len(cfg.Profiles))
+	for _, name := range cfg.names() {
+		profile := cfg.Profiles[name]
+		output := profileOutput{
+			Name:    name,
+			URL:     profile.URL,
+			Token:   maskToken(profile.Token),
+			Current: name == cfg.Current,
+		}
+		profiles = append(profiles, output)
+
+		current := ""
+		if output.Current {
+			current = "*"
+		}
+		rows = append(rows, []string{current, output.Name, output.URL, output.Token})
+	}
+
+	if p.output == outputJSON {
+		return p.printJSON(profiles)
+	}
+	return p.table([]string{"", "NAME", "URL", "TOKEN"}, rows)
+}
+
+// maskToken 只保留凭证的前 4 位
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + strings.Repeat("*", len(token)-4)
+}
+
+// describeError 在参数校验失败时附加校验失败的字段
+func describeError(err error) error {
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || len(apiErr.ValidationErrors) == 0 {
+		return err
+	}
+	fields := make([]string, 0, len(apiErr.ValidationErrors))
+	for _, validationError := range apiErr.ValidationErrors {
+		fields = append(fields, fmt.Sprintf("%s(%s)", validationError.Field, validationError.Message))
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(fields, ", "))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// defaultURL 没有配置地址时使用的消息服务地址
+const defaultURL = "http://127.0.0.1:1204"
+
+// Profile 一个消息服务的地址和凭证
+type Profile struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+}
+
+// Config 命令行客户端的配置文件
+type Config struct {
+	// Current 没有指定 --profile 时使用的配置
+	Current  string             `yaml:"current"`
+	Profiles map[string]Profile `yaml:"profiles"`
+
+	path string
+}
+
+// configPath 返回配置文件的路径，优先使用 --config 和 MESSAGE_CLI_CONFIG 环境变量
+func configPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path = os.Getenv("MESSAGE_CLI_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "message-cli", "config.yaml"), nil
+}
+
+// loadConfig 读取配置文件，文件不存在时返回空的配置
+func loadConfig(path string) (*Config, error) {
+	path, err := configPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		cfg.Profiles = map[string]Profile{}
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	return cfg, nil
+}
+
+// save 保存配置文件，文件中包含凭证，所以只允许当前用户读写
+func (c *Config) save() error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o600)
+}
+
+// names 返回按名称排序的所有配置
+func (c *Config) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profile 返回 name 对应的配置，name 为空时使用当前配置
+//
+// 环境变量 MESSAGE_URL 和 MESSAGE_TOKEN 优先于配置文件。
+func (c *Config) profile(name string) (Profile, error) {
+	if name == "" {
+		name = c.Current
+	}
+
+	var profile Profile
+	if name != "" {
+		var ok bool
+		if profile, ok = c.Profiles[name]; !ok {
+			return profile, fmt.Errorf("配置 %s 不存在", name)
+		}
+	}
+	if url := os.Getenv("MESSAGE_URL"); url != "" {
+		profile.URL = url
+	}
+	if token := os.Getenv("MESSAGE_TOKEN"); token != "" {
+		profile.Token = token
+	}
+	if profile.URL == "" {
+		profile.URL = defaultURL
+	}
+	if profile.Token == "" {
+		return profile, errors.New("没有配置凭证，使用 message-cli profile set 添加配置或者设置 MESSAGE_TOKEN")
+	}
+	return profile, nil
+}
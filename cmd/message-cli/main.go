@@ -0,0 +1,159 @@
+// message-cli 消息服务的命令行客户端，通过 /v1 接口发送、查询和管理消息
+//
+// 使用方法：
+//
+//	message-cli profile set prod --url https://message.example.com --token <凭证> --use
+//	message-cli send --title 标题 --content 内容 --category notice --big-content 详细内容 --to <接收者>
+//	message-cli list --filter "status = 0"
+//	message-cli tail
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"message/app/model"
+	"message/client"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// command 一个子命令
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands 所有的子命令，按照帮助信息中的顺序排列
+var commands []command
+
+func init() {
+	commands = []command{
+		{"send", "[flags]", "发送消息", runSend},
+		{"list", "[flags]", "查询收到的消息", runList},
+		{"show", "[flags] <id>", "查看一条消息", runShow},
+		{"read", "<id>...", "将消息标记为已读", statusCommand("read", model.Read)},
+		{"archive", "<id>...", "将消息归档", statusCommand("archive", model.Archived)},
+		{"delete", "[flags] <id>...", "删除发送的消息，或者从收件箱中删除收到的消息", runDelete},
+		{"tail", "[flags]", "持续输出新收到的消息", runTail},
+		{"export", "[flags]", "导出收到的消息", runExport},
+		{"profile", "<list|set|use|delete>", "管理配置文件中的地址和凭证", runProfile},
+	}
+}
+
+// errUsage 参数错误，已经打印了帮助信息
+var errUsage = errors.New("usage")
+
+// options 所有子命令共用的参数
+type options struct {
+	config  string
+	profile string
+	output  string
+}
+
+// newFlagSet 创建子命令的参数解析器，并添加共用的参数
+func newFlagSet(name string, usage string) (*flag.FlagSet, *options) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: message-cli %s %s\n\n", name, usage)
+		flags.PrintDefaults()
+	}
+	opts := &options{}
+	flags.StringVar(&opts.config, "config", "", "配置文件的路径，默认为 $MESSAGE_CLI_CONFIG 或用户配置目录下的 message-cli/config.yaml")
+	flags.StringVar(&opts.profile, "profile", "", "使用的配置，默认为当前配置")
+	flags.StringVar(&opts.output, "output", outputTable, "输出格式（table/json）")
+	return flags, opts
+}
+
+// parse 解析参数并检查输出格式，返回位置参数
+//
+// 位置参数和参数可以交替出现，例如 show <id> --output json。
+func parse(flags *flag.FlagSet, opts *options, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if opts.output != outputTable && opts.output != outputJSON {
+		fmt.Fprintf(flags.Output(), "不支持的输出格式 %s\n", opts.output)
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// client 根据配置创建消息服务的客户端
+func (o *options) client() (*client.Client, error) {
+	cfg, err := loadConfig(o.config)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := cfg.profile(o.profile)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(profile.URL, profile.Token), nil
+}
+
+// printer 根据输出格式创建打印结果的 printer
+func (o *options) printer() *printer {
+	return &printer{w: os.Stdout, output: o.output}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: message-cli <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "使用 message-cli <command> -h 查看命令的参数")
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+
+	// 收到中断信号时取消正在进行的请求
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		err := cmd.run(ctx, os.Args[2:])
+		switch {
+		case err == nil:
+		case errors.Is(err, flag.ErrHelp):
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		case errors.Is(err, context.Canceled):
+			os.Exit(130)
+		default:
+			fmt.Fprintf(os.Stderr, "message-cli %s: %s\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "message-cli: 未知的命令 %s\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
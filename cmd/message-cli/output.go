@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"message/app/model"
+	"message/app/response"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// 定义输出格式的常量
+const (
+	outputTable = "table" // 表格，适合在终端中查看
+	outputJSON  = "json"  // JSON，适合给其他程序处理
+)
+
+// printer 按照输出格式打印命令的结果
+type printer struct {
+	w      io.Writer
+	output string
+}
+
+// printJSON 将 value 格式化为 JSON 打印
+func (p *printer) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
+
+// table 打印表格，rows 的每一行和 header 的列数相同
+func (p *printer) table(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// messages 打印消息列表
+func (p *printer) messages(messages []response.Message, value interface{}) error {
+	if p.output == outputJSON {
+		return p.printJSON(value)
+	}
+	rows := make([][]string, 0, len(messages))
+	for _, message := range messages {
+		rows = append(rows, messageRow(&message))
+	}
+	return p.table([]string{"ID", "TITLE", "CATEGORY", "STATUS", "PRIORITY", "SENDER", "CREATED"}, rows)
+}
+
+// message 打印一条消息的详细信息
+func (p *printer) message(message *response.Message) error {
+	if p.output == outputJSON {
+		return p.printJSON(message)
+	}
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\t%s\n", message.MessageId)
+	fmt.Fprintf(w, "TITLE\t%s\n", message.Title)
+	fmt.Fprintf(w, "CONTENT\t%s\n", message.Content)
+	fmt.Fprintf(w, "CATEGORY\t%s\n", message.Category)
+	fmt.Fprintf(w, "STATUS\t%s\n", statusName(message.Status))
+	fmt.Fprintf(w, "PRIORITY\t%s\n", message.Priority)
+	fmt.Fprintf(w, "SENDER\t%s\n", strings.Join(message.SenderIds, ","))
+	fmt.Fprintf(w, "TO\t%s\n", strings.Join(message.IntroducerIds, ","))
+	fmt.Fprintf(w, "VERSION\t%d\n", message.Version)
+	fmt.Fprintf(w, "ETAG\t%s\n", response.MessageETag(message))
+	fmt.Fprintf(w, "CREATED\t%s\n", formatTime(message.CreatedAt))
+	fmt.Fprintf(w, "UPDATED\t%s\n", formatTime(message.UpdatedAt))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(p.w, "\n%s\n", message.BigContent)
+	return err
+}
+
+// results 打印批量操作每条消息的结果
+func (p *printer) results(value interface{}, rows [][]string) error {
+	if p.output == outputJSON {
+		return p.printJSON(value)
+	}
+	return p.table([]string{"ID", "RESULT"}, rows)
+}
+
+// messageRow 返回消息在表格中的一行
+func messageRow(message *response.Message) []string {
+	return []string{
+		message.MessageId,
+		message.Title,
+		message.Category,
+		statusName(message.Status),
+		fmt.Sprint(message.Priority),
+		strings.Join(message.SenderIds, ","),
+		formatTime(message.CreatedAt),
+	}
+}
+
+// statusName 返回消息状态的名称
+func statusName(status uint8) string {
+	switch status {
+	case model.Unread:
+		return "unread"
+	case model.Read:
+		return "read"
+	case model.Archived:
+		return "archived"
+	}
+	return fmt.Sprint(status)
+}
+
+// formatTime 将时间格式化为本地时间
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -1,11 +1,20 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"github.com/spf13/viper"
+	"net"
 	"os"
+	"regexp"
 )
 
+// AdminOperator 单独配置凭证的管理员，审计日志使用名称记录操作的管理员
+type AdminOperator struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+}
+
 type ServiceConfig struct {
 	App struct {
 		Title    string `yaml:"title"`
@@ -25,28 +34,67 @@ type ServiceConfig struct {
 			Table  string `yaml:"table"`
 			Column string `yaml:"column"`
 		} `yaml:"verify"`
+		Admin struct {
+			Token     string          `yaml:"token"`
+			Operators []AdminOperator `yaml:"operators"`
+		} `yaml:"admin"`
+		Trash struct {
+			PurgeDays int `yaml:"purgeDays"`
+		} `yaml:"trash"`
+		Priority struct {
+			Default    uint8            `yaml:"default"`
+			Categories map[string]uint8 `yaml:"categories"`
+		} `yaml:"priority"`
+		Webhook struct {
+			Timeout int `yaml:"timeout"`
+		} `yaml:"webhook"`
 	} `yaml:"app"`
 	Database struct {
-		Host       string `yaml:"host"`
-		Port       int    `yaml:"port"`
-		User       string `yaml:"user"`
-		Pwd        string `yaml:"pwd"`
-		Name       string `yaml:"name"`
-		MaxIdleCon int    `yaml:"max_idle_con"`
-		MaxOpenCon int    `yaml:"max_open_con"`
-		Params     struct {
+		Host        string `yaml:"host"`
+		Port        int    `yaml:"port"`
+		User        string `yaml:"user"`
+		Pwd         string `yaml:"pwd"`
+		Name        string `yaml:"name"`
+		MaxIdleCon  int    `yaml:"max_idle_con"`
+		MaxOpenCon  int    `yaml:"max_open_con"`
+		AutoMigrate bool   `yaml:"autoMigrate"`
+		Params      struct {
 			Character string `yaml:"character"`
 		} `yaml:"params"`
 	} `yaml:"database"`
 	API struct {
-		Test     bool `yaml:"test"`
-		MaxLimit int  `yaml:"maxLimit"`
+		Test           bool `yaml:"test"`
+		MaxLimit       int  `yaml:"maxLimit"`
+		RequireIfMatch bool `yaml:"requireIfMatch"`
+		IdempotencyTTL int  `yaml:"idempotencyTTL"`
 	} `yaml:"api"`
+	GRPC struct {
+		Address string `yaml:"address"`
+	} `yaml:"grpc"`
+	Server struct {
+		Address         string `yaml:"address"`
+		ReadTimeout     int    `yaml:"readTimeout"`
+		WriteTimeout    int    `yaml:"writeTimeout"`
+		IdleTimeout     int    `yaml:"idleTimeout"`
+		MaxHeaderBytes  int    `yaml:"maxHeaderBytes"`
+		ShutdownTimeout int    `yaml:"shutdownTimeout"`
+		TLS             struct {
+			Cert string `yaml:"cert"`
+			Key  string `yaml:"key"`
+		} `yaml:"tls"`
+	} `yaml:"server"`
 }
 
 var AppConfig ServiceConfig
 
 func InitConfig() {
+	if err := LoadConfig(); err != nil {
+		panic(err)
+	}
+}
+
+// LoadConfig 读取 config 目录下的配置文件，和 InitConfig 相同，但是返回错误而不是 panic
+func LoadConfig() error {
 	workDir, _ := os.Getwd()
 	// 设置配置文件的名字
 	viper.SetConfigName("config")
@@ -54,14 +102,75 @@ func InitConfig() {
 	viper.SetConfigType("yaml")
 	// 添加配置文件的路径，指定 config 目录下寻找
 	viper.AddConfigPath(workDir + "/config")
+	// 没有配置的项使用的默认值
+	viper.SetDefault("database.autoMigrate", true)
+	viper.SetDefault("server.address", ":1204")
+	viper.SetDefault("server.shutdownTimeout", 30)
 	// 寻找配置文件并读取
 	err := viper.ReadInConfig()
 	if err != nil {
-		panic(fmt.Errorf("fatal error config file: %w", err))
+		return fmt.Errorf("fatal error config file: %w", err)
 	}
 
 	err = viper.Unmarshal(&AppConfig)
 	if err != nil {
-		panic(fmt.Errorf("fatal error parset file: %w", err))
+		return fmt.Errorf("fatal error parset file: %w", err)
+	}
+	return nil
+}
+
+// identifierPattern 表名和列名只能包含字母、数字和下划线
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// operatorPattern 管理员的名称记录在审计日志的操作者中，只能包含字母、数字、下划线和连字符
+var operatorPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,26}$`)
+
+// Validate 检查配置的值是否有效，返回所有无效的配置项
+func (c *ServiceConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.App.Log.Info != "" && c.App.Log.Error != "" && c.App.Log.Access != "", "app.log: 日志路径不能为空")
+	check(identifierPattern.MatchString(c.App.Verify.Table), "app.verify.table: 无效的表名 %q", c.App.Verify.Table)
+	check(identifierPattern.MatchString(c.App.Verify.Column), "app.verify.column: 无效的列名 %q", c.App.Verify.Column)
+	names := make(map[string]bool)
+	for i, operator := range c.App.Admin.Operators {
+		check(operatorPattern.MatchString(operator.Name), "app.admin.operators[%d].name: 无效的名称 %q", i, operator.Name)
+		check(!names[operator.Name], "app.admin.operators[%d].name: 名称 %q 重复", i, operator.Name)
+		check(operator.Token != "", "app.admin.operators[%d].token: 不能为空", i)
+		names[operator.Name] = true
 	}
+	check(c.App.Trash.PurgeDays >= 0, "app.trash.purgeDays: 不能小于 0")
+	check(c.App.Priority.Default >= 1 && c.App.Priority.Default <= 4, "app.priority.default: 优先级必须在 1 到 4 之间")
+	for category, priority := range c.App.Priority.Categories {
+		check(priority >= 1 && priority <= 4, "app.priority.categories.%s: 优先级必须在 1 到 4 之间", category)
+	}
+	check(c.App.Webhook.Timeout >= 0, "app.webhook.timeout: 不能小于 0")
+
+	check(c.Database.Host != "", "database.host: 不能为空")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port: 无效的端口 %d", c.Database.Port)
+	check(c.Database.User != "", "database.user: 不能为空")
+	check(c.Database.Name != "", "database.name: 不能为空")
+
+	check(c.API.MaxLimit > 0, "api.maxLimit: 必须大于 0")
+	check(c.API.IdempotencyTTL >= 0, "api.idempotencyTTL: 不能小于 0")
+
+	if c.GRPC.Address != "" {
+		_, _, err := net.SplitHostPort(c.GRPC.Address)
+		check(err == nil, "grpc.address: 无效的地址 %q", c.GRPC.Address)
+	}
+
+	_, _, err := net.SplitHostPort(c.Server.Address)
+	check(err == nil, "server.address: 无效的地址 %q", c.Server.Address)
+	check(c.Server.ReadTimeout >= 0, "server.readTimeout: 不能小于 0")
+	check(c.Server.WriteTimeout >= 0, "server.writeTimeout: 不能小于 0")
+	check(c.Server.IdleTimeout >= 0, "server.idleTimeout: 不能小于 0")
+	check(c.Server.MaxHeaderBytes >= 0, "server.maxHeaderBytes: 不能小于 0")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout: 必须大于 0")
+	check((c.Server.TLS.Cert == "") == (c.Server.TLS.Key == ""), "server.tls: cert 和 key 必须同时设置")
+	return errors.Join(errs...)
 }
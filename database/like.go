@@ -0,0 +1,24 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+// likeEscaper 转义 LIKE 模式中的通配符和转义字符本身
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike 转义 s 中的 %、_ 和 \，使其在 LIKE 模式中按字面匹配，需要和 LikeEscape 一起使用
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// LikeEscape 返回 LIKE 条件中声明反斜杠为转义字符的 ESCAPE 子句
+//
+// MySQL 的字符串字面量中反斜杠本身需要转义，其他数据库按标准 SQL 处理。
+func LikeEscape(db *gorm.DB) string {
+	if db.Dialector.Name() == "mysql" {
+		return `ESCAPE '\\'`
+	}
+	return `ESCAPE '\'`
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hash/fnv"
+	"message/logs"
+	"time"
+)
+
+// 定义迁移锁的常量
+const (
+	migrationLockName    = "message_schema_migrations" // 迁移锁的名称
+	migrationLockTimeout = 5 * time.Minute             // 等待迁移锁的最长时间
+	migrationLockRetry   = time.Second                 // 没有获取到迁移锁时重试的间隔
+	// migrationLockStale 使用锁表时，超过该时间的锁视为持有锁的实例已经退出
+	migrationLockStale = 30 * time.Minute
+)
+
+// ErrMigrationLocked 等待迁移锁超时
+var ErrMigrationLocked = errors.New("等待迁移锁超时，其他实例正在执行迁移")
+
+// migrationLocker 迁移锁，不同的数据库使用不同的实现
+type migrationLocker interface {
+	// tryLock 尝试获取锁，锁被其他实例持有时返回 false
+	tryLock(ctx context.Context, conn *sql.Conn) (bool, error)
+	// unlock 释放锁
+	unlock(ctx context.Context, conn *sql.Conn) error
+}
+
+// migrationLockers 每种数据库对应的迁移锁，键为 gorm 的数据库方言名称，没有对应的实现时使用锁表
+var migrationLockers = map[string]migrationLocker{
+	"mysql":    mysqlLocker{},
+	"postgres": postgresLocker{},
+}
+
+// withMigrationLock 获取迁移锁后执行 fn，执行完成后释放锁
+func withMigrationLock(fn func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), migrationLockTimeout)
+	defer cancel()
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	// 会话级别的锁需要在同一个连接上获取和释放
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	locker, ok := migrationLockers[DB.Dialector.Name()]
+	if !ok {
+		locker = tableLocker{}
+	}
+	for {
+		locked, err := locker.tryLock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if locked {
+			break
+		}
+		logs.LogInfo.Info("Migrate-等待迁移锁")
+		select {
+		case <-ctx.Done():
+			return ErrMigrationLocked
+		case <-time.After(migrationLockRetry):
+		}
+	}
+	defer func() {
+		// 迁移可能用完了等待的时间，释放锁时使用新的上下文
+		if err := locker.unlock(context.Background(), conn); err != nil {
+			logs.LogError.Errorf("Migrate-释放迁移锁失败 %s", err)
+		}
+	}()
+
+	return fn()
+}
+
+// mysqlLocker 使用 MySQL 的 GET_LOCK，连接断开时自动释放
+type mysqlLocker struct{}
+
+func (mysqlLocker) tryLock(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", migrationLockName).Scan(&locked)
+	return locked.Valid && locked.Int64 == 1, err
+}
+
+func (mysqlLocker) unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+	return err
+}
+
+// postgresLocker 使用 PostgreSQL 的 advisory lock，连接断开时自动释放
+type postgresLocker struct{}
+
+// postgresLockKey 根据锁的名称生成 advisory lock 的键
+func postgresLockKey() int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(migrationLockName))
+	return int64(hash.Sum64())
+}
+
+func (postgresLocker) tryLock(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var locked bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", postgresLockKey()).Scan(&locked)
+	return locked, err
+}
+
+func (postgresLocker) unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLockKey())
+	return err
+}
+
+// tableLocker 没有会话锁的数据库（例如 SQLite）在锁表中插入一行作为锁
+//
+// 持有锁的实例异常退出时锁不会释放，超过 migrationLockStale 的锁会被删除。
+type tableLocker struct{}
+
+func (tableLocker) tryLock(ctx context.Context, conn *sql.Conn) (bool, error) {
+	_, err := conn.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations_lock (id INTEGER PRIMARY KEY, locked_at TIMESTAMP NOT NULL)",
+	)
+	if err != nil {
+		return false, err
+	}
+	_, err = conn.ExecContext(ctx,
+		"DELETE FROM schema_migrations_lock WHERE id = 1 AND locked_at < ?",
+		time.Now().Add(-migrationLockStale),
+	)
+	if err != nil {
+		return false, err
+	}
+	result, err := conn.ExecContext(ctx,
+		"INSERT INTO schema_migrations_lock (id, locked_at) SELECT 1, ? WHERE NOT EXISTS (SELECT 1 FROM schema_migrations_lock WHERE id = 1)",
+		time.Now(),
+	)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	return inserted == 1, err
+}
+
+func (tableLocker) unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE id = 1")
+	return err
+}
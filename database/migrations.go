@@ -2,26 +2,210 @@ package database
 
 import (
 	"fmt"
-	"message/app/model"
+	"gorm.io/gorm"
 	"message/config"
-	"message/logs"
+	"time"
 )
 
-// InitMigration 用于初始化数据库迁移
-func InitMigration() {
-	// 从配置中获取字符集设置
-	charset := config.AppConfig.Database.Params.Character
-
-	// 构建数据库配置字符串
-	dbConfig := fmt.Sprintf("charset=%s", charset)
-
-	// 设置表选项为指定的数据库配置，并自动迁移指定的数据模型
-	err := DB.Set("gorm:table_options", dbConfig).AutoMigrate(
-		// 迁移消息模型
-		&model.Message{},
-	)
-	if err != nil {
-		// 输出迁移错误信息
-		logs.LogError.Errorf("InitMigration %s", err)
+// migrations 按照版本排列的数据库迁移，已经发布的迁移不能修改，修改表结构时添加新的版本
+//
+// Up 和 Down 可以使用 Go 代码，也可以使用 dialectSQL 为每种数据库编写 SQL。MySQL 的 DDL 不能在事务中回滚，
+// 所以每个步骤都需要可以重复执行，例如创建前检查是否已经存在、删除时使用 IF EXISTS。
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tables",
+		Up: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() == "mysql" {
+				// 从配置中获取字符集设置，表选项只有 MySQL 支持
+				charset := config.AppConfig.Database.Params.Character
+				tx = tx.Set("gorm:table_options", fmt.Sprintf("charset=%s", charset))
+			}
+			return tx.AutoMigrate(v1Models()...)
+		},
+		// 使用迁移之前启动服务时通过 AutoMigrate 创建了所有的表，不再修改这些表
+		Baseline: func(db *gorm.DB) bool {
+			for _, model := range v1Models() {
+				if !db.Migrator().HasTable(model) {
+					return false
+				}
+			}
+			return true
+		},
+		Down: func(tx *gorm.DB) error {
+			models := v1Models()
+			for i := len(models) - 1; i >= 0; i-- {
+				if err := tx.Migrator().DropTable(models[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_message_fulltext_index",
+		// 全文索引已经存在时不重复创建
+		Up: func(tx *gorm.DB) error {
+			return MessageSearcher().Migrate(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			// MySQL 不支持 DROP INDEX IF EXISTS，索引不存在时跳过
+			if tx.Dialector.Name() == "mysql" && !tx.Migrator().HasIndex("message", "idx_message_fulltext") {
+				return nil
+			}
+			return dialectSQL(map[string][]string{
+				"mysql": {
+					"DROP INDEX idx_message_fulltext ON message",
+				},
+				"sqlite": {
+					"DROP TRIGGER IF EXISTS message_fts_ai",
+					"DROP TRIGGER IF EXISTS message_fts_ad",
+					"DROP TRIGGER IF EXISTS message_fts_au",
+					"DROP TABLE IF EXISTS message_fts",
+				},
+				"postgres": {
+					"DROP INDEX IF EXISTS idx_message_search_vector",
+					"ALTER TABLE message DROP COLUMN IF EXISTS search_vector",
+				},
+			})(tx)
+		},
+	},
+}
+
+// v1Models 第一个版本创建的数据表
+//
+// 使用固定的结构体而不是 app/model 中的数据模型，修改数据模型后已经发布的迁移不会改变。
+func v1Models() []interface{} {
+	return []interface{}{
+		&v1Message{},
+		&v1MessageRecipient{},
+		&v1Audit{},
+		&v1MessageVersion{},
+		&v1Tag{},
+		&v1MessageTag{},
+		&v1MessageActionChoice{},
+		&v1Webhook{},
+		&v1MessageIdempotency{},
 	}
 }
+
+// v1Message 第一个版本的消息表
+type v1Message struct {
+	gorm.Model
+	MessageId     string `gorm:"type:varchar(32);index;unique;not null;comment:消息id"`
+	SenderIds     string `gorm:"type:text;comment:发送者的ID集合"`
+	Title         string `gorm:"type:varchar(25);not null;comment:消息标题"`
+	Content       string `gorm:"type:varchar(50);not null;comment:消息内容"`
+	Category      string `gorm:"type:varchar(50);index;not null;comment:消息类别"`
+	BigContent    string `gorm:"type:longtext;not null;comment:消息的详细内容"`
+	ContentType   string `gorm:"type:varchar(16);default:text;not null;comment:详细内容的格式"`
+	IntroducerIds string `gorm:"type:text;comment:接收者的ID集合"`
+	Data          string `gorm:"type:text;comment:消息附带的结构化数据"`
+	Actions       string `gorm:"type:text;comment:消息的操作按钮"`
+	Status        uint8  `gorm:"type:tinyint;default:0;comment:消息阅读状态"`
+	Priority      uint8  `gorm:"type:tinyint;default:2;index;comment:消息优先级"`
+	Version       uint   `gorm:"default:1;not null;comment:消息版本"`
+	Edited        bool   `gorm:"default:false;comment:消息是否被编辑过"`
+}
+
+func (v1Message) TableName() string { return "message" }
+
+// v1MessageRecipient 第一个版本的消息接收者表
+type v1MessageRecipient struct {
+	gorm.Model
+	MessageId   string `gorm:"type:varchar(32);uniqueIndex:idx_message_recipient;not null;comment:消息id"`
+	RecipientId string `gorm:"type:varchar(32);uniqueIndex:idx_message_recipient;not null;comment:接收者的ID"`
+	Status      uint8  `gorm:"type:tinyint;default:0;comment:消息阅读状态"`
+	Hidden      bool   `gorm:"default:false;comment:接收者是否删除了消息"`
+	Pinned      bool   `gorm:"default:false;comment:接收者是否置顶了消息"`
+	Starred     bool   `gorm:"default:false;comment:接收者是否标星了消息"`
+}
+
+func (v1MessageRecipient) TableName() string { return "message_recipient" }
+
+// v1Audit 第一个版本的审计日志表
+type v1Audit struct {
+	gorm.Model
+	Actor     string `gorm:"type:varchar(32);index;not null;comment:操作者的凭证"`
+	Ip        string `gorm:"type:varchar(64);comment:操作者的IP"`
+	Action    string `gorm:"type:varchar(32);index;not null;comment:操作类型"`
+	MessageId string `gorm:"type:varchar(32);index;comment:消息id"`
+	Changes   string `gorm:"type:text;comment:修改前后的数据"`
+}
+
+func (v1Audit) TableName() string { return "audit" }
+
+// v1MessageVersion 第一个版本的消息历史版本表
+type v1MessageVersion struct {
+	gorm.Model
+	MessageId     string    `gorm:"type:varchar(32);uniqueIndex:idx_message_version;not null;comment:消息id"`
+	Version       uint      `gorm:"uniqueIndex:idx_message_version;not null;comment:消息版本"`
+	Title         string    `gorm:"type:varchar(25);not null;comment:消息标题"`
+	Content       string    `gorm:"type:varchar(50);not null;comment:消息内容"`
+	Category      string    `gorm:"type:varchar(50);not null;comment:消息类别"`
+	BigContent    string    `gorm:"type:longtext;not null;comment:消息的详细内容"`
+	ContentType   string    `gorm:"type:varchar(16);default:text;not null;comment:详细内容的格式"`
+	IntroducerIds string    `gorm:"type:text;comment:接收者的ID集合"`
+	Data          string    `gorm:"type:text;comment:消息附带的结构化数据"`
+	Actions       string    `gorm:"type:text;comment:消息的操作按钮"`
+	Priority      uint8     `gorm:"type:tinyint;default:2;comment:消息优先级"`
+	EditedAt      time.Time `gorm:"comment:该版本的修改时间"`
+}
+
+func (v1MessageVersion) TableName() string { return "message_version" }
+
+// v1Tag 第一个版本的标签表
+type v1Tag struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"type:varchar(32);uniqueIndex:idx_tag_owner;not null;comment:标签名称"`
+	OwnerId   string    `gorm:"type:varchar(32);uniqueIndex:idx_tag_owner;not null;default:'';comment:标签所有者的ID，为空时是发送者设置的标签"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+}
+
+func (v1Tag) TableName() string { return "tag" }
+
+// v1MessageTag 第一个版本的消息标签关系表
+type v1MessageTag struct {
+	ID        uint      `gorm:"primarykey"`
+	MessageId string    `gorm:"type:varchar(32);uniqueIndex:idx_message_tag;not null;comment:消息id"`
+	TagId     uint      `gorm:"uniqueIndex:idx_message_tag;index;not null;comment:标签id"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+}
+
+func (v1MessageTag) TableName() string { return "message_tag" }
+
+// v1MessageActionChoice 第一个版本的操作按钮点击记录表
+type v1MessageActionChoice struct {
+	gorm.Model
+	MessageId   string `gorm:"type:varchar(32);uniqueIndex:idx_message_action_choice;not null;comment:消息id"`
+	RecipientId string `gorm:"type:varchar(32);uniqueIndex:idx_message_action_choice;not null;comment:接收者的ID"`
+	ActionId    string `gorm:"type:varchar(32);not null;comment:操作按钮的ID"`
+}
+
+func (v1MessageActionChoice) TableName() string { return "message_action_choice" }
+
+// v1Webhook 第一个版本的发送者 Webhook 表
+type v1Webhook struct {
+	ID        uint      `gorm:"primarykey"`
+	SenderId  string    `gorm:"type:varchar(32);uniqueIndex;not null;comment:发送者的ID"`
+	Url       string    `gorm:"type:varchar(2048);not null;comment:Webhook 地址"`
+	Secret    string    `gorm:"type:varchar(255);not null;default:'';comment:签名密钥，为空时不签名"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+	UpdatedAt time.Time `gorm:"comment:更新时间"`
+}
+
+func (v1Webhook) TableName() string { return "webhook" }
+
+// v1MessageIdempotency 第一个版本的幂等键表
+type v1MessageIdempotency struct {
+	ID             uint      `gorm:"primarykey"`
+	SenderId       string    `gorm:"type:varchar(32);uniqueIndex:idx_message_idempotency;not null;comment:发送者的ID"`
+	IdempotencyKey string    `gorm:"type:varchar(255);uniqueIndex:idx_message_idempotency;not null;comment:幂等键"`
+	RequestHash    string    `gorm:"type:varchar(64);not null;comment:请求内容的哈希"`
+	MessageId      string    `gorm:"type:varchar(32);not null;comment:创建的消息id"`
+	CreatedAt      time.Time `gorm:"comment:创建时间"`
+	ExpiresAt      time.Time `gorm:"index;comment:过期时间"`
+}
+
+func (v1MessageIdempotency) TableName() string { return "message_idempotency" }
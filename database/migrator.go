@@ -0,0 +1,273 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"message/config"
+	"message/logs"
+	"sort"
+	"time"
+)
+
+// ErrSchemaTooNew 数据库已经执行了比当前程序更新的迁移，需要使用更新的程序
+var ErrSchemaTooNew = errors.New("数据库的版本比程序新")
+
+// Migration 一个版本的数据库迁移
+type Migration struct {
+	// Version 版本号，必须大于之前的版本
+	Version uint
+	// Name 迁移的名称，只用于显示
+	Name string
+	// Up 执行迁移
+	Up func(tx *gorm.DB) error
+	// Down 回滚迁移，为 nil 时不能回滚
+	Down func(tx *gorm.DB) error
+	// Baseline 判断迁移创建的结构是否已经存在，例如使用迁移之前通过 AutoMigrate 创建的表
+	//
+	// 数据库中还没有任何迁移记录时，从第一个迁移开始依次调用，返回 true 的迁移只记录版本，不执行 Up。
+	Baseline func(db *gorm.DB) bool
+}
+
+// SchemaMigration 已经执行的迁移
+type SchemaMigration struct {
+	Version   uint      `gorm:"primarykey;autoIncrement:false;comment:迁移的版本"`
+	Name      string    `gorm:"type:varchar(255);not null;comment:迁移的名称"`
+	AppliedAt time.Time `gorm:"not null;comment:执行的时间"`
+}
+
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// MigrationStatus 一个版本的迁移状态
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	// AppliedAt 执行的时间，没有执行时为 nil
+	AppliedAt *time.Time
+	// Unknown 数据库中已经执行但是程序中不存在的迁移，通常是更新的程序执行的
+	Unknown bool
+}
+
+// dialectSQL 根据数据库方言执行对应的 SQL，没有对应方言的 SQL 时不执行
+func dialectSQL(statements map[string][]string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements[tx.Dialector.Name()] {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// LatestVersion 返回程序中最新的迁移版本
+func LatestVersion() uint {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// appliedMigrations 查询已经执行的迁移，按照版本排序，没有迁移记录表时返回空
+func appliedMigrations(db *gorm.DB) ([]SchemaMigration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		// HasTable 查询失败时也返回 false，需要区分没有迁移记录和数据库不可用
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return nil, sqlDB.Ping()
+	}
+	var applied []SchemaMigration
+	err := db.Order("version").Find(&applied).Error
+	return applied, err
+}
+
+// checkVersion 数据库的版本比程序新时返回 ErrSchemaTooNew
+func checkVersion(applied []SchemaMigration) error {
+	if len(applied) == 0 {
+		return nil
+	}
+	if version := applied[len(applied)-1].Version; version > LatestVersion() {
+		return fmt.Errorf("%w：数据库版本 %d，程序版本 %d", ErrSchemaTooNew, version, LatestVersion())
+	}
+	return nil
+}
+
+// CheckSchema 检查数据库的版本，返回没有执行的迁移数量，数据库的版本比程序新时返回 ErrSchemaTooNew
+func CheckSchema() (int, error) {
+	applied, err := appliedMigrations(DB)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkVersion(applied); err != nil {
+		return 0, err
+	}
+	return len(migrations) - len(applied), nil
+}
+
+// InitMigration 启动服务时检查数据库的版本，开启自动迁移时执行没有执行的迁移
+//
+// 数据库的版本比程序新时返回 ErrSchemaTooNew，迁移或检查版本失败时返回错误，服务不能启动。
+// 关闭自动迁移时有没有执行的迁移不是错误，只记录日志，/readyz 返回服务不可用。
+func InitMigration() error {
+	var err error
+	if config.AppConfig.Database.AutoMigrate {
+		err = Migrate()
+	} else {
+		var pending int
+		pending, err = CheckSchema()
+		if pending > 0 {
+			logs.LogError.Errorf("InitMigration-有 %d 个迁移没有执行，需要执行 migrate up", pending)
+		}
+	}
+	return err
+}
+
+// Migrate 执行所有没有执行的迁移
+func Migrate() error {
+	return MigrateTo(LatestVersion())
+}
+
+// MigrateTo 按照版本顺序执行不超过 version 的所有没有执行的迁移
+//
+// 执行前获取迁移锁，多个实例同时启动时只有一个实例执行迁移，其他实例等待后不再重复执行。
+func MigrateTo(version uint) error {
+	return withMigrationLock(func() error {
+		if err := DB.AutoMigrate(&SchemaMigration{}); err != nil {
+			return err
+		}
+		applied, err := appliedMigrations(DB)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(applied); err != nil {
+			return err
+		}
+
+		done := make(map[uint]bool, len(applied))
+		for _, migration := range applied {
+			done[migration.Version] = true
+		}
+		// 没有迁移记录时，已经存在的结构只记录版本
+		baseline := len(applied) == 0
+		for _, migration := range migrations {
+			if migration.Version > version || done[migration.Version] {
+				continue
+			}
+			record := func(tx *gorm.DB) error {
+				return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&SchemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			}
+
+			baseline = baseline && migration.Baseline != nil && migration.Baseline(DB)
+			if baseline {
+				logs.LogInfo.Infof("Migrate-已经存在，只记录版本 %d %s", migration.Version, migration.Name)
+				if err := record(DB); err != nil {
+					return fmt.Errorf("记录迁移 %d %s 失败：%w", migration.Version, migration.Name, err)
+				}
+				continue
+			}
+
+			logs.LogInfo.Infof("Migrate-执行 %d %s", migration.Version, migration.Name)
+			err := runMigration(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+				return record(tx)
+			})
+			if err != nil {
+				return fmt.Errorf("迁移 %d %s 失败：%w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// runMigration 执行一个迁移的步骤和版本记录
+//
+// MySQL 的 DDL 会隐式提交事务，失败时已经执行的 DDL 不会回滚，所以在 MySQL 上不使用事务，
+// 每个步骤都必须可以重复执行：失败后修复问题再次执行迁移，已经完成的步骤会被跳过。其他数据库在事务中执行。
+func runMigration(fn func(tx *gorm.DB) error) error {
+	if DB.Dialector.Name() == "mysql" {
+		return fn(DB)
+	}
+	return DB.Transaction(fn)
+}
+
+// Rollback 按照版本倒序回滚最近执行的 steps 个迁移
+func Rollback(steps int) error {
+	return withMigrationLock(func() error {
+		applied, err := appliedMigrations(DB)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(applied); err != nil {
+			return err
+		}
+
+		known := make(map[uint]Migration, len(migrations))
+		for _, migration := range migrations {
+			known[migration.Version] = migration
+		}
+		for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			migration := known[applied[i].Version]
+			if migration.Down == nil {
+				return fmt.Errorf("迁移 %d %s 不能回滚", applied[i].Version, applied[i].Name)
+			}
+			logs.LogInfo.Infof("Migrate-回滚 %d %s", migration.Version, migration.Name)
+			err := runMigration(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("回滚 %d %s 失败：%w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Migrations 返回程序中的迁移和数据库中已经执行的迁移的状态，按照版本排序
+func Migrations() ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(DB)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedAt := make(map[uint]time.Time, len(applied))
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if t, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &t
+			delete(appliedAt, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, migration := range applied {
+		if _, ok := appliedAt[migration.Version]; ok {
+			t := migration.AppliedAt
+			statuses = append(statuses, MigrationStatus{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: &t,
+				Unknown:   true,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"message/config"
+	"message/logs"
+	"path/filepath"
+	"testing"
+)
+
+// openTestDB 使用临时的 SQLite 数据库作为 DB
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	if logs.LogInfo == nil {
+		dir := t.TempDir()
+		config.AppConfig.App.Log.Info = filepath.Join(dir, "info.log")
+		config.AppConfig.App.Log.Error = filepath.Join(dir, "error.log")
+		config.AppConfig.App.Log.Access = filepath.Join(dir, "access.log")
+		logs.Console = "stderr"
+		logs.InitLog()
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "message.db")), &gorm.Config{
+		Logger:         logger.Discard,
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Use(db)
+	t.Cleanup(func() {
+		Use(nil)
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// appliedVersions 返回已经记录的迁移版本
+func appliedVersions(t *testing.T) []uint {
+	t.Helper()
+	applied, err := appliedMigrations(DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := make([]uint, 0, len(applied))
+	for _, migration := range applied {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestMigrateAndRollback(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate: %s", err)
+	}
+	if got := appliedVersions(t); len(got) != len(migrations) {
+		t.Fatalf("applied versions = %v, want all %d migrations", got, len(migrations))
+	}
+	if !db.Migrator().HasTable("message_fts") || !db.Migrator().HasTable("webhook") {
+		t.Fatal("Migrate did not create message_fts and webhook")
+	}
+
+	// 再次执行不会重复执行迁移
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate again: %s", err)
+	}
+
+	if err := Rollback(len(migrations)); err != nil {
+		t.Fatalf("Rollback: %s", err)
+	}
+	if got := appliedVersions(t); len(got) != 0 {
+		t.Fatalf("applied versions after rollback = %v, want none", got)
+	}
+	if db.Migrator().HasTable("message") || db.Migrator().HasTable("message_fts") {
+		t.Fatal("Rollback left message tables behind")
+	}
+
+	// 回滚后可以重新迁移
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate after rollback: %s", err)
+	}
+	if got := appliedVersions(t); len(got) != len(migrations) {
+		t.Fatalf("applied versions = %v, want all %d migrations", got, len(migrations))
+	}
+}
+
+func TestMigrateBaselinesExistingTables(t *testing.T) {
+	db := openTestDB(t)
+
+	// 使用迁移之前通过 AutoMigrate 创建的表，和第一个版本的结构不完全相同
+	if err := db.AutoMigrate(v1Models()...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrator().DropColumn(&v1Message{}, "edited"); err != nil {
+		t.Fatal(err)
+	}
+	err := db.Exec(
+		"INSERT INTO message (message_id, title, content, category, big_content) VALUES (?, ?, ?, ?, ?)",
+		"7e55cb38290f49ee2b0e9cfd2adf13e4", "已有的消息", "内容", "notice", "详细内容",
+	).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate: %s", err)
+	}
+	if got := appliedVersions(t); len(got) != len(migrations) {
+		t.Fatalf("applied versions = %v, want all %d migrations", got, len(migrations))
+	}
+	// 第一个版本只记录版本，没有修改已有的表，后面的版本正常执行
+	if db.Migrator().HasColumn(&v1Message{}, "edited") {
+		t.Fatal("Migrate re-ran the baselined migration")
+	}
+	if !db.Migrator().HasTable("message_fts") || !db.Migrator().HasTable("webhook") {
+		t.Fatal("Migrate did not run the migrations after the baseline")
+	}
+	var count int64
+	db.Table("message").Count(&count)
+	if count != 1 {
+		t.Fatalf("message count = %d, want the existing message kept", count)
+	}
+}
+
+func TestMigrateDoesNotBaselinePartialSchema(t *testing.T) {
+	db := openTestDB(t)
+
+	// 只有部分表时正常执行第一个版本
+	if err := db.AutoMigrate(&v1Message{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate: %s", err)
+	}
+	for _, model := range v1Models() {
+		if !db.Migrator().HasTable(model) {
+			t.Fatalf("Migrate did not create %T", model)
+		}
+	}
+}
+
+func TestInitMigrationReturnsErrors(t *testing.T) {
+	db := openTestDB(t)
+	autoMigrate := config.AppConfig.Database.AutoMigrate
+	t.Cleanup(func() { config.AppConfig.Database.AutoMigrate = autoMigrate })
+
+	// 数据库连接已经关闭时迁移和检查版本都失败，服务不能在迁移失败的数据库上启动
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sqlDB.Close()
+	for _, enabled := range []bool{true, false} {
+		config.AppConfig.Database.AutoMigrate = enabled
+		if err := InitMigration(); err == nil {
+			t.Fatalf("InitMigration with autoMigrate %t on a closed database = nil, want an error", enabled)
+		}
+	}
+}
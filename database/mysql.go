@@ -1,27 +1,102 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
+	"log"
 	"message/config"
 	"message/logs"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 // DB 全局变量 DB 用于存储数据库连接实例
 var DB *gorm.DB
 
+// ErrNotConnected 没有连接数据库，例如启动时多次重试后仍然连接失败
+var ErrNotConnected = errors.New("数据库未连接")
+
+// connected 数据库是否可以使用，后台重新连接时 DB 在其它 goroutine 中赋值，需要先检查 connected 再使用 DB
+var connected atomic.Bool
+
 // 进行连接重试
 var maxRetries = 5
 var curRetries = 1
 
+// reconnectInterval 启动时连接失败后，后台重新连接数据库的间隔
+var reconnectInterval = 30 * time.Second
+
 // InitMySQL 用于初始化 MySQL 数据库连接
 func InitMySQL() {
+	// 进行数据库连接
+	err := databaseConnect(dsn())
+	if err != nil {
+		logs.LogError.Errorf(
+			"InitMySQL-数据库连接失败！ %s 3秒后尝试连接。正在尝试%d次 剩余%d次。",
+			err,
+			curRetries,
+			maxRetries-curRetries,
+		)
+		// 重试 maxRetries 次后放弃，DB 保持为 nil，可以使用 Reconnect 在后台继续连接
+		if curRetries < maxRetries {
+			curRetries++
+			time.Sleep(time.Second * 3)
+			InitMySQL()
+		}
+		return
+	}
+	connected.Store(true)
+}
+
+// Reconnect 每隔 reconnectInterval 重新连接数据库，直到连接成功或者 ctx 被取消
+//
+// 连接成功后执行 onConnect，例如检查迁移和启动后台任务，onConnect 返回 nil 之后 Connected 才返回 true。
+// onConnect 返回错误时不再重试，数据库保持不可用。
+func Reconnect(ctx context.Context, onConnect func() error) {
+	ticker := time.NewTicker(reconnectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := databaseConnect(dsn()); err != nil {
+			logs.LogError.Errorf("Reconnect-数据库连接失败 %s %s后重试", err, reconnectInterval)
+			continue
+		}
+		if err := onConnect(); err != nil {
+			logs.LogError.Errorf("Reconnect-数据库连接成功，初始化失败 %s", err)
+			return
+		}
+		connected.Store(true)
+		logs.LogInfo.Infof("Reconnect-数据库连接成功")
+		return
+	}
+}
+
+// Connected 数据库已经连接并且可以处理请求时返回 true
+func Connected() bool {
+	return connected.Load()
+}
+
+// Use 使用已经打开的数据库连接，例如测试中的 SQLite，传入 nil 时视为没有连接数据库
+func Use(db *gorm.DB) {
+	DB = db
+	connected.Store(db != nil)
+}
+
+// dsn 根据配置构建 MySQL 的 DSN
+func dsn() string {
 	// 从配置中获取数据库连接所需的信息
 	username := config.AppConfig.Database.User
 	password := config.AppConfig.Database.Pwd
@@ -31,7 +106,7 @@ func InitMySQL() {
 	charset := config.AppConfig.Database.Params.Character
 
 	// 构建 DSN 字符串
-	dsn := strings.Join([]string{
+	return strings.Join([]string{
 		username,
 		":",
 		password,
@@ -41,34 +116,20 @@ func InitMySQL() {
 		strconv.Itoa(port),
 		")/",
 		database,
-		"?charset" + charset + "&parseTime=True",
+		"?charset=" + charset + "&parseTime=True",
 	}, "")
-
-	// 进行数据库连接
-	err := databaseConnect(dsn)
-	if err != nil {
-		logs.LogError.Errorf(
-			"InitMySQL-数据库连接失败！ %s 3秒后尝试连接。正在尝试%d次 剩余%d次。",
-			err,
-			curRetries,
-			maxRetries-curRetries,
-		)
-		curRetries = maxRetries - curRetries
-		if maxRetries-curRetries > 0 {
-			time.Sleep(time.Second * 3)
-			InitMySQL()
-		}
-	}
 }
 
 // databaseConnect 用于实际连接数据库
 func databaseConnect(dsn string) error {
 	// 根据 Gin 的模式设置 ORM 日志级别
-	var ormLogger logger.Interface
+	ormLogger := logger.New(log.New(logs.ConsoleWriter(), "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      logger.Warn,
+		Colorful:      true,
+	})
 	if gin.Mode() == "debug" {
-		ormLogger = logger.Default.LogMode(logger.Info)
-	} else {
-		ormLogger = logger.Default
+		ormLogger = ormLogger.LogMode(logger.Info)
 	}
 
 	// 使用 GORM 进行数据库连接
@@ -99,8 +160,29 @@ func databaseConnect(dsn string) error {
 	// 将数据库连接赋值给全局变量 DB
 	DB = db
 
-	// 初始化数据库迁移
-	InitMigration()
-
 	return err
 }
+
+// Ping 检查数据库是否可以访问，没有连接数据库时返回 ErrNotConnected
+func Ping(ctx context.Context) error {
+	if !Connected() {
+		return ErrNotConnected
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close 关闭数据库连接池，没有连接数据库时不做任何操作
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
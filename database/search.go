@@ -0,0 +1,147 @@
+package database
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"message/app/model"
+	"strings"
+)
+
+// Searcher 消息全文检索的实现，不同的数据库使用不同的全文索引
+type Searcher interface {
+	// Migrate 创建全文索引
+	Migrate(db *gorm.DB) error
+	// Search 在查询中添加全文检索条件，并将相关度查询为 score 列
+	Search(query *gorm.DB, keyword string) *gorm.DB
+}
+
+// searchers 每种数据库对应的全文检索实现，键为 gorm 的数据库方言名称
+var searchers = map[string]Searcher{
+	"mysql":    mysqlSearcher{},
+	"sqlite":   sqliteSearcher{},
+	"postgres": postgresSearcher{},
+}
+
+// RegisterSearcher 注册数据库的全文检索实现，已存在时覆盖
+func RegisterSearcher(dialect string, searcher Searcher) {
+	searchers[dialect] = searcher
+}
+
+// MessageSearcher 返回当前数据库的全文检索实现，没有对应的实现时使用 LIKE 查询
+func MessageSearcher() Searcher {
+	if searcher, ok := searchers[DB.Dialector.Name()]; ok {
+		return searcher
+	}
+	return likeSearcher{}
+}
+
+// mysqlSearcher 使用 MySQL 的 FULLTEXT 索引，ngram 分词器支持中文
+type mysqlSearcher struct{}
+
+func (mysqlSearcher) Migrate(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&model.Message{}, "idx_message_fulltext") {
+		return nil
+	}
+	return db.Exec(
+		"CREATE FULLTEXT INDEX idx_message_fulltext ON message (title, content, big_content) WITH PARSER ngram",
+	).Error
+}
+
+func (mysqlSearcher) Search(query *gorm.DB, keyword string) *gorm.DB {
+	match := "MATCH (title, content, big_content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	return query.
+		Select("message.*, "+match+" AS score", keyword).
+		Where(match, keyword)
+}
+
+// sqliteSearcher 使用 SQLite 的 FTS5 虚拟表，通过触发器与消息表保持同步
+type sqliteSearcher struct{}
+
+func (sqliteSearcher) Migrate(db *gorm.DB) error {
+	if db.Migrator().HasTable("message_fts") {
+		return nil
+	}
+	statements := []string{
+		"CREATE VIRTUAL TABLE message_fts USING fts5(title, content, big_content, content='message', content_rowid='id')",
+		`CREATE TRIGGER message_fts_ai AFTER INSERT ON message BEGIN
+			INSERT INTO message_fts(rowid, title, content, big_content) VALUES (new.id, new.title, new.content, new.big_content);
+		END`,
+		`CREATE TRIGGER message_fts_ad AFTER DELETE ON message BEGIN
+			INSERT INTO message_fts(message_fts, rowid, title, content, big_content) VALUES ('delete', old.id, old.title, old.content, old.big_content);
+		END`,
+		`CREATE TRIGGER message_fts_au AFTER UPDATE ON message BEGIN
+			INSERT INTO message_fts(message_fts, rowid, title, content, big_content) VALUES ('delete', old.id, old.title, old.content, old.big_content);
+			INSERT INTO message_fts(rowid, title, content, big_content) VALUES (new.id, new.title, new.content, new.big_content);
+		END`,
+		// 为已存在的消息建立索引
+		"INSERT INTO message_fts(message_fts) VALUES ('rebuild')",
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (sqliteSearcher) Search(query *gorm.DB, keyword string) *gorm.DB {
+	// 每个词都用双引号包裹，避免关键字中的符号被解析为 FTS5 的查询语法
+	var terms []string
+	for _, term := range strings.Fields(keyword) {
+		terms = append(terms, fmt.Sprintf(`"%s"`, strings.ReplaceAll(term, `"`, `""`)))
+	}
+	match := strings.Join(terms, " OR ")
+
+	// 使用子查询避免与消息表的列名冲突，bm25 的值越小越相关
+	return query.
+		Select(
+			"message.*, (SELECT -bm25(message_fts) FROM message_fts WHERE message_fts MATCH ? AND message_fts.rowid = message.id) AS score",
+			match,
+		).
+		Where("message.id IN (SELECT rowid FROM message_fts WHERE message_fts MATCH ?)", match)
+}
+
+// postgresSearcher 使用 PostgreSQL 的 tsvector 生成列和 GIN 索引
+type postgresSearcher struct{}
+
+func (postgresSearcher) Migrate(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`ALTER TABLE message ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(content, '') || ' ' || coalesce(big_content, ''))) STORED`,
+		).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(
+			"CREATE INDEX IF NOT EXISTS idx_message_search_vector ON message USING GIN (search_vector)",
+		).Error
+	})
+}
+
+func (postgresSearcher) Search(query *gorm.DB, keyword string) *gorm.DB {
+	return query.
+		Select("message.*, ts_rank(search_vector, plainto_tsquery('simple', ?)) AS score", keyword).
+		Where("search_vector @@ plainto_tsquery('simple', ?)", keyword)
+}
+
+// likeSearcher 没有全文索引时使用 LIKE 查询，所有结果的相关度相同
+type likeSearcher struct{}
+
+func (likeSearcher) Migrate(db *gorm.DB) error {
+	return nil
+}
+
+func (likeSearcher) Search(query *gorm.DB, keyword string) *gorm.DB {
+	// 关键字中的 % 和 _ 按字面匹配
+	like := "%" + EscapeLike(keyword) + "%"
+	escape := " " + LikeEscape(query)
+	return query.
+		Select("message.*, 0 AS score").
+		Where(
+			DB.Where("title LIKE ?"+escape, like).
+				Or("content LIKE ?"+escape, like).
+				Or("big_content LIKE ?"+escape, like),
+		)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"message/app/model"
+	"testing"
+)
+
+func TestLikeSearcherMatchesWildcardsLiterally(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&model.Message{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, title := range []string{"100% done", "1000 done", "a_b", "axb", `c\d`} {
+		message := &model.Message{MessageId: string(rune('a' + i)), Title: title, Content: "content", BigContent: "big"}
+		if err := db.Create(message).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string][]string{
+		"0%":   {"100% done"},
+		"a_b":  {"a_b"},
+		`c\d`:  {`c\d`},
+		"done": {"100% done", "1000 done"},
+	}
+	for keyword, want := range tests {
+		var messages []model.Message
+		err := likeSearcher{}.Search(db.Model(&model.Message{}), keyword).Order("id").Find(&messages).Error
+		if err != nil {
+			t.Fatalf("Search(%q): %s", keyword, err)
+		}
+		var titles []string
+		for _, message := range messages {
+			titles = append(titles, message.Title)
+		}
+		if len(titles) != len(want) {
+			t.Fatalf("Search(%q) = %v, want %v", keyword, titles, want)
+		}
+		for i := range want {
+			if titles[i] != want[i] {
+				t.Fatalf("Search(%q) = %v, want %v", keyword, titles, want)
+			}
+		}
+	}
+}
@@ -24,14 +24,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/message": {
+        "/admin/audit": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "根据用户凭证查询消息",
+                "description": "根据条件分页查询审计日志",
                 "consumes": [
                     "application/json"
                 ],
@@ -39,26 +39,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
                 ],
-                "summary": "查询消息",
+                "summary": "查询审计日志",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
-                        "name": "filter",
+                        "description": "操作者的凭证",
+                        "name": "actor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status）",
-                        "name": "sortColumn",
+                        "description": "操作类型（create|update|status|delete|restore|hide|retract|flag|tag|action）",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "排序类型（asc/desc）",
-                        "name": "sortType",
+                        "description": "消息id",
+                        "name": "messageId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339）",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339）",
+                        "name": "to",
                         "in": "query"
                     },
                     {
@@ -70,13 +82,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "消息信息",
+                        "description": "审计日志",
                         "schema": {
                             "type": "array",
                             "items": {
                                 "type": "array",
                                 "items": {
-                                    "$ref": "#/definitions/response.Message"
+                                    "$ref": "#/definitions/response.Audit"
                                 }
                             }
                         }
@@ -100,47 +112,154 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/audit/export": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "创建消息",
+                "description": "根据条件导出审计日志，每行一条 JSON 数据",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
                 ],
-                "summary": "创建消息",
+                "summary": "导出审计日志",
                 "parameters": [
                     {
-                        "description": "创建的数据",
-                        "name": "_",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.MessageCreateUpdateRequest"
-                        }
+                        "type": "string",
+                        "description": "操作者的凭证",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作类型（create|update|status|delete|restore|hide|retract|flag|tag|action）",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "messageId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339）",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339）",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "每行一条审计日志",
                         "schema": {
-                            "$ref": "#/definitions/response.Message"
+                            "$ref": "#/definitions/response.Audit"
                         }
                     },
-                    "202": {
-                        "description": "创建失败",
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
                         "schema": {
                             "$ref": "#/definitions/response.HTTPError"
                         }
+                    }
+                }
+            }
+        },
+        "/admin/message": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "查询所有消息，不限制发送者和接收者",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "管理员查询消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status|priority）",
+                        "name": "sortColumn",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序类型（asc/desc）",
+                        "name": "sortType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "查询第几页数据",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "复杂消息的返回格式（html/text），为空时原样返回",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否查询已软删除的消息",
+                        "name": "deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "消息信息",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.AdminMessage"
+                                }
+                            }
+                        }
                     },
                     "400": {
                         "description": "请求参数错误",
@@ -168,7 +287,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "根据数组的数据删除消息",
+                "description": "根据数组的数据撤回消息，不限制发送者",
                 "consumes": [
                     "application/json"
                 ],
@@ -176,12 +295,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
                 ],
-                "summary": "删除消息",
+                "summary": "管理员撤回消息",
                 "parameters": [
                     {
-                        "description": "删除的消息",
+                        "type": "boolean",
+                        "description": "任意一条消息撤回失败则全部回滚",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "description": "撤回的消息",
                         "name": "_",
                         "in": "body",
                         "required": true,
@@ -195,7 +320,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新后返回的数据",
+                        "description": "每条消息的撤回结果",
                         "schema": {
                             "type": "array",
                             "items": {
@@ -215,61 +340,123 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "找不到兑换码",
+                    "502": {
+                        "description": "系统异常",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/message/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "导出所有没有删除的消息，格式为 JSON Lines 或 CSV，可以用于导入到其他环境",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "导出所有消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "导出格式（jsonl/csv），为空时为 jsonl",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每行一条消息",
+                        "schema": {
+                            "$ref": "#/definitions/response.ExportedMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
                         }
                     },
                     "502": {
                         "description": "系统异常",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.HTTPError"
                         }
                     }
                 }
             }
         },
-        "/message/status": {
-            "put": {
+        "/admin/message/import": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "根据数组的数据更新消息状态",
+                "description": "导入 JSON Lines 或 CSV 格式的消息，字段与导出的消息相同，保留消息id、时间、发送者、接收者和状态。消息id已经存在的消息跳过，读取并校验所有数据后在一个事务中导入，失败时不会导入任何消息，一次最多导入 100000 条消息。dryRun 为 true 时只校验数据",
                 "consumes": [
-                    "application/json"
+                    "application/x-ndjson",
+                    "text/csv"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
                 ],
-                "summary": "更新状态",
+                "summary": "导入消息",
                 "parameters": [
                     {
-                        "description": "消息状态",
+                        "type": "string",
+                        "description": "导入格式（jsonl/csv），为空时为 jsonl",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只校验数据，不写入数据库",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "导入的数据",
                         "name": "_",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/request.MessageStatusRequest"
-                            }
+                            "type": "string"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新后返回的数据",
+                        "description": "导入的结果",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/response.MessageStatusResponse"
-                            }
+                            "$ref": "#/definitions/response.MessageImportResponse"
                         }
                     },
                     "400": {
@@ -284,6 +471,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.HTTPError"
                         }
                     },
+                    "413": {
+                        "description": "导入的消息过多",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
                     "502": {
                         "description": "系统异常",
                         "schema": {
@@ -293,14 +486,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/message/{id}": {
-            "put": {
+        "/admin/message/restore": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "根据消息id更新消息",
+                "description": "根据数组的数据恢复已软删除的消息，不限制发送者",
                 "consumes": [
                     "application/json"
                 ],
@@ -308,38 +501,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
                 ],
-                "summary": "更新消息",
+                "summary": "管理员恢复消息",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "消息id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新消息",
+                        "description": "恢复的消息",
                         "name": "_",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.MessageCreateUpdateRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageRestoreRequest"
+                            }
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "恢复后返回的数据",
                         "schema": {
-                            "$ref": "#/definitions/response.Message"
-                        }
-                    },
-                    "202": {
-                        "description": "更新失败",
-                        "schema": {
-                            "$ref": "#/definitions/response.HTTPError"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageRestoreResponse"
+                            }
                         }
                     },
                     "400": {
@@ -354,12 +540,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "找不到数据",
-                        "schema": {
-                            "$ref": "#/definitions/response.HTTPError"
-                        }
-                    },
                     "502": {
                         "description": "系统异常",
                         "schema": {
@@ -368,20 +548,2763 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/admin/message/{id}/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据消息id查询消息每个接收者的状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "查询接收者状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "接收者状态",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.MessageRecipientStatusResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "进程可以处理请求时返回 200，不检查数据库等依赖",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "存活检查",
+                "responses": {
+                    "200": {
+                        "description": "服务正常",
+                        "schema": {
+                            "$ref": "#/definitions/response.Health"
+                        }
+                    }
+                }
+            }
+        },
+        "/message": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据用户凭证查询消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "查询消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status|priority）",
+                        "name": "sortColumn",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序类型（asc/desc）",
+                        "name": "sortType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "查询第几页数据",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "复杂消息的返回格式（html/text），为空时原样返回",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "置顶的消息排在最前面",
+                        "name": "pinnedFirst",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "消息信息",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.InboxMessage"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "创建消息。携带 Idempotency-Key 时，同一个键和相同的内容重试返回之前创建的消息，并设置响应头 Idempotent-Replayed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "创建消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，同一个发送者在有效期内只创建一条消息",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "创建的数据",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.MessageCreateUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Message"
+                        }
+                    },
+                    "202": {
+                        "description": "创建失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "幂等键已经用于内容不同的请求",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "410": {
+                        "description": "幂等键之前创建的消息已经被彻底删除",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据删除消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "删除消息",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "任意一条消息删除失败则全部回滚",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "description": "删除的消息",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageDeleteRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每条消息的删除结果",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageDeleteResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据批量创建消息，每条消息单独校验，返回每条消息的结果。atomic 为 true 时任意一条消息失败则全部不创建",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "批量创建消息",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "任意一条消息失败则全部不创建",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "description": "创建的数据，最多 1000 条",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageCreateUpdateRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每条消息的结果",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.MessageBatchResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "导出自己发送的消息和自己作为接收者没有删除的消息，格式为 JSON Lines 或 CSV",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "导出消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "导出格式（jsonl/csv），为空时为 jsonl",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每行一条消息",
+                        "schema": {
+                            "$ref": "#/definitions/response.ExportedMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/flags": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据批量设置接收者自己的置顶和标星，只修改请求中存在的标记",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "置顶和标星",
+                "parameters": [
+                    {
+                        "description": "置顶和标星",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageFlagRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "设置后返回的数据",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageFlagResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/inbox": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据删除接收者自己的消息，不影响其他接收者和发送者",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "接收者删除消息",
+                "parameters": [
+                    {
+                        "description": "删除的消息",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageHideRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每条消息的删除结果",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageDeleteResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据恢复发送者已软删除的消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "恢复消息",
+                "parameters": [
+                    {
+                        "description": "恢复的消息",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageRestoreRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复后返回的数据",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageRestoreResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/retract": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据撤回发送者的消息，撤回后所有接收者都看不到消息，发送者可以在回收站中恢复",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "发送者撤回消息",
+                "parameters": [
+                    {
+                        "description": "撤回的消息",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageRetractRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每条消息的撤回结果",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageDeleteResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据关键字全文搜索标题、内容和详细内容，按照相关度排序并返回高亮的摘要",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "搜索消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "搜索的关键字",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "查询第几页数据",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "复杂消息的返回格式（html/text），为空时原样返回",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "搜索到的消息",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.MessageSearchResult"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据更新消息状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "更新状态",
+                "parameters": [
+                    {
+                        "description": "消息状态",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageStatusRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新后返回的数据",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageStatusResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "查询发送者设置的标签和自己的标签，以及使用每个标签的消息数量",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "查询标签",
+                "responses": {
+                    "200": {
+                        "description": "标签和消息数量",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.TagCount"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据批量添加标签，发送者的标签只有发送者可以添加，自己的标签只有自己可以看到",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "添加标签",
+                "parameters": [
+                    {
+                        "description": "添加的标签",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageTagRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "添加后返回的数据",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageTagResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据数组的数据批量移除标签，发送者的标签只有发送者可以移除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "移除标签",
+                "parameters": [
+                    {
+                        "description": "移除的标签",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.MessageTagRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "移除后返回的数据",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.MessageTagResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/trash": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据用户凭证查询发送者已软删除的消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "查询已删除的消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "过滤语句（title = 标题,status = 0|1|2,...）",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序列（created_at|updated_at|sender_ids|title|content|category|big_content|introducer_ids|status|priority）",
+                        "name": "sortColumn",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序类型（asc/desc）",
+                        "name": "sortType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "查询第几页数据",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "复杂消息的返回格式（html/text），为空时原样返回",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已删除的消息",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.TrashMessage"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/webhook": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "查询发送者自己注册的 Webhook，不返回签名密钥",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "查询 Webhook",
+                "responses": {
+                    "200": {
+                        "description": "注册的 Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/response.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "没有注册 Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "注册或替换发送者自己的 Webhook，接收者点击自己发送的消息的操作按钮时将 message.action 事件 POST 到该地址",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "注册 Webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook 的地址和签名密钥",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册的 Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/response.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "删除发送者自己注册的 Webhook，返回删除前的 Webhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "删除 Webhook",
+                "responses": {
+                    "200": {
+                        "description": "删除的 Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/response.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "没有注册 Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据消息id查询发送者或接收者可以看到的消息，可以指定查询的版本",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "查询一条消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "消息版本，为空时返回当前版本",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "复杂消息的返回格式（html/text），为空时原样返回",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "消息的 ETag，消息没有修改时返回 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "消息信息",
+                        "schema": {
+                            "$ref": "#/definitions/response.Message"
+                        }
+                    },
+                    "304": {
+                        "description": "消息没有修改",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据消息id更新消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "更新消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "消息的 ETag，消息已经被修改时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "更新消息",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.MessageCreateUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Message"
+                        }
+                    },
+                    "202": {
+                        "description": "更新失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "消息已经被修改，返回当前的消息",
+                        "schema": {
+                            "$ref": "#/definitions/response.Message"
+                        }
+                    },
+                    "428": {
+                        "description": "没有携带 If-Match 请求头",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按照 JSON Merge Patch 的语义更新消息，只更新请求中存在的字段，可以单独添加或移除接收者",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "部分更新消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "消息的 ETag，消息已经被修改时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "更新的字段",
+                        "name": "_",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.MessagePatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Message"
+                        }
+                    },
+                    "202": {
+                        "description": "更新失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "消息已经被修改，返回当前的消息",
+                        "schema": {
+                            "$ref": "#/definitions/response.Message"
+                        }
+                    },
+                    "428": {
+                        "description": "没有携带 If-Match 请求头",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/{id}/actions/{actionId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "记录接收者点击的操作按钮，并通过事件和 Webhook 通知发送者。每个接收者只能选择一个操作按钮，重复点击同一个按钮时返回之前的记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "点击消息的操作按钮",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作按钮id",
+                        "name": "actionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "点击记录",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "已经选择了其他操作按钮",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/message/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "根据消息id查询消息的所有版本，按照版本号从小到大排序，最后一个是当前版本",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "查询消息的历史版本",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "消息的所有版本",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.MessageVersion"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/request.ValidationError"
+                        }
+                    },
+                    "401": {
+                        "description": "凭证错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "系统异常",
+                        "schema": {
+                            "$ref": "#/definitions/response.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "检查数据库连接和迁移的版本，并返回后台任务的状态和丢弃的事件数量\n数据库不可用或者有没有执行的迁移时返回 503，后台任务失败时状态为 degraded，仍然返回 200",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "就绪检查",
+                "responses": {
+                    "200": {
+                        "description": "可以处理请求",
+                        "schema": {
+                            "$ref": "#/definitions/response.Health"
+                        }
+                    },
+                    "503": {
+                        "description": "不能处理请求",
+                        "schema": {
+                            "$ref": "#/definitions/response.Health"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "model.AuditChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {}
+            }
+        },
+        "model.AuditChanges": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/model.AuditChange"
+            }
+        },
+        "model.MessageAction": {
+            "type": "object",
+            "properties": {
+                "callback": {
+                    "description": "Callback 点击按钮后通知发送者的回调 ID",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Id 按钮 ID，同一条消息中唯一",
+                    "type": "string"
+                },
+                "label": {
+                    "description": "Label 按钮的文字",
+                    "type": "string"
+                },
+                "style": {
+                    "description": "Style 按钮的样式",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Url 点击按钮后打开的链接",
+                    "type": "string"
+                }
+            }
+        },
+        "request.MessageActionRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "label"
+            ],
+            "properties": {
+                "callback": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "order.approve"
+                },
+                "id": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "approve"
+                },
+                "label": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "同意"
+                },
+                "style": {
+                    "type": "string",
+                    "enum": [
+                        "default",
+                        "primary",
+                        "danger"
+                    ],
+                    "example": "primary"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/orders/1"
+                }
+            }
+        },
         "request.MessageCreateUpdateRequest": {
             "type": "object",
-            "required": [
-                "bigContent",
-                "category",
-                "content",
-                "introducerIds",
-                "title"
-            ],
+            "required": [
+                "bigContent",
+                "category",
+                "content",
+                "introducerIds",
+                "title"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/request.MessageActionRequest"
+                    }
+                },
+                "bigContent": {
+                    "type": "string",
+                    "example": "复杂的内容"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "important"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "简单的内容"
+                },
+                "contentType": {
+                    "type": "string",
+                    "enum": [
+                        "text",
+                        "markdown",
+                        "html"
+                    ],
+                    "example": "markdown"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "introducerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "发给谁"
+                    ]
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "标题"
+                }
+            }
+        },
+        "request.MessageDeleteRequest": {
+            "type": "object",
+            "required": [
+                "messageId"
+            ],
+            "properties": {
+                "delete": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "messageId": {
+                    "type": "string",
+                    "example": "id"
+                }
+            }
+        },
+        "request.MessageFlagRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "pinned": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "starred": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "request.MessageHideRequest": {
+            "type": "object",
+            "required": [
+                "messageId"
+            ],
+            "properties": {
+                "messageId": {
+                    "type": "string",
+                    "example": "id"
+                }
+            }
+        },
+        "request.MessagePatchRequest": {
+            "type": "object",
+            "required": [
+                "addIntroducerIds",
+                "introducerIds",
+                "removeIntroducerIds"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/request.MessageActionRequest"
+                    }
+                },
+                "addIntroducerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "发给谁"
+                    ]
+                },
+                "bigContent": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "复杂的内容"
+                },
+                "category": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "important"
+                },
+                "content": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "简单的内容"
+                },
+                "contentType": {
+                    "type": "string",
+                    "enum": [
+                        "text",
+                        "markdown",
+                        "html"
+                    ],
+                    "example": "markdown"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "introducerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "发给谁"
+                    ]
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "removeIntroducerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "发给谁"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "标题"
+                }
+            }
+        },
+        "request.MessageRestoreRequest": {
+            "type": "object",
+            "required": [
+                "messageId"
+            ],
+            "properties": {
+                "messageId": {
+                    "type": "string",
+                    "example": "id"
+                }
+            }
+        },
+        "request.MessageRetractRequest": {
+            "type": "object",
+            "required": [
+                "messageId"
+            ],
+            "properties": {
+                "messageId": {
+                    "type": "string",
+                    "example": "id"
+                }
+            }
+        },
+        "request.MessageStatusRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "status"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "status": {
+                    "type": "integer",
+                    "maximum": 2,
+                    "minimum": 0,
+                    "example": 1
+                }
+            }
+        },
+        "request.MessageTagRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "tags"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "personal": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "invoice"
+                    ]
+                }
+            }
+        },
+        "request.ValidationError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "字段名",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "错误消息",
+                    "type": "string"
+                },
+                "param": {
+                    "description": "参数",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "字段类型",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "字段数值"
+                }
+            }
+        },
+        "request.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "secret"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/webhook"
+                }
+            }
+        },
+        "response.AdminMessage": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
+                },
+                "big_content": {
+                    "type": "string",
+                    "example": "复杂的内容"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "important"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "简单的内容"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "edited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "introducer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fc64c1a807c2e69655f68d31e5caa35d",
+                        "70c021d35ce60436c115b20b5cf583d0",
+                        "..."
+                    ]
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "sender_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2f14ec370621a8be08c8f0ece459e7e0",
+                        "22798c5dcd6e5b66c8660c447010d49d",
+                        "..."
+                    ]
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "title": {
+                    "type": "string",
+                    "example": "标题"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "response.Audit": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "2f14ec370621a8be08c8f0ece459e7e0"
+                },
+                "changes": {
+                    "$ref": "#/definitions/model.AuditChanges"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                }
+            }
+        },
+        "response.ExportedMessage": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
+                },
+                "big_content": {
+                    "type": "string",
+                    "example": "复杂的内容"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "important"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "简单的内容"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "edited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "introducer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fc64c1a807c2e69655f68d31e5caa35d",
+                        "70c021d35ce60436c115b20b5cf583d0",
+                        "..."
+                    ]
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "recipients": {
+                    "description": "Recipients 接收者的状态，没有记录状态的接收者为未读",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.MessageRecipientState"
+                    }
+                },
+                "sender_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2f14ec370621a8be08c8f0ece459e7e0",
+                        "22798c5dcd6e5b66c8660c447010d49d",
+                        "..."
+                    ]
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "title": {
+                    "type": "string",
+                    "example": "标题"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "response.HTTPError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "message": {
+                    "type": "string",
+                    "example": "status bad request"
+                }
+            }
+        },
+        "response.Health": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Checks 每一项检查的结果，键为检查的名称",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/response.HealthCheck"
+                    }
+                },
+                "status": {
+                    "description": "Status 服务的状态（ok/degraded/unavailable）",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "response.HealthCheck": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "Details 检查的详细信息，例如迁移的版本和后台任务的状态",
+                    "type": "object"
+                },
+                "error": {
+                    "description": "Error 检查失败的原因，只有固定的说明，具体的错误在日志中",
+                    "type": "string",
+                    "example": "数据库不可用"
+                },
+                "status": {
+                    "description": "Status 检查的状态（ok/degraded/unavailable）",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "response.InboxMessage": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
+                },
+                "big_content": {
+                    "type": "string",
+                    "example": "复杂的内容"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "important"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "简单的内容"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "edited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "introducer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fc64c1a807c2e69655f68d31e5caa35d",
+                        "70c021d35ce60436c115b20b5cf583d0",
+                        "..."
+                    ]
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                },
+                "pinned": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "sender_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2f14ec370621a8be08c8f0ece459e7e0",
+                        "22798c5dcd6e5b66c8660c447010d49d",
+                        "..."
+                    ]
+                },
+                "starred": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "invoice",
+                        "q3"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "标题"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "response.Message": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
+                },
+                "big_content": {
+                    "type": "string",
+                    "example": "复杂的内容"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "important"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "简单的内容"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "edited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "introducer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fc64c1a807c2e69655f68d31e5caa35d",
+                        "70c021d35ce60436c115b20b5cf583d0",
+                        "..."
+                    ]
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "sender_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2f14ec370621a8be08c8f0ece459e7e0",
+                        "22798c5dcd6e5b66c8660c447010d49d",
+                        "..."
+                    ]
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "title": {
+                    "type": "string",
+                    "example": "标题"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "response.MessageActionResponse": {
+            "type": "object",
+            "properties": {
+                "action_id": {
+                    "type": "string",
+                    "example": "approve"
+                },
+                "callback": {
+                    "type": "string",
+                    "example": "order.approve"
+                },
+                "chosen_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                }
+            }
+        },
+        "response.MessageBatchResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "Errors 校验失败时的错误信息",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "index": {
+                    "description": "Index 消息在请求数组中的位置，从 0 开始",
+                    "type": "integer",
+                    "example": 0
+                },
+                "message_id": {
+                    "description": "MessageId 创建成功时消息的唯一标识符",
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                },
+                "result": {
+                    "description": "Result 创建的结果（created/invalid/failed/rolledBack）",
+                    "type": "string",
+                    "example": "created"
+                }
+            }
+        },
+        "response.MessageDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "delete": {
+                    "description": "Delete 表示消息是否永久删除。",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "Id 表示消息的唯一标识符。",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result 表示消息删除操作的结果（deleted/notFound/forbidden/failed/rolledBack）。",
+                    "type": "string",
+                    "example": "deleted"
+                },
+                "status": {
+                    "description": "Status 表示消息删除操作的状态，用于指示操作是否成功",
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.MessageFlagResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
+                "result": {
+                    "type": "boolean"
+                },
+                "starred": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.MessageImportError": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "Errors 校验失败的错误信息",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "line": {
+                    "description": "Line 数据所在的行号，从 1 开始，CSV 包括表头",
+                    "type": "integer",
+                    "example": 2
+                },
+                "message_id": {
+                    "description": "MessageId 消息id，解析失败时为空",
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                }
+            }
+        },
+        "response.MessageImportResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "DryRun 是否只校验数据，没有写入数据库",
+                    "type": "boolean",
+                    "example": false
+                },
+                "errors": {
+                    "description": "Errors 校验失败的消息，最多返回 100 条",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.MessageImportError"
+                    }
+                },
+                "imported": {
+                    "description": "Imported 导入的消息数量，只校验时是可以导入的消息数量",
+                    "type": "integer",
+                    "example": 97
+                },
+                "invalid": {
+                    "description": "Invalid 校验失败的消息数量",
+                    "type": "integer",
+                    "example": 2
+                },
+                "skipped": {
+                    "description": "Skipped 消息id已经存在而跳过的消息数量",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "description": "Total 读取到的消息数量",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "response.MessageRecipientState": {
+            "type": "object",
+            "properties": {
+                "hidden": {
+                    "description": "Hidden 接收者是否删除了消息",
+                    "type": "boolean",
+                    "example": false
+                },
+                "pinned": {
+                    "description": "Pinned 接收者是否置顶了消息",
+                    "type": "boolean",
+                    "example": false
+                },
+                "recipient_id": {
+                    "description": "RecipientId 接收者的ID",
+                    "type": "string",
+                    "example": "fc64c1a807c2e69655f68d31e5caa35d"
+                },
+                "starred": {
+                    "description": "Starred 接收者是否标星了消息",
+                    "type": "boolean",
+                    "example": true
+                },
+                "status": {
+                    "description": "Status 接收者的消息状态",
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "description": "UpdatedAt 接收者最后一次更新状态的时间",
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                }
+            }
+        },
+        "response.MessageRecipientStatusResponse": {
+            "type": "object",
             "properties": {
-                "bigContent": {
+                "recipient_id": {
+                    "description": "RecipientId 表示接收者的ID。",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 表示接收者的消息状态。",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt 表示接收者最后一次更新状态的时间，未更新过时为空。",
+                    "type": "string"
+                }
+            }
+        },
+        "response.MessageRestoreResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Id 表示消息的唯一标识符。",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 表示消息恢复操作的状态，用于指示操作是否成功",
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.MessageSearchResult": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
+                },
+                "big_content": {
                     "type": "string",
                     "example": "复杂的内容"
                 },
@@ -393,96 +3316,215 @@ const docTemplate = `{
                     "type": "string",
                     "example": "简单的内容"
                 },
-                "introducerIds": {
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "edited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "introducer_ids": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "发给谁"
+                        "fc64c1a807c2e69655f68d31e5caa35d",
+                        "70c021d35ce60436c115b20b5cf583d0",
+                        "..."
+                    ]
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "score": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "sender_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2f14ec370621a8be08c8f0ece459e7e0",
+                        "22798c5dcd6e5b66c8660c447010d49d",
+                        "..."
                     ]
                 },
+                "snippets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "title": "\u003cmark\u003e标题\u003c/mark\u003e"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "title": {
                     "type": "string",
                     "example": "标题"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "request.MessageDeleteRequest": {
+        "response.MessageStatusResponse": {
             "type": "object",
-            "required": [
-                "messageId"
-            ],
             "properties": {
-                "delete": {
-                    "type": "boolean",
-                    "example": false
+                "id": {
+                    "description": "Id 表示消息的唯一标识符。",
+                    "type": "string"
                 },
-                "messageId": {
-                    "type": "string",
-                    "example": "id"
+                "result": {
+                    "description": "Result 表示消息状态更新操作的结果。\ntrue 表示更新成功，false 表示更新失败。",
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "Status 表示消息的当前状态。",
+                    "type": "integer"
                 }
             }
         },
-        "request.MessageStatusRequest": {
+        "response.MessageTagResponse": {
             "type": "object",
-            "required": [
-                "id",
-                "status"
-            ],
             "properties": {
                 "id": {
-                    "type": "string",
-                    "example": "1"
+                    "type": "string"
                 },
-                "status": {
-                    "type": "integer",
-                    "maximum": 2,
-                    "minimum": 0,
-                    "example": 1
+                "result": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "invoice",
+                        "q3"
+                    ]
                 }
             }
         },
-        "request.ValidationError": {
+        "response.MessageVersion": {
             "type": "object",
             "properties": {
-                "field": {
-                    "description": "字段名",
-                    "type": "string"
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
                 },
-                "message": {
-                    "description": "错误消息",
-                    "type": "string"
+                "big_content": {
+                    "type": "string",
+                    "example": "复杂的内容"
                 },
-                "param": {
-                    "description": "参数",
-                    "type": "string"
+                "category": {
+                    "type": "string",
+                    "example": "important"
                 },
-                "type": {
-                    "description": "字段类型",
-                    "type": "string"
+                "content": {
+                    "type": "string",
+                    "example": "简单的内容"
                 },
-                "value": {
-                    "description": "字段数值"
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "edited_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "introducer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fc64c1a807c2e69655f68d31e5caa35d",
+                        "70c021d35ce60436c115b20b5cf583d0",
+                        "..."
+                    ]
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "标题"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "response.HTTPError": {
+        "response.TagCount": {
             "type": "object",
             "properties": {
-                "code": {
+                "count": {
                     "type": "integer",
-                    "example": 400
+                    "example": 3
                 },
-                "message": {
+                "name": {
                     "type": "string",
-                    "example": "status bad request"
+                    "example": "invoice"
+                },
+                "personal": {
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
-        "response.Message": {
+        "response.TrashMessage": {
             "type": "object",
             "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageAction"
+                    }
+                },
                 "big_content": {
                     "type": "string",
                     "example": "复杂的内容"
@@ -495,10 +3537,25 @@ const docTemplate = `{
                     "type": "string",
                     "example": "简单的内容"
                 },
+                "content_type": {
+                    "type": "string",
+                    "example": "text"
+                },
                 "created_at": {
                     "type": "string",
                     "example": "2024-02-15T05:49:57Z"
                 },
+                "data": {
+                    "type": "object"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "edited": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "introducer_ids": {
                     "type": "array",
                     "items": {
@@ -514,6 +3571,16 @@ const docTemplate = `{
                     "type": "string",
                     "example": "7e55cb38290f49ee2b0e9cfd2adf13e4"
                 },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
                 "sender_ids": {
                     "type": "array",
                     "items": {
@@ -536,40 +3603,31 @@ const docTemplate = `{
                 "updated_at": {
                     "type": "string",
                     "example": "2024-02-15T05:49:57Z"
-                }
-            }
-        },
-        "response.MessageDeleteResponse": {
-            "type": "object",
-            "properties": {
-                "delete": {
-                    "description": "Delete 表示消息是否永久删除。",
-                    "type": "boolean"
-                },
-                "id": {
-                    "description": "Id 表示消息的唯一标识符。",
-                    "type": "string"
                 },
-                "status": {
-                    "description": "Status 表示消息删除操作的状态，用于指示操作是否成功",
-                    "type": "boolean"
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "response.MessageStatusResponse": {
+        "response.Webhook": {
             "type": "object",
             "properties": {
-                "id": {
-                    "description": "Id 表示消息的唯一标识符。",
-                    "type": "string"
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
                 },
-                "result": {
-                    "description": "Result 表示消息状态更新操作的结果。\ntrue 表示更新成功，false 表示更新失败。",
-                    "type": "boolean"
+                "has_secret": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "status": {
-                    "description": "Status 表示消息的当前状态。",
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-15T05:49:57Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhook"
                 }
             }
         }
@@ -4,9 +4,16 @@ import (
 	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"io"
 	"message/config"
+	"os"
 )
 
+// Console 日志同时输出到的控制台（stdout/stderr），需要在 InitLog 之前设置
+//
+// 命令行的子命令把结果输出到 stdout，所以将日志输出到 stderr。
+var Console = "stdout"
+
 var LogInfo, LogError, LogAccess *zap.SugaredLogger
 var logInfo, logError, logAccess *zap.Logger
 
@@ -28,7 +35,7 @@ func InitLog() {
 			EncodeDuration: zapcore.SecondsDurationEncoder,
 			EncodeCaller:   zapcore.ShortCallerEncoder,
 		},
-		OutputPaths: []string{"stdout"},
+		OutputPaths: []string{Console},
 		InitialFields: map[string]interface{}{
 			"version": "v1.0.0",
 		},
@@ -47,6 +54,14 @@ func InitLog() {
 	LogAccess = logAccess.Sugar()
 }
 
+// ConsoleWriter 返回日志输出到的控制台
+func ConsoleWriter() io.Writer {
+	if Console == "stderr" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 func createLog(cfg zap.Config, outputPath string) *zap.Logger {
 	cfg.OutputPaths = append(cfg.OutputPaths, outputPath)
 	logger, err := cfg.Build()
@@ -1,15 +1,8 @@
 package main
 
 import (
-	lang "github.com/gin-contrib/i18n"
-	"github.com/gin-gonic/gin"
-	"golang.org/x/text/language"
-	"gopkg.in/yaml.v3"
-	"message/config"
-	"message/database"
-	"message/logs"
-	"message/router"
-	"message/utils"
+	"message/app/command"
+	"os"
 )
 
 //	@title			消息系统 API
@@ -35,32 +28,5 @@ import (
 // @externalDocs.url			https://swagger.io/resources/open-api/
 
 func main() {
-	// 初始化Gin引擎
-	r := gin.Default()
-
-	// 初始化配置
-	config.InitConfig()
-
-	// 初始日志
-	logs.InitLog()
-	defer logs.Sync()
-	r.Use(utils.AccessLogger())
-
-	// 国际化中间件
-	r.Use(lang.Localize(lang.WithBundle(&lang.BundleCfg{
-		DefaultLanguage:  language.Chinese,
-		FormatBundleFile: "yaml",
-		AcceptLanguage:   []language.Tag{language.Chinese, language.English},
-		RootPath:         "resources/lang",
-		UnmarshalFunc:    yaml.Unmarshal,
-	})))
-
-	// 初始化路由
-	router.InitRouter(r)
-
-	// 连接MySQL数据库
-	database.InitMySQL()
-
-	// 启动Gin引擎
-	r.Run(":1204")
+	os.Exit(command.Run(os.Args[1:]))
 }
@@ -0,0 +1,59 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"message/app/controller"
+	"message/app/request"
+)
+
+// InitAdminRouter 用于初始化管理员相关的路由
+func InitAdminRouter(router *gin.RouterGroup) {
+	// 查询所有消息
+	router.GET(
+		"message",
+		request.ValidateAdminMessageRequestMiddleware(),
+		controller.AdminMessageIndex,
+	)
+	// 导出所有消息
+	router.GET(
+		"message/export",
+		request.ValidateMessageExportRequestMiddleware(),
+		controller.AdminMessageExport,
+	)
+	// 导入消息
+	router.POST(
+		"message/import",
+		request.ValidateMessageImportRequestMiddleware(),
+		controller.AdminMessageImport,
+	)
+	// 撤回消息
+	router.DELETE(
+		"message",
+		request.ValidateMessageDeleteRequestMiddleware(),
+		controller.AdminMessageDelete,
+	)
+	// 恢复已软删除的消息
+	router.POST(
+		"message/restore",
+		request.ValidateMessageRestoreRequestMiddleware(),
+		controller.AdminMessageRestore,
+	)
+	// 查询消息每个接收者的状态
+	router.GET(
+		"message/:id/status",
+		request.ValidateMessageIdRequestMiddleware(),
+		controller.AdminMessageRecipientStatus,
+	)
+	// 查询审计日志
+	router.GET(
+		"audit",
+		request.ValidateAuditRequestMiddleware(),
+		controller.AdminAuditIndex,
+	)
+	// 导出审计日志
+	router.GET(
+		"audit/export",
+		request.ValidateAuditRequestMiddleware(),
+		controller.AdminAuditExport,
+	)
+}
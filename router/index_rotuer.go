@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"message/app/controller"
 	"message/app/middleware"
 	"message/config"
 	_ "message/docs"
@@ -17,10 +18,23 @@ func InitRouter(router *gin.Engine) {
 		c.String(http.StatusOK, "OK")
 	})
 
-	// 创建一个名为 message 的路由组，并应用 AuthMiddleware 中间件
-	messageGroup := router.Group("message", middleware.AuthMiddleware())
+	// 健康检查，/healthz 只检查进程是否存活，/readyz 检查数据库等依赖是否可用
+	router.GET("/healthz", controller.Healthz)
+	router.GET("/readyz", controller.Readyz)
+
+	// 创建一个名为 message 的路由组，并应用 AuthMiddleware 中间件，没有连接数据库时返回 503
+	messageGroup := router.Group("message", middleware.DatabaseMiddleware(), middleware.AuthMiddleware())
 	InitMessageRouter(messageGroup)
 
+	// 创建一个名为 admin 的路由组，并应用 AdminAuthMiddleware 中间件
+	adminGroup := router.Group("admin", middleware.DatabaseMiddleware(), middleware.AdminAuthMiddleware())
+	InitAdminRouter(adminGroup)
+
+	// 创建一个名为 v1 的路由组，使用统一的响应格式，上面没有版本号的路由保留用于兼容旧的客户端
+	v1Group := router.Group("v1", middleware.EnvelopeMiddleware(), middleware.DatabaseMiddleware())
+	InitMessageRouter(v1Group.Group("message", middleware.AuthMiddleware()))
+	InitAdminRouter(v1Group.Group("admin", middleware.AdminAuthMiddleware()))
+
 	// 根据配置文件中的设置决定是否允许访问 SwaggerApi
 	if config.AppConfig.API.Test {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -14,26 +14,134 @@ func InitMessageRouter(router *gin.RouterGroup) {
 		request.ValidateMessageRequestMiddleware(),
 		controller.MessageIndex,
 	)
+	// 查询已删除的消息
+	router.GET(
+		"trash",
+		request.ValidateMessageRequestMiddleware(),
+		controller.MessageTrash,
+	)
+	// 全文搜索消息
+	router.GET(
+		"search",
+		request.ValidateMessageSearchRequestMiddleware(),
+		controller.MessageSearch,
+	)
+	// 导出消息
+	router.GET(
+		"export",
+		request.ValidateMessageExportRequestMiddleware(),
+		controller.MessageExport,
+	)
+	// 查询自己注册的 Webhook
+	router.GET(
+		"webhook",
+		controller.WebhookShow,
+	)
+	// 注册或替换自己的 Webhook
+	router.PUT(
+		"webhook",
+		request.ValidateWebhookRequestMiddleware(),
+		controller.WebhookUpdate,
+	)
+	// 删除自己的 Webhook
+	router.DELETE(
+		"webhook",
+		controller.WebhookDelete,
+	)
+	// 查询一条消息
+	router.GET(
+		":id",
+		request.ValidateMessageIdRequestMiddleware(),
+		request.ValidateMessageVersionRequestMiddleware(),
+		controller.MessageShow,
+	)
+	// 查询消息的历史版本
+	router.GET(
+		":id/history",
+		request.ValidateMessageIdRequestMiddleware(),
+		controller.MessageHistory,
+	)
+	// 点击消息的操作按钮
+	router.POST(
+		":id/actions/:actionId",
+		request.ValidateMessageIdRequestMiddleware(),
+		request.ValidateMessageActionIdRequestMiddleware(),
+		controller.MessageAction,
+	)
 	// 新增消息
 	router.POST("",
+		request.ValidateIdempotencyKeyRequestMiddleware(),
 		request.ValidateMessageCreateUpdateRequestMiddleware(),
 		controller.MessageCreate,
 	)
+	// 批量新增消息
+	router.POST(
+		"batch",
+		request.ValidateMessageBatchRequestMiddleware(),
+		controller.MessageBatchCreate,
+	)
 	// 更新消息
 	router.PUT(":id",
 		request.ValidateMessageIdRequestMiddleware(),
 		request.ValidateMessageCreateUpdateRequestMiddleware(),
 		controller.MessageUpdate,
 	)
+	// 部分更新消息
+	router.PATCH(":id",
+		request.ValidateMessageIdRequestMiddleware(),
+		request.ValidateMessagePatchRequestMiddleware(),
+		controller.MessagePatch,
+	)
 	// 更新消息状态
 	router.PUT(
 		"status",
 		request.ValidateMessageStatusRequestMiddleware(),
 		controller.MessageUpdateStatus,
 	)
+	// 置顶和标星消息
+	router.PUT(
+		"flags",
+		request.ValidateMessageFlagRequestMiddleware(),
+		controller.MessageFlag,
+	)
+	// 查询标签
+	router.GET(
+		"tags",
+		controller.MessageTagIndex,
+	)
+	// 添加标签
+	router.POST(
+		"tags",
+		request.ValidateMessageTagRequestMiddleware(),
+		controller.MessageTagAdd,
+	)
+	// 移除标签
+	router.DELETE(
+		"tags",
+		request.ValidateMessageTagRequestMiddleware(),
+		controller.MessageTagRemove,
+	)
 	// 删除通知
 	router.DELETE("",
 		request.ValidateMessageDeleteRequestMiddleware(),
 		controller.MessageDelete,
 	)
+	// 恢复已删除的消息
+	router.POST(
+		"restore",
+		request.ValidateMessageRestoreRequestMiddleware(),
+		controller.MessageRestore,
+	)
+	// 接收者删除自己的消息
+	router.DELETE(
+		"inbox",
+		request.ValidateMessageHideRequestMiddleware(),
+		controller.MessageHide,
+	)
+	// 发送者撤回消息
+	router.POST(
+		"retract",
+		request.ValidateMessageRetractRequestMiddleware(),
+		controller.MessageRetract,
+	)
 }
@@ -0,0 +1,114 @@
+// Package testutil 测试使用的数据库和路由，只在测试中引用，不会编译到服务中
+package testutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"message/app/middleware"
+	"message/app/repository"
+	"message/config"
+	"message/database"
+	"message/logs"
+	"message/router"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// initOnce 初始化配置和日志，整个测试进程只执行一次
+var initOnce sync.Once
+
+// baseConfig 配置文件中的配置，每个测试开始时恢复，避免测试之间互相影响
+var baseConfig config.ServiceConfig
+
+// Setup 使用临时的 SQLite 数据库作为 database.DB 并执行所有迁移，测试结束后关闭数据库
+//
+// 工作目录切换到项目根目录，以便读取 config 和 resources 目录，日志写入临时目录。
+func Setup(t testing.TB) *gorm.DB {
+	t.Helper()
+	initOnce.Do(func() {
+		_, file, _, _ := runtime.Caller(0)
+		if err := os.Chdir(filepath.Dir(filepath.Dir(file))); err != nil {
+			panic(err)
+		}
+		if err := config.LoadConfig(); err != nil {
+			panic(err)
+		}
+
+		logDir, err := os.MkdirTemp("", "message-test-logs")
+		if err != nil {
+			panic(err)
+		}
+		config.AppConfig.App.Log.Info = filepath.Join(logDir, "info.log")
+		config.AppConfig.App.Log.Error = filepath.Join(logDir, "error.log")
+		config.AppConfig.App.Log.Access = filepath.Join(logDir, "access.log")
+		logs.Console = "stderr"
+		logs.InitLog()
+		gin.SetMode(gin.TestMode)
+		baseConfig = config.AppConfig
+	})
+	config.AppConfig = baseConfig
+
+	dsn := filepath.Join(t.TempDir(), "message.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Discard,
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("open database: %s", err)
+	}
+	database.Use(db)
+	t.Cleanup(func() {
+		database.Use(nil)
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("migrate: %s", err)
+	}
+	// 验证表由使用消息服务的应用创建，测试中创建一个只有凭证列的表
+	verify := config.AppConfig.App.Verify
+	err = db.Exec(fmt.Sprintf(
+		"CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, %s VARCHAR(32) NOT NULL)",
+		verify.Table,
+		verify.Column,
+	)).Error
+	if err != nil {
+		t.Fatalf("create verify table: %s", err)
+	}
+	return db
+}
+
+// Token 在验证表中添加一个随机的消息凭证
+func Token(t testing.TB) string {
+	t.Helper()
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	token := hex.EncodeToString(data)
+	if err := repository.CreateMessageToken(token); err != nil {
+		t.Fatalf("create token: %s", err)
+	}
+	return token
+}
+
+// NewRouter 创建和 serve 命令相同的路由，不包括访问日志
+func NewRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.I18nMiddleware())
+	router.InitRouter(r)
+	return r
+}
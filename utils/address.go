@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// reservedPrefixes 不能作为公网地址的保留网段，补充 netip.Addr 判断方法没有覆盖的部分
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),   // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF 协议分配
+	netip.MustParsePrefix("192.0.2.0/24"),    // 文档示例
+	netip.MustParsePrefix("198.18.0.0/15"),   // 基准测试
+	netip.MustParsePrefix("198.51.100.0/24"), // 文档示例
+	netip.MustParsePrefix("203.0.113.0/24"),  // 文档示例
+	netip.MustParsePrefix("240.0.0.0/4"),     // 保留
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // 本地 NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // 文档示例
+}
+
+// IsPublicAddr 判断地址是否是公网地址，回环、私有、链路本地、组播和其他保留地址返回 false
+//
+// 用于限制服务端主动请求的地址，IPv4 映射的 IPv6 地址按照 IPv4 判断。
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	return !slices.ContainsFunc(reservedPrefixes, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"html"
+	"strings"
+)
+
+// 定义消息内容格式的常量
+const (
+	ContentTypeText     = "text"     // 纯文本
+	ContentTypeMarkdown = "markdown" // Markdown
+	ContentTypeHTML     = "html"     // HTML
+)
+
+// htmlPolicy 允许用户生成内容中常用的安全标签，去掉脚本、样式和事件属性
+var htmlPolicy = bluemonday.UGCPolicy()
+
+// textPolicy 去掉所有标签，只保留文本
+var textPolicy = bluemonday.StrictPolicy()
+
+// SanitizeHTML 根据允许的标签过滤 HTML，用于保存 HTML 内容前
+func SanitizeHTML(content string) string {
+	return htmlPolicy.Sanitize(content)
+}
+
+// RenderContent 将内容从 contentType 格式渲染为 format 格式
+//
+// format 为 html 时 Markdown 渲染为过滤后的 HTML，纯文本进行转义；format 为 text 时去掉所有标签。
+// 其他格式原样返回内容。
+func RenderContent(content string, contentType string, format string) string {
+	if format != ContentTypeHTML && format != ContentTypeText {
+		return content
+	}
+	if format == contentType {
+		return content
+	}
+
+	// 先统一渲染为 HTML
+	rendered := content
+	switch contentType {
+	case ContentTypeMarkdown:
+		var buffer bytes.Buffer
+		if err := goldmark.Convert([]byte(content), &buffer); err == nil {
+			rendered = htmlPolicy.Sanitize(buffer.String())
+		} else {
+			// 渲染失败时按照 HTML 过滤原始内容，避免返回未过滤的标签
+			rendered = htmlPolicy.Sanitize(content)
+		}
+	case ContentTypeHTML:
+	default:
+		if format == ContentTypeHTML {
+			return strings.ReplaceAll(html.EscapeString(content), "\n", "<br>")
+		}
+		return content
+	}
+
+	if format == ContentTypeHTML {
+		return rendered
+	}
+	return strings.TrimSpace(html.UnescapeString(textPolicy.Sanitize(rendered)))
+}
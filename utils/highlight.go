@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Highlight 截取文本中第一个关键字附近的片段，并用 <mark> 标记片段中的关键字，找不到关键字时返回空字符串
+//
+// 片段中的其他内容会进行 HTML 转义，radius 为关键字前后保留的字符数。
+func Highlight(text string, keywords []string, radius int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 优先匹配较长的关键字
+	var terms [][]rune
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			terms = append(terms, []rune(strings.ToLower(keyword)))
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return len(terms[i]) > len(terms[j])
+	})
+
+	// 从左到右查找所有不重叠的关键字位置
+	var matches [][2]int
+	for i := 0; i < len(lower); {
+		matched := 0
+		for _, term := range terms {
+			if hasRunePrefix(lower[i:], term) {
+				matched = len(term)
+				break
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		matches = append(matches, [2]int{i, i + matched})
+		i += matched
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	start := max(matches[0][0]-radius, 0)
+	end := min(matches[0][1]+radius, len(runes))
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	position := start
+	for _, match := range matches {
+		if match[1] > end {
+			break
+		}
+		builder.WriteString(html.EscapeString(string(runes[position:match[0]])))
+		builder.WriteString("<mark>")
+		builder.WriteString(html.EscapeString(string(runes[match[0]:match[1]])))
+		builder.WriteString("</mark>")
+		position = match[1]
+	}
+	builder.WriteString(html.EscapeString(string(runes[position:end])))
+	if end < len(runes) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
+
+// hasRunePrefix 判断 runes 是否以 prefix 开头
+func hasRunePrefix(runes []rune, prefix []rune) bool {
+	if len(runes) < len(prefix) {
+		return false
+	}
+	for i, r := range prefix {
+		if runes[i] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package utils
+
+// MergePatch 按照 JSON Merge Patch（RFC 7396）的语义将 patch 合并到 target，返回合并后的新对象
+//
+// patch 中值为 nil 的键从结果中删除，值为对象时递归合并，其他值直接替换，空的 patch 不修改 target。
+// target 不会被修改。
+func MergePatch(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target)+len(patch))
+	for key, value := range target {
+		result[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		patchObject, ok := value.(map[string]interface{})
+		if !ok {
+			result[key] = value
+			continue
+		}
+		// 原来的值不是对象时按照空对象合并，同时去掉 patch 中值为 nil 的键
+		targetObject, _ := result[key].(map[string]interface{})
+		result[key] = MergePatch(targetObject, patchObject)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	target := map[string]interface{}{
+		"a": "b",
+		"c": map[string]interface{}{"d": "e", "f": "g"},
+		"h": "i",
+	}
+
+	tests := []struct {
+		name  string
+		patch map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{
+			name:  "empty patch",
+			patch: map[string]interface{}{},
+			want:  target,
+		},
+		{
+			name:  "replace and delete",
+			patch: map[string]interface{}{"a": "z", "h": nil},
+			want: map[string]interface{}{
+				"a": "z",
+				"c": map[string]interface{}{"d": "e", "f": "g"},
+			},
+		},
+		{
+			name:  "nested merge",
+			patch: map[string]interface{}{"c": map[string]interface{}{"f": nil, "x": 1.0}},
+			want: map[string]interface{}{
+				"a": "b",
+				"c": map[string]interface{}{"d": "e", "x": 1.0},
+				"h": "i",
+			},
+		},
+		{
+			name:  "object replaces scalar without nulls",
+			patch: map[string]interface{}{"a": map[string]interface{}{"b": "c", "d": nil}},
+			want: map[string]interface{}{
+				"a": map[string]interface{}{"b": "c"},
+				"c": map[string]interface{}{"d": "e", "f": "g"},
+				"h": "i",
+			},
+		},
+		{
+			name:  "array replaced",
+			patch: map[string]interface{}{"h": []interface{}{"x", nil}},
+			want: map[string]interface{}{
+				"a": "b",
+				"c": map[string]interface{}{"d": "e", "f": "g"},
+				"h": []interface{}{"x", nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergePatch(target, tt.patch)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergePatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := target["c"].(map[string]interface{})["f"]; !ok {
+		t.Error("MergePatch() modified target")
+	}
+}